@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/casassg/wedding/backend/internal/sheets"
+)
+
+// CodesCmd manages invite codes
+type CodesCmd struct {
+	Generate CodesGenerateCmd `cmd:"" help:"Assign invite codes to guest list rows that have a name but no code"`
+}
+
+// CodeFlags configures generated invite codes
+type CodeFlags struct {
+	CodeAlphabet string `env:"INVITE_CODE_ALPHABET" default:"${code_alphabet}" help:"Characters used in generated invite codes"`
+	CodeLength   int    `env:"INVITE_CODE_LENGTH" default:"${code_length}" help:"Length of generated invite codes"`
+}
+
+// generator returns the invite code generator for the flags
+func (f *CodeFlags) generator() (*sheets.CodeGenerator, error) {
+	gen, err := sheets.NewCodeGenerator(f.CodeAlphabet, f.CodeLength)
+	if err != nil {
+		return nil, fmt.Errorf("invalid INVITE_CODE_ALPHABET/INVITE_CODE_LENGTH: %w", err)
+	}
+	return gen, nil
+}
+
+// CodesGenerateCmd assigns codes to rows without one and writes them back in one batch
+type CodesGenerateCmd struct {
+	MigrationFlags
+	Source SourceFlags `embed:""`
+	Codes  CodeFlags   `embed:""`
+	DryRun bool        `help:"Print the codes that would be assigned without writing them"`
+}
+
+func (cmd *CodesGenerateCmd) Run() error {
+	ctx := context.Background()
+
+	gen, err := cmd.Codes.generator()
+	if err != nil {
+		return err
+	}
+
+	// A dry run doesn't migrate either
+	open := cmd.openMigrated
+	if cmd.DryRun {
+		open = cmd.openCurrent
+	}
+	database, err := open(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	source, err := cmd.Source.open(ctx)
+	if err != nil {
+		return err
+	}
+	if !source.IsConfigured() {
+		return fmt.Errorf("guest list source not configured")
+	}
+
+	syncer, err := cmd.Source.syncer(database, source)
+	if err != nil {
+		return err
+	}
+	assigned, err := syncer.AssignInviteCodes(ctx, gen, cmd.DryRun)
+	if err != nil {
+		return err
+	}
+	if len(assigned) == 0 {
+		fmt.Println("Every row with a name already has an invite code")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROW\tNAME\tCODE")
+	for _, row := range assigned {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", row.Row, row.Name, row.InviteCode)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if cmd.DryRun {
+		fmt.Printf("\nDry run, %d code(s) not written. These are placeholders: running without --dry-run generates different codes.\n", len(assigned))
+		return nil
+	}
+
+	// Import the new invites right away so their links work before the next sync
+	log.Printf("Importing new invites...")
+	if err := syncer.SyncFromSheet(ctx); err != nil {
+		return fmt.Errorf("codes written but import failed: %w", err)
+	}
+	fmt.Printf("\nWrote %d invite code(s)\n", len(assigned))
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// HistoryCmd prints an invite's RSVP timeline
+type HistoryCmd struct {
+	MigrationFlags
+	InviteCode string `arg:"" help:"Invite code to show the timeline for"`
+}
+
+func (cmd *HistoryCmd) Run() error {
+	ctx := context.Background()
+
+	database, err := cmd.openMigrated(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	invite, err := database.GetInviteByInviteCode(ctx, cmd.InviteCode)
+	if err != nil {
+		return fmt.Errorf("invite %s not found: %w", cmd.InviteCode, err)
+	}
+
+	events, err := database.ListRSVPEventsByInviteCode(ctx, cmd.InviteCode)
+	if err != nil {
+		return fmt.Errorf("failed to fetch history: %w", err)
+	}
+
+	fmt.Printf("%s (%s): %d change(s)\n\n", invite.Name, invite.InviteCode, len(events))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "WHEN (UTC)\tEVENT\tSOURCE\tIP\tADULTS\tKIDS\tDIETARY\tSONG")
+	for _, event := range events {
+		answered := event.EventKey
+		if answered == "" {
+			answered = "wedding"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d -> %d\t%d -> %d\t%q -> %q\t%q -> %q\n",
+			event.CreatedAt.UTC().Format("2006-01-02 15:04:05"),
+			answered,
+			event.Source,
+			event.ClientIp,
+			event.OldConfirmedAdults, event.NewConfirmedAdults,
+			event.OldConfirmedKids, event.NewConfirmedKids,
+			event.OldDietaryInfo, event.NewDietaryInfo,
+			event.OldSongRequest, event.NewSongRequest,
+		)
+	}
+	return w.Flush()
+}
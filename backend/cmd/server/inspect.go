@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/casassg/wedding/backend/internal/sheets"
 	"google.golang.org/api/option"
 	googsheets "google.golang.org/api/sheets/v4"
 )
@@ -103,31 +104,50 @@ func (cmd *InspectCmd) Run() error {
 	fmt.Printf("Total rows in sheet: %d\n", len(countResp.Values))
 
 	// Read all data to analyze
-	dataRange := fmt.Sprintf("'%s'!A2:M", sheetName)
+	dataRange := fmt.Sprintf("'%s'", sheetName)
 	dataResp, err := service.Spreadsheets.Values.Get(sheetID, dataRange).Do()
 	if err != nil {
 		return fmt.Errorf("failed to read data: %w", err)
 	}
+	if len(dataResp.Values) == 0 {
+		return fmt.Errorf("sheet '%s' is empty", sheetName)
+	}
+
+	// Resolve columns from the header row the same way the syncer does
+	aliases, err := sheets.ParseColumnAliases(os.Getenv("GOOGLE_SHEET_COLUMN_ALIASES"))
+	if err != nil {
+		return fmt.Errorf("invalid GOOGLE_SHEET_COLUMN_ALIASES: %w", err)
+	}
+	cols, err := sheets.ResolveGuestColumns(dataResp.Values[0], aliases)
+	if err != nil {
+		return fmt.Errorf("failed to resolve columns: %w", err)
+	}
+
+	fmt.Printf("\n=== Column Mapping ===\n")
+	for _, key := range sheets.GuestColumnKeys() {
+		fmt.Printf("  %-16s -> column %s (%v)\n", key, cols.Letter(key), cols.Cell(dataResp.Values[0], key))
+	}
+
+	rows := dataResp.Values[1:]
 
 	fmt.Printf("\n=== Data Analysis ===\n")
-	fmt.Printf("Total data rows (excluding header): %d\n", len(dataResp.Values))
+	fmt.Printf("Total data rows (excluding header): %d\n", len(rows))
 
 	// Count rows with invite codes
 	inviteCodeCount := 0
-	for _, row := range dataResp.Values {
-		// Column H (index 7) should have invite code
-		if len(row) > 7 && row[7] != nil && row[7] != "" {
+	for _, row := range rows {
+		if code := cols.Cell(row, sheets.ColInviteCode); code != nil && code != "" {
 			inviteCodeCount++
 		}
 	}
-	fmt.Printf("Rows with invite codes (column H): %d\n", inviteCodeCount)
+	fmt.Printf("Rows with invite codes (column %s): %d\n", cols.Letter(sheets.ColInviteCode), inviteCodeCount)
 
 	// Show a few sample invite codes
 	fmt.Printf("\nSample invite codes:\n")
 	count := 0
-	for i, row := range dataResp.Values {
-		if len(row) > 7 && row[7] != nil && row[7] != "" {
-			fmt.Printf("  Row %d: %v (Name: %v)\n", i+2, row[7], row[0])
+	for i, row := range rows {
+		if code := cols.Cell(row, sheets.ColInviteCode); code != nil && code != "" {
+			fmt.Printf("  Row %d: %v (Name: %v)\n", i+2, code, cols.Cell(row, sheets.ColName))
 			count++
 			if count >= 5 {
 				break
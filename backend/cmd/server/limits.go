@@ -0,0 +1,48 @@
+package main
+
+import (
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/api"
+)
+
+// LimitFlags configures the per-IP rate limits and the lockouts after failed
+// invite code lookups
+type LimitFlags struct {
+	RateLimitInvite      int           `env:"RATE_LIMIT_INVITE" default:"10" help:"Invite lookups and RSVPs per minute per IP"`
+	RateLimitSchedule    int           `env:"RATE_LIMIT_SCHEDULE" default:"120" help:"Schedule and calendar feed requests per minute per IP"`
+	RateLimitDefault     int           `env:"RATE_LIMIT_DEFAULT" default:"60" help:"Requests per minute per IP on other routes"`
+	RateLimitMaxIPs      int           `env:"RATE_LIMIT_MAX_IPS" default:"10000" help:"IPs tracked by each rate limit, the least recently seen are forgotten first"`
+	TrustedProxies       string        `env:"TRUSTED_PROXIES" default:"" help:"Comma separated CIDRs whose X-Forwarded-For is believed, and 'fly' for Fly.io's Fly-Client-IP. Empty uses the connection's address"`
+	LookupWindow         time.Duration `env:"LOOKUP_WINDOW" default:"10m" help:"Window failed invite lookups are counted in"`
+	LookupIPFailures     int           `env:"LOOKUP_IP_FAILURES" default:"5" help:"Failed invite lookups from one IP within the window before it's locked out"`
+	LookupPrefixFailures int           `env:"LOOKUP_PREFIX_FAILURES" default:"20" help:"Failed lookups of codes sharing their first two characters, from any IP, before the prefix is locked out for IPs that failed lookups too"`
+	LookupLockout        time.Duration `env:"LOOKUP_LOCKOUT" default:"1m" help:"First lockout after too many failed lookups, doubled for each one after it"`
+	LookupMaxLockout     time.Duration `env:"LOOKUP_MAX_LOCKOUT" default:"1h" help:"Longest lockout after failed lookups"`
+}
+
+// rateLimits returns the per-IP rate limits by route
+func (f *LimitFlags) rateLimits() api.RateLimits {
+	return api.RateLimits{
+		Invite:   f.RateLimitInvite,
+		Schedule: f.RateLimitSchedule,
+		Default:  f.RateLimitDefault,
+		MaxIPs:   f.RateLimitMaxIPs,
+	}
+}
+
+// lookupGuard returns the failed lookup lockout settings
+func (f *LimitFlags) lookupGuard() api.LookupGuardConfig {
+	return api.LookupGuardConfig{
+		Window:         f.LookupWindow,
+		IPFailures:     f.LookupIPFailures,
+		PrefixFailures: f.LookupPrefixFailures,
+		Lockout:        f.LookupLockout,
+		MaxLockout:     f.LookupMaxLockout,
+	}
+}
+
+// trustedProxies returns whose forwarded headers tell the client IP
+func (f *LimitFlags) trustedProxies() (*api.TrustedProxies, error) {
+	return api.ParseTrustedProxies(f.TrustedProxies)
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"log"
+	"strconv"
 
 	"github.com/alecthomas/kong"
+	"github.com/casassg/wedding/backend/internal/sheets"
 	"github.com/joho/godotenv"
 )
 
@@ -11,7 +13,12 @@ import (
 type CLI struct {
 	Serve   ServeCmd   `cmd:"" help:"Start the RSVP API server" default:"1"`
 	Inspect InspectCmd `cmd:"" help:"Inspect Google Sheets structure and data"`
-	Sync    SyncCmd    `cmd:"" help:"Force an immediate sync between database and Google Sheets"`
+	Sync    SyncCmd    `cmd:"" help:"Sync the database with Google Sheets or show the sync history"`
+	Migrate MigrateCmd `cmd:"" help:"Show, apply or roll back database schema migrations"`
+	History HistoryCmd `cmd:"" help:"Show the RSVP change history of an invite"`
+	Stats   StatsCmd   `cmd:"" help:"Show RSVP totals, dietary breakdown and responses per day"`
+	Codes   CodesCmd   `cmd:"" help:"Generate invite codes for guest list rows"`
+	QR      QRCmd      `cmd:"" name:"qr" help:"Render invitation link QR codes and a manifest for printing"`
 }
 
 func main() {
@@ -25,6 +32,10 @@ func main() {
 		kong.Name("server"),
 		kong.Description("Wedding RSVP API server and management tools"),
 		kong.UsageOnError(),
+		kong.Vars{
+			"code_alphabet": sheets.DefaultCodeAlphabet,
+			"code_length":   strconv.Itoa(sheets.DefaultCodeLength),
+		},
 	)
 
 	err := ctx.Run()
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/api"
+	"github.com/casassg/wedding/backend/internal/store"
+	"github.com/casassg/wedding/backend/migrations"
+)
+
+// MigrateCmd manages database schema migrations
+type MigrateCmd struct {
+	Status MigrateStatusCmd `cmd:"" help:"List migrations and whether they have been applied" default:"1"`
+	Up     MigrateUpCmd     `cmd:"" help:"Apply all pending migrations"`
+	Down   MigrateDownCmd   `cmd:"" help:"Roll back the most recent migrations"`
+}
+
+// MigrationFlags are shared by every command that opens the database
+type MigrationFlags struct {
+	DBPath        string `env:"DB_PATH" default:"wedding.db" help:"Path to SQLite database file"`
+	MigrationsDir string `env:"MIGRATIONS_DIR" help:"Path to migrations directory (defaults to the migrations embedded in the binary)"`
+}
+
+// MigrateStatusCmd prints the migration status
+type MigrateStatusCmd struct {
+	MigrationFlags
+}
+
+func (cmd *MigrateStatusCmd) Run() error {
+	ctx := context.Background()
+
+	database, fsys, err := cmd.open()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	statuses, err := database.MigrationStatus(ctx, fsys)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-8s %-32s %s\n", "VERSION", "NAME", "APPLIED AT")
+	for _, status := range statuses {
+		applied := "pending"
+		if status.AppliedAt != nil {
+			applied = status.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+		}
+		if status.Missing {
+			applied += " (file missing)"
+		}
+		fmt.Printf("%04d     %-32s %s\n", status.Version, status.Name, applied)
+	}
+
+	return nil
+}
+
+// MigrateUpCmd applies pending migrations
+type MigrateUpCmd struct {
+	MigrationFlags
+}
+
+func (cmd *MigrateUpCmd) Run() error {
+	ctx := context.Background()
+
+	database, fsys, err := cmd.open()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	count, err := database.Migrate(ctx, fsys)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Applied %d migration(s)", count)
+	return nil
+}
+
+// MigrateDownCmd rolls back applied migrations
+type MigrateDownCmd struct {
+	MigrationFlags
+	Steps int `default:"1" help:"Number of migrations to roll back"`
+}
+
+func (cmd *MigrateDownCmd) Run() error {
+	ctx := context.Background()
+
+	database, fsys, err := cmd.open()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	count, err := database.MigrateDown(ctx, fsys, cmd.Steps)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Rolled back %d migration(s)", count)
+	return nil
+}
+
+// open opens the database and the migrations source without migrating
+func (f *MigrationFlags) open() (*store.Store, fs.FS, error) {
+	fsys, err := migrationsFS(f.MigrationsDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	database, err := store.Open(f.DBPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	return database, fsys, nil
+}
+
+// openMigrated opens the database and applies any pending migrations
+func (f *MigrationFlags) openMigrated(ctx context.Context) (*store.Store, error) {
+	database, fsys, err := f.open()
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := database.Migrate(ctx, fsys)
+	if err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+	if count > 0 {
+		log.Printf("Applied %d migration(s)", count)
+	}
+
+	return database, nil
+}
+
+// openCurrent opens the database without changing it, failing if it has
+// pending migrations
+func (f *MigrationFlags) openCurrent(ctx context.Context) (*store.Store, error) {
+	// Opening a missing database would create it
+	if _, err := os.Stat(f.DBPath); err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	database, fsys, err := f.open()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := database.MigrationStatus(ctx, fsys)
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			database.Close()
+			return nil, fmt.Errorf("database has pending migrations, run `server migrate up` first")
+		}
+	}
+
+	return database, nil
+}
+
+// replicaWaitInterval is how often a replica checks whether the primary has
+// created or migrated the replicated database
+const replicaWaitInterval = 5 * time.Second
+
+// openForRegion opens the database on the primary, applying pending
+// migrations, or the read-only copy of a replica, which the primary
+// migrates. Replicas wait until their copy exists and is up to date, since
+// on a deploy they may start before the primary is done, and migrate it
+// themselves if promoted meanwhile.
+func (f *MigrationFlags) openForRegion(ctx context.Context, region api.Region) (*store.Store, error) {
+	for {
+		if region.IsPrimary() {
+			return f.openMigrated(ctx)
+		}
+
+		database, err := f.openCurrent(ctx)
+		if err == nil {
+			return database, nil
+		}
+		log.Printf("Waiting for the primary to set up the database: %v", err)
+
+		select {
+		case <-time.After(replicaWaitInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// migrationsFS returns the migrations directory, accepting plain paths or
+// file:// URLs. An empty dir uses the migrations embedded in the binary.
+func migrationsFS(dir string) (fs.FS, error) {
+	if dir == "" {
+		return migrations.FS, nil
+	}
+
+	dir = strings.TrimPrefix(dir, "file://")
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MIGRATIONS_DIR: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("invalid MIGRATIONS_DIR: %s is not a directory", dir)
+	}
+
+	return os.DirFS(dir), nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/notify"
+	"github.com/casassg/wedding/backend/internal/store"
+)
+
+// NotifyFlags configures the RSVP confirmation and digest emails
+type NotifyFlags struct {
+	SMTPHost         string        `name:"smtp-host" env:"SMTP_HOST" help:"SMTP server for notification emails"`
+	SMTPPort         int           `name:"smtp-port" env:"SMTP_PORT" default:"587" help:"SMTP port (465 for implicit TLS)"`
+	SMTPUser         string        `name:"smtp-user" env:"SMTP_USER" help:"SMTP username"`
+	SMTPPassword     string        `name:"smtp-password" env:"SMTP_PASSWORD" help:"SMTP password"`
+	MailFrom         string        `env:"MAIL_FROM" default:"Laura & Gerard <rsvp@lauraygerard.wedding>" help:"From address of notification emails"`
+	MailOutboxDir    string        `env:"MAIL_OUTBOX_DIR" help:"Write emails to this maildir instead of sending them (local testing)"`
+	NotifyAdminEmail string        `env:"NOTIFY_ADMIN_EMAIL" help:"Send a digest of RSVP changes to this address"`
+	DigestInterval   time.Duration `env:"NOTIFY_DIGEST_INTERVAL" default:"24h" help:"Minimum time between RSVP digests"`
+	DispatchInterval time.Duration `env:"NOTIFY_DISPATCH_INTERVAL" default:"30s" help:"How often queued emails are sent"`
+}
+
+// open creates the notifier. The file outbox takes precedence over SMTP;
+// with neither configured notifications are disabled.
+func (f *NotifyFlags) open(database *store.Store) (*notify.Notifier, error) {
+	var mailer notify.Mailer
+	switch {
+	case f.MailOutboxDir != "":
+		fileMailer, err := notify.NewFileMailer(f.MailOutboxDir, f.MailFrom)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Writing notification emails to %s", f.MailOutboxDir)
+		mailer = fileMailer
+	case f.SMTPHost != "":
+		log.Printf("Sending notification emails through %s:%d", f.SMTPHost, f.SMTPPort)
+		mailer = notify.NewSMTPMailer(f.SMTPHost, f.SMTPPort, f.SMTPUser, f.SMTPPassword, f.MailFrom)
+	}
+
+	if f.DigestInterval <= 0 {
+		return nil, fmt.Errorf("NOTIFY_DIGEST_INTERVAL must be positive")
+	}
+
+	return notify.NewNotifier(database, mailer, notify.Config{
+		AdminEmail:     f.NotifyAdminEmail,
+		DigestInterval: f.DigestInterval,
+	}), nil
+}
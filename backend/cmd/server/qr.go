@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/casassg/wedding/backend/internal/qr"
+	"github.com/casassg/wedding/backend/internal/store"
+)
+
+// QRCmd renders the invitation link QR codes of every invite for printing
+type QRCmd struct {
+	MigrationFlags
+	SiteURL string `env:"SITE_URL" default:"https://lauraygerard.wedding" help:"Public site the invitation links point to"`
+	Lang    string `enum:",es,en,ca" default:"" help:"Language prefix for the links (es, en or ca), empty to let the site pick"`
+	Format  string `enum:"png,svg" default:"png" help:"Image format (png or svg)"`
+	Size    int    `default:"600" help:"PNG width and height in pixels"`
+	Out     string `short:"o" default:"invitations.zip" help:"ZIP file to write, with one image per invite and manifest.csv"`
+}
+
+func (cmd *QRCmd) Run() error {
+	ctx := context.Background()
+
+	database, err := cmd.openMigrated(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	invites, err := database.ListInvites(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list invites: %w", err)
+	}
+	invites = slices.DeleteFunc(invites, (*store.Invite).Disabled)
+	if len(invites) == 0 {
+		return fmt.Errorf("no invites in the database, run sync first")
+	}
+
+	var buf bytes.Buffer
+	opts := qr.Options{SiteURL: cmd.SiteURL, Lang: cmd.Lang, Format: cmd.Format, Size: cmd.Size}
+	if err := qr.WriteZIP(&buf, invites, opts); err != nil {
+		return err
+	}
+	if err := os.WriteFile(cmd.Out, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", cmd.Out, err)
+	}
+
+	fmt.Printf("Wrote %d QR codes to %s (e.g. %s)\n", len(invites), cmd.Out, qr.Link(cmd.SiteURL, cmd.Lang, invites[0].InviteCode))
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/api"
+)
+
+// primaryCheckInterval is how often whilePrimary checks whether this machine
+// became or stopped being the primary
+const primaryCheckInterval = 5 * time.Second
+
+// RegionFlags tell whether this machine is the primary, which has the
+// writable database and runs the sheet sync, on Fly.io
+type RegionFlags struct {
+	LiteFSDir     string `env:"LITEFS_DIR" help:"LiteFS mount holding the database, whose lease decides the primary"`
+	PrimaryRegion string `env:"PRIMARY_REGION" help:"Region of the primary machine when not using LiteFS, writes are replayed there from other regions"`
+	FlyRegion     string `env:"FLY_REGION" help:"Region of this machine, set by Fly.io"`
+}
+
+// region returns the region of this machine
+func (f *RegionFlags) region() api.Region {
+	return api.Region{Primary: f.PrimaryRegion, Current: f.FlyRegion, LiteFSDir: f.LiteFSDir}
+}
+
+// whilePrimary runs run while this machine is the primary. It's cancelled
+// when the machine stops being the primary, e.g. the LiteFS lease moved,
+// and started again if it becomes the primary later.
+func whilePrimary(ctx context.Context, region api.Region, name string, run func(ctx context.Context)) {
+	ticker := time.NewTicker(primaryCheckInterval)
+	defer ticker.Stop()
+
+	var cancel context.CancelFunc
+	var done chan struct{}
+	idleLogged := false
+	stop := func() {
+		cancel()
+		<-done
+		cancel = nil
+	}
+
+	for {
+		primary := region.IsPrimary()
+		switch {
+		case primary && cancel == nil:
+			log.Printf("Starting %s on the primary", name)
+			var runCtx context.Context
+			runCtx, cancel = context.WithCancel(ctx)
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				run(runCtx)
+			}()
+		case !primary && cancel != nil:
+			log.Printf("No longer the primary, stopping %s", name)
+			stop()
+		case !primary && !idleLogged:
+			log.Printf("Not the primary, %s runs there", name)
+			idleLogged = true
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if cancel != nil {
+				stop()
+			}
+			return
+		}
+	}
+}
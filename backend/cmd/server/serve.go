@@ -12,19 +12,31 @@ import (
 	"time"
 
 	"github.com/casassg/wedding/backend/internal/api"
-	"github.com/casassg/wedding/backend/internal/sheets"
-	"github.com/casassg/wedding/backend/internal/store"
 )
 
 const shutdownTimeout = 5 * time.Second
 
 // ServeCmd runs the HTTP server
 type ServeCmd struct {
-	DBPath         string `env:"DB_PATH" default:"wedding.db" help:"Path to SQLite database file"`
 	Port           string `env:"PORT" default:"8080" help:"Port to listen on"`
 	AllowedOrigins string `env:"ALLOWED_ORIGINS" default:"https://lauraygerard.wedding,https://www.lauraygerard.wedding" help:"Comma-separated list of allowed CORS origins"`
-	SyncInterval   string `env:"SHEETS_SYNC_INTERVAL" default:"1m" help:"Interval between Google Sheets syncs"`
-	MigrationsDir  string `help:"Path to migrations directory" default:"file:///app/migrations" env:"MIGRATIONS_DIR"`
+	AdminToken     string `env:"ADMIN_TOKEN" help:"Bearer token for the admin API"`
+	AdminUser      string `env:"ADMIN_USER" help:"Basic auth user for the admin API"`
+	AdminPassword  string `env:"ADMIN_PASSWORD" help:"Basic auth password for the admin API"`
+	SiteURL        string `env:"SITE_URL" default:"https://lauraygerard.wedding" help:"Public site, used for invitation links and QR codes"`
+	AssignCodes    bool   `env:"SHEETS_ASSIGN_CODES" help:"Assign invite codes to sheet rows that have a name but no code on every sync"`
+	RSVPDeadline   string `env:"RSVP_DEADLINE" help:"Last day guests can change their RSVP (YYYY-MM-DD or RFC 3339), empty for no deadline"`
+
+	RefreshInterval time.Duration `env:"SHEETS_REFRESH_INTERVAL" default:"30s" help:"Minimum time between guest list reads for unknown invite codes"`
+	MissedCodeTTL   time.Duration `env:"SHEETS_MISSED_CODE_TTL" default:"5m" help:"How long a code still unknown after a guest list read is answered as not found without reading again"`
+
+	MigrationFlags
+	Sync   SyncFlags   `embed:""`
+	Source SourceFlags `embed:""`
+	Notify NotifyFlags `embed:""`
+	Codes  CodeFlags   `embed:""`
+	Limits LimitFlags  `embed:""`
+	Region RegionFlags `embed:""`
 }
 
 func (cmd *ServeCmd) Run() error {
@@ -39,43 +51,113 @@ func (cmd *ServeCmd) Run() error {
 	}
 
 	// Parse sync interval
-	interval, err := time.ParseDuration(cmd.SyncInterval)
+	interval, err := cmd.Sync.interval()
 	if err != nil {
-		return fmt.Errorf("invalid SHEETS_SYNC_INTERVAL: %w", err)
+		return err
 	}
 
+	if (cmd.AdminUser == "") != (cmd.AdminPassword == "") {
+		return fmt.Errorf("ADMIN_USER and ADMIN_PASSWORD must be set together")
+	}
+
+	cal, err := cmd.Source.calendar()
+	if err != nil {
+		return err
+	}
+
+	// Parse RSVP deadline, invites can override it from the sheet
+	var rsvpDeadline time.Time
+	if cmd.RSVPDeadline != "" {
+		if rsvpDeadline, err = cal.ParseDeadline(cmd.RSVPDeadline); err != nil {
+			return fmt.Errorf("invalid RSVP_DEADLINE: %w", err)
+		}
+	}
+
+	region := cmd.Region.region()
+
 	log.Printf("Starting Wedding RSVP API")
+	log.Printf("Region: %s", region)
 	log.Printf("Database: %s", cmd.DBPath)
 	log.Printf("Port: %s", cmd.Port)
 	log.Printf("Allowed origins: %v", allowedOrigins)
 	log.Printf("Sync interval: %s", interval)
+	log.Printf("Wedding timezone: %s", cal.Location)
+	if !rsvpDeadline.IsZero() {
+		log.Printf("RSVP deadline: %s", rsvpDeadline.Format(time.RFC3339))
+	}
 
-	// Initialize database
-	database, err := store.Open(cmd.DBPath)
+	// Initialize database and apply pending migrations. Replicas read the
+	// primary's copy and leave migrating it to the primary.
+	database, err := cmd.openForRegion(ctx, region)
 	if err != nil {
-		return fmt.Errorf("failed to initialize database: %w", err)
+		return err
 	}
 	defer database.Close()
 
-	// Initialize Google Sheets client
-	sheetsClient, err := sheets.NewClient(ctx)
+	// Initialize guest list source (Google Sheets or local file)
+	source, err := cmd.Source.open(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to initialize sheets client: %w", err)
+		return err
 	}
 
-	// Start background sync (only on primary region)
-	syncer := sheets.NewSyncer(database, sheetsClient)
-	// Run initial sync
-	log.Printf("Running initial sync...")
-	if err := syncer.SyncOnce(ctx); err != nil {
-		log.Printf("initial sync failed: %s", err)
+	// Create sync, run below on the primary only
+	syncer, err := cmd.Source.syncer(database, source)
+	if err != nil {
+		return err
+	}
+	if cmd.AssignCodes {
+		gen, err := cmd.Codes.generator()
+		if err != nil {
+			return err
+		}
+		syncer.SetCodeGenerator(gen)
+	}
+	syncer.SetRefreshLimits(cmd.RefreshInterval, cmd.MissedCodeTTL)
+
+	notifier, err := cmd.Notify.open(database)
+	if err != nil {
+		return err
 	}
 
-	// Start sync in background goroutine
-	go syncer.Start(ctx, interval)
+	// Only the primary writes to the database: replicas get the guest list
+	// and the email outbox through the replicated copy. Which machine is the
+	// primary can change while running, see whilePrimary.
+	go whilePrimary(ctx, region, "sheet sync", func(ctx context.Context) {
+		// Run initial sync
+		log.Printf("Running initial sync...")
+		if err := syncer.SyncOnce(ctx); err != nil {
+			log.Printf("initial sync failed: %s", err)
+		}
+
+		syncer.Start(ctx, interval)
+	})
+	go whilePrimary(ctx, region, "email dispatch", func(ctx context.Context) {
+		notifier.Start(ctx, cmd.Notify.DispatchInterval)
+	})
+
+	trustedProxies, err := cmd.Limits.trustedProxies()
+	if err != nil {
+		return err
+	}
 
 	// Create HTTP router
-	router := api.NewRouter(database, syncer, allowedOrigins)
+	router := api.NewRouter(ctx, database, syncer, api.Options{
+		AllowedOrigins: allowedOrigins,
+		Notifier:       notifier,
+		RSVPDeadline:   rsvpDeadline,
+		SiteURL:        cmd.SiteURL,
+		SyncStaleAfter: cmd.Sync.staleAfter(interval),
+		Timezone:       cal.Location,
+		RateLimits:     cmd.Limits.rateLimits(),
+		LookupGuard:    cmd.Limits.lookupGuard(),
+		TrustedProxies: trustedProxies,
+		Region:         region,
+		Admin: api.AdminCredentials{
+			Token:    cmd.AdminToken,
+			User:     cmd.AdminUser,
+			Password: cmd.AdminPassword,
+		},
+	})
 
 	// Create HTTP server
 	server := &http.Server{
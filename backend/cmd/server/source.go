@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/casassg/wedding/backend/internal/sheets"
+	"github.com/casassg/wedding/backend/internal/store"
+)
+
+// SourceFlags selects the guest list backend used by the syncer
+type SourceFlags struct {
+	GuestListFile   string `env:"GUEST_LIST_FILE" help:"Local CSV or YAML guest list to sync with instead of Google Sheets"`
+	ScheduleFile    string `env:"SCHEDULE_FILE" help:"Local CSV schedule with the Schedule sheet columns (CSV guest lists only)"`
+	ColumnAliases   string `env:"GOOGLE_SHEET_COLUMN_ALIASES" help:"Extra header names per column, e.g. 'partner=Plus one|Pareja'"`
+	RemovedInvites  string `env:"SHEETS_REMOVED_INVITES" enum:"disable,keep,delete" default:"disable" help:"What sync does with invites whose row was removed from the guest list: disable, keep or delete"`
+	WeddingYear     int    `env:"WEDDING_YEAR" default:"2026" help:"Year of schedule day headers that don't include one, e.g. 'Friday Dec 18'"`
+	WeddingTimezone string `env:"WEDDING_TIMEZONE" default:"America/Tegucigalpa" help:"IANA timezone of schedule times and date-only RSVP deadlines"`
+}
+
+// calendar returns how dates in the guest list are read
+func (f *SourceFlags) calendar() (*sheets.Calendar, error) {
+	cal, err := sheets.NewCalendar(f.WeddingYear, f.WeddingTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEDDING_YEAR or WEDDING_TIMEZONE: %w", err)
+	}
+	return cal, nil
+}
+
+// open returns the file-backed source when a guest list file is set,
+// otherwise the Google Sheets client (which may be unconfigured).
+func (f *SourceFlags) open(ctx context.Context) (sheets.Source, error) {
+	cal, err := f.calendar()
+	if err != nil {
+		return nil, err
+	}
+
+	if f.GuestListFile == "" {
+		client, err := sheets.NewClient(ctx, cal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize sheets client: %w", err)
+		}
+		return client, nil
+	}
+
+	aliases, err := sheets.ParseColumnAliases(f.ColumnAliases)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GOOGLE_SHEET_COLUMN_ALIASES: %w", err)
+	}
+
+	source, err := sheets.NewFileSource(f.GuestListFile, f.ScheduleFile, aliases, cal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize guest list file: %w", err)
+	}
+	return source, nil
+}
+
+// syncer creates a syncer between the database and source
+func (f *SourceFlags) syncer(database *store.Store, source sheets.Source) (*sheets.Syncer, error) {
+	syncer := sheets.NewSyncer(database, source)
+	if err := syncer.SetRemovedInvites(f.RemovedInvites); err != nil {
+		return nil, fmt.Errorf("invalid SHEETS_REMOVED_INVITES: %w", err)
+	}
+	return syncer, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/casassg/wedding/backend/internal/api"
+)
+
+// StatsCmd prints RSVP totals for headcount planning
+type StatsCmd struct {
+	MigrationFlags
+	JSON bool `help:"Print the same JSON as the admin stats endpoint"`
+}
+
+func (cmd *StatsCmd) Run() error {
+	ctx := context.Background()
+
+	database, err := cmd.openMigrated(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	stats, err := database.GetStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	response := api.ToStatsResponse(stats)
+	if cmd.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(response)
+	}
+
+	invites := response.Invites
+	fmt.Printf("Invites:  %d total, %d responded (%.1f%%), %d attending, %d declined, %d pending\n",
+		invites.Total, invites.Responded, response.ResponseRate*100, invites.Attending, invites.Declined, invites.Pending)
+	fmt.Printf("Adults:   %d confirmed of %d invited\n", response.Adults.Confirmed, response.Adults.Invited)
+	fmt.Printf("Kids:     %d confirmed of %d invited\n", response.Kids.Confirmed, response.Kids.Invited)
+	fmt.Printf("Headcount: %d\n", response.Adults.Confirmed+response.Kids.Confirmed)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	if len(response.Dietary) > 0 {
+		fmt.Fprintln(w, "\nDIETARY\tINVITES\tGUESTS")
+		for _, row := range response.Dietary {
+			fmt.Fprintf(w, "%s\t%d\t%d\n", row.DietaryInfo, row.Invites, row.Guests)
+		}
+	}
+
+	if len(response.MealChoices) > 0 {
+		fmt.Fprintln(w, "\nMEAL\tGUESTS")
+		for _, row := range response.MealChoices {
+			meal := row.MealChoice
+			if meal == "" {
+				meal = "(not chosen)"
+			}
+			fmt.Fprintf(w, "%s\t%d\n", meal, row.Guests)
+		}
+	}
+
+	if len(response.ResponsesByLastAnswerDay) > 0 {
+		fmt.Fprintln(w, "\nLAST ANSWER (UTC)\tRESPONSES\tADULTS\tKIDS\tDECLINES\tTOTAL RESPONSES\tTOTAL CONFIRMED")
+		for _, row := range response.ResponsesByLastAnswerDay {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\n",
+				row.Day, row.Responses, row.ConfirmedAdults, row.ConfirmedKids, row.Declines,
+				row.TotalResponses, row.TotalConfirmed)
+		}
+	}
+
+	return w.Flush()
+}
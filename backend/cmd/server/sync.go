@@ -2,39 +2,109 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"text/tabwriter"
+	"time"
 
+	"github.com/casassg/wedding/backend/internal/api"
 	"github.com/casassg/wedding/backend/internal/sheets"
-	"github.com/casassg/wedding/backend/internal/store"
 )
 
-// SyncCmd forces an immediate sync
+// SyncCmd runs or inspects the sync between database and guest list
 type SyncCmd struct {
-	DBPath string `env:"DB_PATH" default:"wedding.db" help:"Path to SQLite database file"`
+	Run    SyncRunCmd    `cmd:"" help:"Force an immediate sync between database and Google Sheets" default:"withargs"`
+	Status SyncStatusCmd `cmd:"" help:"Show the latest sync runs and whether the sync is stale"`
 }
 
-func (cmd *SyncCmd) Run() error {
+// SyncFlags configures the sync interval and when the sync counts as stale
+type SyncFlags struct {
+	SyncInterval       string `env:"SHEETS_SYNC_INTERVAL" default:"1m" help:"Interval between Google Sheets syncs"`
+	SyncStaleIntervals int    `env:"SHEETS_SYNC_STALE_INTERVALS" default:"3" help:"Report degraded health after this many sync intervals without a successful sync, 0 to never"`
+}
+
+// interval parses the sync interval
+func (f *SyncFlags) interval() (time.Duration, error) {
+	interval, err := time.ParseDuration(f.SyncInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid SHEETS_SYNC_INTERVAL: %w", err)
+	}
+	return interval, nil
+}
+
+// staleAfter returns how long without a successful sync counts as stale
+func (f *SyncFlags) staleAfter(interval time.Duration) time.Duration {
+	return time.Duration(max(f.SyncStaleIntervals, 0)) * interval
+}
+
+// SyncRunCmd forces an immediate sync
+type SyncRunCmd struct {
+	AssignCodes bool `env:"SHEETS_ASSIGN_CODES" help:"Assign invite codes to rows that have a name but no code"`
+	DryRun      bool `help:"Print what the sync would change without writing to the database or the sheet"`
+	JSON        bool `help:"Print the dry run changes as JSON"`
+
+	MigrationFlags
+	Source SourceFlags `embed:""`
+	Codes  CodeFlags   `embed:""`
+}
+
+func (cmd *SyncRunCmd) Run() error {
 	ctx := context.Background()
 
+	if cmd.JSON && !cmd.DryRun {
+		return fmt.Errorf("--json only applies to --dry-run")
+	}
+
 	log.Printf("Starting manual sync")
 	log.Printf("Database: %s", cmd.DBPath)
 
-	// Initialize database
-	database, err := store.Open(cmd.DBPath)
+	// Initialize database and apply pending migrations, a dry run doesn't
+	// migrate either
+	open := cmd.openMigrated
+	if cmd.DryRun {
+		open = cmd.openCurrent
+	}
+	database, err := open(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to initialize database: %w", err)
+		return err
 	}
 	defer database.Close()
 
-	// Initialize Google Sheets client
-	sheetsClient, err := sheets.NewClient(ctx)
+	// Initialize guest list source (Google Sheets or local file)
+	source, err := cmd.Source.open(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to initialize sheets client: %w", err)
+		return err
 	}
 
 	// Create syncer and run once
-	syncer := sheets.NewSyncer(database, sheetsClient)
+	syncer, err := cmd.Source.syncer(database, source)
+	if err != nil {
+		return err
+	}
+	if cmd.AssignCodes {
+		gen, err := cmd.Codes.generator()
+		if err != nil {
+			return err
+		}
+		syncer.SetCodeGenerator(gen)
+	}
+
+	if cmd.DryRun {
+		diff, err := syncer.Diff(ctx)
+		if err != nil {
+			return fmt.Errorf("dry run failed: %w", err)
+		}
+		if cmd.JSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(diff)
+		}
+		printSyncDiff(diff)
+		return nil
+	}
 
 	log.Printf("Starting sync cycle...")
 	if err := syncer.SyncOnce(ctx); err != nil {
@@ -44,3 +114,189 @@ func (cmd *SyncCmd) Run() error {
 	log.Printf("Sync completed successfully")
 	return nil
 }
+
+// printSyncDiff prints a dry run's changes grouped by direction
+func printSyncDiff(diff *sheets.SyncDiff) {
+	if diff.Empty() {
+		fmt.Println("Nothing to sync")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	if len(diff.CodesAssigned) > 0 {
+		fmt.Fprintf(w, "\nCODES TO ASSIGN (%d)\n", len(diff.CodesAssigned))
+		for _, code := range diff.CodesAssigned {
+			label := code.InviteCode
+			if code.Placeholder {
+				label += " (placeholder)"
+			}
+			fmt.Fprintf(w, "  row %d\t%s\t%s\n", code.Row, label, code.Name)
+		}
+	}
+
+	printInviteDiffs(w, "INVITES ADDED FROM SHEET", diff.InvitesAdded)
+	printInviteDiffs(w, "INVITES CHANGED FROM SHEET", diff.InvitesChanged)
+	printInviteDiffs(w, "INVITES REMOVED FROM SHEET", diff.InvitesRemoved)
+	printInviteDiffs(w, "ADMIN CHANGES TO WRITE", diff.InvitesToWrite)
+
+	if len(diff.RSVPsToPush) > 0 {
+		fmt.Fprintf(w, "\nRSVPS TO PUSH (%d)\n", len(diff.RSVPsToPush))
+		for _, push := range diff.RSVPsToPush {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%d adults, %d kids\t%s\n",
+				push.InviteCode, push.Name, sheetRowLabel(push.SheetRow), push.ConfirmedAdults, push.ConfirmedKids, push.Note)
+		}
+	}
+
+	printInviteDiffs(w, "ROWS TO DELETE", diff.RowsToDelete)
+	printScheduleDiffs(w, "SCHEDULE EVENTS ADDED", diff.ScheduleAdded)
+	printScheduleDiffs(w, "SCHEDULE EVENTS CHANGED", diff.ScheduleChanged)
+	printScheduleDiffs(w, "SCHEDULE EVENTS REMOVED", diff.ScheduleRemoved)
+	printEventDiffs(w, "EVENTS ADDED FROM SHEET", diff.EventsAdded)
+	printEventDiffs(w, "EVENTS CHANGED FROM SHEET", diff.EventsChanged)
+	printEventDiffs(w, "EVENTS REMOVED FROM SHEET", diff.EventsRemoved)
+	printInvitationDiffs(w, "INVITATIONS ADDED FROM SHEET", diff.InvitationsAdded)
+	printInvitationDiffs(w, "INVITATIONS CHANGED FROM SHEET", diff.InvitationsChanged)
+	printInvitationDiffs(w, "INVITATIONS REMOVED FROM SHEET", diff.InvitationsRemoved)
+
+	if len(diff.EventRSVPsToPush) > 0 {
+		fmt.Fprintf(w, "\nEVENT RSVPS TO PUSH (%d)\n", len(diff.EventRSVPsToPush))
+		for _, push := range diff.EventRSVPsToPush {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%d adults, %d kids\t%s\n",
+				push.EventKey, push.InviteCode, sheetRowLabel(push.SheetRow), push.ConfirmedAdults, push.ConfirmedKids, push.Note)
+		}
+	}
+}
+
+func printInviteDiffs(w io.Writer, title string, invites []*sheets.InviteDiff) {
+	if len(invites) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s (%d)\n", title, len(invites))
+	for _, invite := range invites {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", invite.InviteCode, invite.Name, sheetRowLabel(invite.SheetRow), invite.Note)
+		printFieldChanges(w, invite.Changes)
+	}
+}
+
+func printScheduleDiffs(w io.Writer, title string, events []*sheets.ScheduleDiff) {
+	if len(events) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s (%d)\n", title, len(events))
+	for _, event := range events {
+		fmt.Fprintf(w, "  %s\t%s\n", event.StartTime, event.Name)
+		printFieldChanges(w, event.Changes)
+	}
+}
+
+func printEventDiffs(w io.Writer, title string, events []*sheets.EventDiff) {
+	if len(events) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s (%d)\n", title, len(events))
+	for _, event := range events {
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", event.EventKey, event.Name, event.Note)
+		printFieldChanges(w, event.Changes)
+	}
+}
+
+func printInvitationDiffs(w io.Writer, title string, invitations []*sheets.InvitationDiff) {
+	if len(invitations) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s (%d)\n", title, len(invitations))
+	for _, invitation := range invitations {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", invitation.EventKey, invitation.InviteCode, sheetRowLabel(invitation.SheetRow), invitation.Note)
+		printFieldChanges(w, invitation.Changes)
+	}
+}
+
+func printFieldChanges(w io.Writer, changes []*sheets.FieldChange) {
+	for _, change := range changes {
+		fmt.Fprintf(w, "    %s: %q -> %q\n", change.Field, change.Old, change.New)
+	}
+}
+
+func sheetRowLabel(row *int64) string {
+	if row == nil {
+		return "no row"
+	}
+	return fmt.Sprintf("row %d", *row)
+}
+
+// SyncStatusCmd prints the sync history recorded by the server and sync runs
+type SyncStatusCmd struct {
+	MigrationFlags
+	Source SourceFlags `embed:""`
+	Sync   SyncFlags   `embed:""`
+	Limit  int64       `default:"20" help:"Number of runs to show"`
+	JSON   bool        `help:"Print the same JSON as the admin sync status endpoint"`
+}
+
+func (cmd *SyncStatusCmd) Run() error {
+	ctx := context.Background()
+
+	interval, err := cmd.Sync.interval()
+	if err != nil {
+		return err
+	}
+
+	database, err := cmd.openMigrated(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	source, err := cmd.Source.open(ctx)
+	if err != nil {
+		return err
+	}
+
+	status, err := database.GetSyncStatus(ctx, cmd.Limit)
+	if err != nil {
+		return err
+	}
+
+	// Without a running server there's no start time, so a database that
+	// never synced successfully counts as stale
+	staleAfter := cmd.Sync.staleAfter(interval)
+	enabled := source.IsConfigured()
+	stale := enabled && staleAfter > 0 && status.Stale(time.Now(), time.Time{}, staleAfter)
+
+	response := api.ToSyncStatusResponse(status, enabled, stale)
+	if cmd.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(response)
+	}
+
+	switch {
+	case !enabled:
+		fmt.Println("Status:       sync disabled (guest list source not configured)")
+	case staleAfter > 0:
+		fmt.Printf("Status:       %s (stale after %s without a successful sync)\n", response.Status, staleAfter)
+	default:
+		fmt.Printf("Status:       %s\n", response.Status)
+	}
+	if last := response.LastSuccess; last != nil {
+		fmt.Printf("Last success: %s (%s ago)\n", last.FinishedAt, time.Since(status.LastSuccess.FinishedAt).Round(time.Second))
+	} else {
+		fmt.Println("Last success: never")
+	}
+
+	if len(response.Runs) == 0 {
+		fmt.Println("\nNo sync runs recorded")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nSTARTED (UTC)\tDURATION\tINVITES READ\tRSVPS PUSHED\tRSVPS FAILED\tSCHEDULE EVENTS\tERROR")
+	for _, run := range response.Runs {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%s\n",
+			run.StartedAt, time.Duration(run.DurationMs)*time.Millisecond, run.InvitesRead,
+			run.RSVPsPushed, run.RSVPsFailed, run.ScheduleEvents, run.Error)
+	}
+	return w.Flush()
+}
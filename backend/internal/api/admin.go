@@ -0,0 +1,432 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/qr"
+	"github.com/casassg/wedding/backend/internal/store"
+	"github.com/pkg/errors"
+)
+
+// AdminListInvites handles GET /api/v1/admin/invites
+// Optional ?q= filters by name (substring) or invite code (prefix)
+func (h *Handler) AdminListInvites(w http.ResponseWriter, r *http.Request) {
+	search := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	invites, err := h.db.ListInvites(r.Context(), store.EscapeLike(search))
+	if err != nil {
+		log.Printf("Error listing invites: %v", err)
+		respondError(w, "Failed to list invites", http.StatusInternalServerError)
+		return
+	}
+
+	responses := make([]AdminInviteResponse, 0, len(invites))
+	for _, invite := range invites {
+		responses = append(responses, ToAdminInviteResponse(invite, nil))
+	}
+
+	respondJSON(w, AdminInviteListResponse{Invites: responses}, http.StatusOK)
+}
+
+// AdminGetInvite handles GET /api/v1/admin/invites/{invite_code}
+func (h *Handler) AdminGetInvite(w http.ResponseWriter, r *http.Request) {
+	h.respondAdminInvite(w, r, r.PathValue("invite_code"), http.StatusOK)
+}
+
+// AdminCreateInvite handles POST /api/v1/admin/invites
+// The invite is appended to the sheet on the next sync.
+func (h *Handler) AdminCreateInvite(w http.ResponseWriter, r *http.Request) {
+	var req AdminInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.InviteCode = strings.TrimSpace(req.InviteCode)
+	if req.InviteCode == "" || strings.ContainsAny(req.InviteCode, " \t/?#") {
+		respondError(w, "invite_code is required and can't contain spaces, '/', '?' or '#'", http.StatusBadRequest)
+		return
+	}
+	if err := validateAdminInvite(&req); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	params := &store.CreateInviteParams{
+		InviteCode: req.InviteCode,
+		Name:       req.Name,
+		MaxAdults:  req.MaxAdults,
+		MaxKids:    req.MaxKids,
+	}
+	err := h.db.AddInvite(r.Context(), params)
+	if errors.Is(err, store.ErrInviteExists) || errors.Is(err, store.ErrInviteDeletionPending) {
+		respondError(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error creating invite %s: %v", req.InviteCode, err)
+		respondError(w, "Failed to create invite", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Admin created invite %s", req.InviteCode)
+	h.syncer.TriggerSync()
+
+	h.respondAdminInvite(w, r, req.InviteCode, http.StatusCreated)
+}
+
+// AdminUpdateInvite handles PUT /api/v1/admin/invites/{invite_code}
+// Updates the name and limits; the sheet row is updated on the next sync.
+func (h *Handler) AdminUpdateInvite(w http.ResponseWriter, r *http.Request) {
+	inviteCode := r.PathValue("invite_code")
+
+	var req AdminInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.InviteCode != "" && req.InviteCode != inviteCode {
+		respondError(w, "invite_code can't be changed", http.StatusBadRequest)
+		return
+	}
+	if err := validateAdminInvite(&req); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err := h.db.GetInviteByInviteCode(r.Context(), inviteCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(w, "Invite not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching invite %s: %v", inviteCode, err)
+		respondError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	params := &store.UpdateInviteDetailsParams{
+		Name:       req.Name,
+		MaxAdults:  req.MaxAdults,
+		MaxKids:    req.MaxKids,
+		InviteCode: inviteCode,
+	}
+	err = h.db.EditInvite(r.Context(), params)
+	if errors.Is(err, store.ErrInviteLimitsTooLow) {
+		respondError(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating invite %s: %v", inviteCode, err)
+		respondError(w, "Failed to update invite", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Admin updated invite %s", inviteCode)
+	h.syncer.TriggerSync()
+
+	h.respondAdminInvite(w, r, inviteCode, http.StatusOK)
+}
+
+// AdminDeleteInvite handles DELETE /api/v1/admin/invites/{invite_code}
+// The invite's sheet row is removed on the next sync. Its RSVP history is kept.
+func (h *Handler) AdminDeleteInvite(w http.ResponseWriter, r *http.Request) {
+	inviteCode := r.PathValue("invite_code")
+
+	err := h.db.RemoveInvite(r.Context(), inviteCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(w, "Invite not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting invite %s: %v", inviteCode, err)
+		respondError(w, "Failed to delete invite", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Admin deleted invite %s", inviteCode)
+	h.syncer.TriggerSync()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdminPostRSVP handles POST /api/v1/admin/invites/{invite_code}/rsvp
+// Overrides an invite's RSVP on the guest's behalf, same payload as the public endpoint.
+func (h *Handler) AdminPostRSVP(w http.ResponseWriter, r *http.Request) {
+	inviteCode := r.PathValue("invite_code")
+
+	var req RSVPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	invite, err := h.db.GetInviteByInviteCode(r.Context(), inviteCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(w, "Invite not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching invite %s: %v", inviteCode, err)
+		respondError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	events, err := h.db.ListInvitedEvents(r.Context(), inviteCode)
+	if err != nil {
+		log.Printf("Error fetching events for invite %s: %v", inviteCode, err)
+		respondError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if err := validateRSVP(&req, invite, events); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dbReq := store.UpdateRSVPParams{
+		InputConfirmedAdults: req.AdultCount,
+		InputConfirmedKids:   req.KidCount,
+		InputDietaryInfo:     req.DietaryInfo,
+		InputMessage:         req.MessageForUs,
+		InputSong:            req.SongRequest,
+		InputInviteCode:      inviteCode,
+	}
+
+	change := store.RSVPChange{Source: store.RSVPSourceAdmin, ClientIP: getIP(r)}
+	if !applyIfMatch(w, r, &change) {
+		return
+	}
+	err = h.db.SaveRSVP(r.Context(), &dbReq, toGuestParams(req), toEventRSVPParams(req), change)
+	if errors.Is(err, store.ErrRSVPRejected) {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, store.ErrRSVPConflict) {
+		respondErrorCode(w, ErrCodeRSVPConflict, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	if errors.Is(err, store.ErrInviteDisabled) {
+		respondErrorCode(w, ErrCodeInviteDisabled, err.Error(), http.StatusGone)
+		return
+	}
+	if err != nil {
+		log.Printf("Error saving admin RSVP for invite %s: %v", inviteCode, err)
+		respondError(w, "Failed to save RSVP", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Admin overrode RSVP for invite %s", inviteCode)
+	h.syncer.TriggerSync()
+
+	h.respondAdminInvite(w, r, inviteCode, http.StatusOK)
+}
+
+// AdminGetInviteHistory handles GET /api/v1/admin/invites/{invite_code}/history
+// Returns the invite's RSVP timeline with client IPs, oldest first. Works for
+// deleted invites too.
+func (h *Handler) AdminGetInviteHistory(w http.ResponseWriter, r *http.Request) {
+	inviteCode := r.PathValue("invite_code")
+
+	events, err := h.db.ListRSVPEventsByInviteCode(r.Context(), inviteCode)
+	if err != nil {
+		log.Printf("Error fetching history for invite %s: %v", inviteCode, err)
+		respondError(w, "Failed to fetch history", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, ToAdminRSVPHistoryResponse(events), http.StatusOK)
+}
+
+// AdminGetStats handles GET /api/v1/admin/stats
+// Returns headcounts, response rate, dietary breakdown and responses per day,
+// plus failed invite lookups since this machine started
+func (h *Handler) AdminGetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.db.GetStats(r.Context())
+	if err != nil {
+		log.Printf("Error computing stats: %v", err)
+		respondError(w, "Failed to compute stats", http.StatusInternalServerError)
+		return
+	}
+
+	response := ToStatsResponse(stats)
+	response.Lookups = ToLookupStatsResponse(h.guard.Stats(time.Now()))
+	respondJSON(w, response, http.StatusOK)
+}
+
+// AdminGetSyncStatus handles GET /api/v1/admin/sync/status
+// Returns the last successful sync and the latest runs, ?limit= of them (default 20)
+func (h *Handler) AdminGetSyncStatus(w http.ResponseWriter, r *http.Request) {
+	limit := int64(20)
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 1 || n > 500 {
+			respondError(w, "limit not valid, must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	status, err := h.db.GetSyncStatus(r.Context(), limit)
+	if err != nil {
+		log.Printf("Error fetching sync status: %v", err)
+		respondError(w, "Failed to fetch sync status", http.StatusInternalServerError)
+		return
+	}
+
+	enabled := h.syncer.Enabled()
+	stale := enabled && h.syncStaleAfter > 0 && status.Stale(time.Now(), h.started, h.syncStaleAfter)
+	respondJSON(w, ToSyncStatusResponse(status, enabled, stale), http.StatusOK)
+}
+
+// AdminGetInviteQR handles GET /api/v1/admin/invites/{invite_code}/qr
+// Returns the QR code of the invite's link. Optional ?lang= adds a language
+// prefix to the link, ?format=png|svg and ?size= in pixels for PNGs.
+func (h *Handler) AdminGetInviteQR(w http.ResponseWriter, r *http.Request) {
+	inviteCode := r.PathValue("invite_code")
+
+	opts, err := h.qrOptions(r)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err = h.db.GetInviteByInviteCode(r.Context(), inviteCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(w, "Invite not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching invite %s: %v", inviteCode, err)
+		respondError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	image, err := qr.Render(qr.Link(opts.SiteURL, opts.Lang, inviteCode), opts)
+	if err != nil {
+		log.Printf("Error rendering QR code for invite %s: %v", inviteCode, err)
+		respondError(w, "Failed to render QR code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", qr.ContentType(opts.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s.%s"`, inviteCode, opts.Format))
+	w.WriteHeader(http.StatusOK)
+	w.Write(image)
+}
+
+// AdminGetQRZip handles GET /api/v1/admin/qr.zip
+// Returns a ZIP with the QR code of every invite plus a CSV manifest for the
+// printer. Takes the same ?lang=, ?format= and ?size= as AdminGetInviteQR.
+func (h *Handler) AdminGetQRZip(w http.ResponseWriter, r *http.Request) {
+	opts, err := h.qrOptions(r)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	invites, err := h.db.ListInvites(r.Context(), "")
+	if err != nil {
+		log.Printf("Error listing invites: %v", err)
+		respondError(w, "Failed to list invites", http.StatusInternalServerError)
+		return
+	}
+	invites = slices.DeleteFunc(invites, (*store.Invite).Disabled)
+
+	var buf bytes.Buffer
+	if err := qr.WriteZIP(&buf, invites, opts); err != nil {
+		log.Printf("Error rendering QR codes: %v", err)
+		respondError(w, "Failed to render QR codes", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="invitations.zip"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// qrOptions reads the QR code query parameters
+func (h *Handler) qrOptions(r *http.Request) (qr.Options, error) {
+	query := r.URL.Query()
+	opts := qr.Options{
+		SiteURL: h.siteURL,
+		Lang:    query.Get("lang"),
+		Format:  query.Get("format"),
+		Size:    qr.DefaultSize,
+	}
+
+	if opts.Lang != "" && !slices.Contains(Languages, opts.Lang) {
+		return opts, fmt.Errorf("lang not valid, must be one of %s", strings.Join(Languages, ", "))
+	}
+	if opts.Format == "" {
+		opts.Format = qr.FormatPNG
+	}
+	if opts.Format != qr.FormatPNG && opts.Format != qr.FormatSVG {
+		return opts, fmt.Errorf("format not valid, must be png or svg")
+	}
+	if size := query.Get("size"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < 64 || n > 4096 {
+			return opts, fmt.Errorf("size not valid, must be between 64 and 4096")
+		}
+		opts.Size = n
+	}
+	return opts, nil
+}
+
+// respondAdminInvite sends an invite with its guests
+func (h *Handler) respondAdminInvite(w http.ResponseWriter, r *http.Request, inviteCode string, status int) {
+	invite, err := h.db.GetInviteByInviteCode(r.Context(), inviteCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(w, "Invite not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching invite %s: %v", inviteCode, err)
+		respondError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	guests, err := h.db.ListGuestsByInviteCode(r.Context(), inviteCode)
+	if err != nil {
+		log.Printf("Error fetching guests for invite %s: %v", inviteCode, err)
+		respondError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	events, err := h.db.ListInvitedEvents(r.Context(), inviteCode)
+	if err != nil {
+		log.Printf("Error fetching events for invite %s: %v", inviteCode, err)
+		respondError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := ToAdminInviteResponse(invite, guests)
+	response.Events = toInviteEventResponses(events)
+	respondJSON(w, response, status)
+}
+
+// validateAdminInvite checks an invite's name and limits
+func validateAdminInvite(req *AdminInviteRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if req.MaxAdults < 1 || req.MaxAdults > 2 {
+		return fmt.Errorf("max_adults not valid, must be 1 or 2")
+	}
+	if req.MaxKids < 0 {
+		return fmt.Errorf("max_kids not valid, must be 0 or more")
+	}
+	return nil
+}
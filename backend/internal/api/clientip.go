@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TrustedFly is the TrustedProxies entry trusting Fly.io's edge
+const TrustedFly = "fly"
+
+// flyProxyRanges are the private networks Fly.io's edge proxy connects from
+var flyProxyRanges = []netip.Prefix{
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("fdaa::/16"),
+}
+
+// ipv6KeyBits is the prefix length IPv6 clients are keyed by in rate limits
+// and lookup lockouts. A single client usually gets a whole /64 and could
+// otherwise use a new address for every request.
+const ipv6KeyBits = 64
+
+// clientIPKey is the request context key of the client IP
+type clientIPKey struct{}
+
+// TrustedProxies decides whose forwarded headers are believed when telling
+// the client IP, which rate limits and lookup lockouts are keyed by. Headers
+// from anyone else are ignored, otherwise every request could claim a new IP.
+type TrustedProxies struct {
+	fly      bool           // Believe Fly-Client-IP from Fly.io's edge
+	prefixes []netip.Prefix // Believe X-Forwarded-For and X-Real-IP from these
+}
+
+// ParseTrustedProxies parses a comma separated list of CIDRs or IPs, and
+// "fly" for Fly.io's edge. An empty list trusts no one.
+func ParseTrustedProxies(list string) (*TrustedProxies, error) {
+	t := &TrustedProxies{}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+			continue
+		case strings.EqualFold(entry, TrustedFly):
+			t.fly = true
+		case strings.Contains(entry, "/"):
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid trusted proxy %q", entry)
+			}
+			t.prefixes = append(t.prefixes, prefix.Masked())
+		default:
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid trusted proxy %q", entry)
+			}
+			addr = addr.Unmap()
+			t.prefixes = append(t.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return t, nil
+}
+
+// String returns the trusted proxies in the format ParseTrustedProxies takes
+func (t *TrustedProxies) String() string {
+	var entries []string
+	if t.fly {
+		entries = append(entries, TrustedFly)
+	}
+	for _, prefix := range t.prefixes {
+		entries = append(entries, prefix.String())
+	}
+	if len(entries) == 0 {
+		return "none"
+	}
+	return strings.Join(entries, ",")
+}
+
+// Middleware resolves the client IP once and stores it in the request
+// context for getIP. Must come before anything that calls getIP.
+func (t *TrustedProxies) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), clientIPKey{}, t.ClientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ClientIP returns the IP of the client that sent the request. Fly-Client-IP
+// is believed from Fly.io's edge; X-Forwarded-For and X-Real-IP from trusted
+// proxies. Otherwise it's the address of the connection.
+func (t *TrustedProxies) ClientIP(r *http.Request) string {
+	peer := remoteIP(r)
+	if !peer.IsValid() {
+		return r.RemoteAddr
+	}
+
+	if t.fly && inPrefixes(flyProxyRanges, peer) {
+		if ip, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("Fly-Client-IP"))); err == nil {
+			return ip.Unmap().String()
+		}
+	}
+
+	if !inPrefixes(t.prefixes, peer) {
+		return peer.String()
+	}
+
+	// Each proxy appends who it got the request from, so walk back from the
+	// last hop and stop at the first address that isn't a trusted proxy.
+	// Entries further left are whatever the client sent.
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		client := peer
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			client = hop.Unmap()
+			if !inPrefixes(t.prefixes, client) {
+				break
+			}
+		}
+		return client.String()
+	}
+
+	if ip, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return ip.Unmap().String()
+	}
+	return peer.String()
+}
+
+// clientKey returns what per-client limits are keyed by: the client IP, or
+// its /64 for IPv6 clients
+func clientKey(r *http.Request) string {
+	ip := getIP(r)
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || !addr.Is6() {
+		return ip
+	}
+	return netip.PrefixFrom(addr.WithZone(""), ipv6KeyBits).Masked().String()
+}
+
+// remoteIP returns the IP of the connection, invalid if RemoteAddr isn't one
+func remoteIP(r *http.Request) netip.Addr {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+// inPrefixes reports whether addr is in any of prefixes
+func inPrefixes(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted string
+		peer    string
+		headers map[string][]string
+		want    string
+	}{
+		{
+			name: "no proxy",
+			peer: "203.0.113.7:4000",
+			want: "203.0.113.7",
+		},
+		{
+			name:    "spoofed X-Forwarded-For from untrusted peer",
+			trusted: "10.0.0.0/8",
+			peer:    "203.0.113.7:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4"}},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "spoofed X-Real-IP from untrusted peer",
+			trusted: "10.0.0.0/8",
+			peer:    "203.0.113.7:4000",
+			headers: map[string][]string{"X-Real-IP": {"1.2.3.4"}},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "single trusted proxy",
+			trusted: "10.0.0.1",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.9"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "client prepends a fake hop",
+			trusted: "10.0.0.1",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4, 198.51.100.9"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "chain of trusted proxies",
+			trusted: "10.0.0.0/8,192.168.1.1",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4, 198.51.100.9, 192.168.1.1, 10.0.0.2"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "chain split across headers",
+			trusted: "10.0.0.0/8",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.9", "10.0.0.3"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "every hop trusted",
+			trusted: "10.0.0.0/8",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}},
+			want:    "10.0.0.3",
+		},
+		{
+			name:    "X-Real-IP from trusted proxy",
+			trusted: "10.0.0.1",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Real-IP": {"198.51.100.9"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "trusted proxy without forwarded headers",
+			trusted: "10.0.0.1",
+			peer:    "10.0.0.1:4000",
+			want:    "10.0.0.1",
+		},
+		{
+			name:    "Fly-Client-IP from Fly.io's edge",
+			trusted: "fly",
+			peer:    "172.16.3.4:4000",
+			headers: map[string][]string{"Fly-Client-IP": {"198.51.100.9"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "Fly-Client-IP from Fly.io's IPv6 edge",
+			trusted: "fly",
+			peer:    "[fdaa:0:1::3]:4000",
+			headers: map[string][]string{"Fly-Client-IP": {"2001:db8::1"}},
+			want:    "2001:db8::1",
+		},
+		{
+			name:    "Fly-Client-IP from outside Fly.io",
+			trusted: "fly",
+			peer:    "203.0.113.7:4000",
+			headers: map[string][]string{"Fly-Client-IP": {"1.2.3.4"}},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "Fly-Client-IP when Fly.io isn't trusted",
+			trusted: "10.0.0.0/8",
+			peer:    "172.16.3.4:4000",
+			headers: map[string][]string{"Fly-Client-IP": {"1.2.3.4"}},
+			want:    "172.16.3.4",
+		},
+		{
+			name:    "X-Forwarded-For from Fly.io's edge is ignored",
+			trusted: "fly",
+			peer:    "172.16.3.4:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4"}},
+			want:    "172.16.3.4",
+		},
+		{
+			name: "IPv4-mapped peer",
+			peer: "[::ffff:203.0.113.7]:4000",
+			want: "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trusted, err := ParseTrustedProxies(tt.trusted)
+			if err != nil {
+				t.Fatalf("ParseTrustedProxies(%q): %v", tt.trusted, err)
+			}
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.peer
+			for key, values := range tt.headers {
+				for _, value := range values {
+					r.Header.Add(key, value)
+				}
+			}
+			if got := trusted.ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    string
+		wantErr bool
+	}{
+		{list: "", want: "none"},
+		{list: " fly , 10.0.0.0/8", want: "fly,10.0.0.0/8"},
+		{list: "10.1.2.3/8", want: "10.0.0.0/8"},
+		{list: "10.0.0.1,::ffff:10.0.0.2", want: "10.0.0.1/32,10.0.0.2/32"},
+		{list: "2001:db8::1", want: "2001:db8::1/128"},
+		{list: "10.0.0.0/33", wantErr: true},
+		{list: "proxy.internal", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.list, func(t *testing.T) {
+			trusted, err := ParseTrustedProxies(tt.list)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseTrustedProxies(%q) = %s, want an error", tt.list, trusted)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTrustedProxies(%q): %v", tt.list, err)
+			}
+			if got := trusted.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "203.0.113.7", want: "203.0.113.7"},
+		{ip: "2001:db8:1:2:3:4:5:6", want: "2001:db8:1:2::/64"},
+		{ip: "2001:db8:1:2:ffff:ffff:ffff:ffff", want: "2001:db8:1:2::/64"},
+		{ip: "2001:db8:1:3::1", want: "2001:db8:1:3::/64"},
+		{ip: "fe80::1%eth0", want: "fe80::/64"},
+		{ip: "not-an-ip", want: "not-an-ip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r = r.WithContext(context.WithValue(r.Context(), clientIPKey{}, tt.ip))
+			if got := clientKey(r); got != tt.want {
+				t.Errorf("clientKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
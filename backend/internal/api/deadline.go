@@ -0,0 +1,44 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/store"
+)
+
+// ErrCodeRSVPClosed is returned when an RSVP is changed after its deadline
+const ErrCodeRSVPClosed = "rsvp_closed"
+
+// deadlineFor returns an invite's RSVP deadline: its own override from the
+// sheet, else the default. Nil means the RSVP can always be changed.
+func (h *Handler) deadlineFor(invite *store.Invite) *time.Time {
+	if invite.RsvpDeadline != nil {
+		return invite.RsvpDeadline
+	}
+	if !h.rsvpDeadline.IsZero() {
+		return &h.rsvpDeadline
+	}
+	return nil
+}
+
+// canEditRSVP reports whether an RSVP with the given deadline can still be changed
+func canEditRSVP(deadline *time.Time, now time.Time) bool {
+	return deadline == nil || !now.After(*deadline)
+}
+
+// rsvpClosedMessage explains in lang that the deadline has passed, Spanish by default.
+// The date is shown in the deadline's location, the wedding's timezone.
+func rsvpClosedMessage(lang string, day time.Time) string {
+	switch lang {
+	case "en":
+		return fmt.Sprintf("The RSVP deadline was %s. If you need to change your answer, please contact us directly.",
+			day.Format("January 2, 2006"))
+	case "ca":
+		return fmt.Sprintf("El termini per confirmar l'assistència va acabar el %s. Si necessites canviar la resposta, escriu-nos directament.",
+			day.Format("02/01/2006"))
+	default:
+		return fmt.Sprintf("El plazo para confirmar asistencia terminó el %s. Si necesitas cambiar tu respuesta, escríbenos directamente.",
+			day.Format("02/01/2006"))
+	}
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/casassg/wedding/backend/internal/store"
+)
+
+// ErrCodeInviteDisabled is returned for invites whose row was removed from the sheet
+const ErrCodeInviteDisabled = "invite_disabled"
+
+// respondInviteDisabled tells the guest in lang that their invite is no longer
+// valid, falling back to the language of their last RSVP
+func respondInviteDisabled(w http.ResponseWriter, invite *store.Invite, lang string) {
+	if !slices.Contains(Languages, lang) {
+		lang = invite.Lang
+	}
+	respondErrorCode(w, ErrCodeInviteDisabled, inviteDisabledMessage(lang), http.StatusGone)
+}
+
+// inviteDisabledMessage explains in lang that the invite is no longer valid, Spanish by default
+func inviteDisabledMessage(lang string) string {
+	switch lang {
+	case "en":
+		return "This invitation is no longer valid. If you think this is a mistake, please contact us directly."
+	case "ca":
+		return "Aquesta invitació ja no és vàlida. Si creus que és un error, escriu-nos directament."
+	default:
+		return "Esta invitación ya no es válida. Si crees que es un error, escríbenos directamente."
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/casassg/wedding/backend/internal/store"
+)
+
+// ErrCodeRSVPConflict is returned when If-Match doesn't match the current RSVP
+const ErrCodeRSVPConflict = "rsvp_conflict"
+
+// formatETag quotes an RSVP version as a strong ETag
+func formatETag(version string) string {
+	return `"` + version + `"`
+}
+
+// parseIfMatch returns the versions listed in an If-Match header. ok is false
+// when the header is absent or "*", i.e. the request is unconditional. Weak
+// tags are skipped since If-Match uses strong comparison, so a header with
+// only weak tags matches nothing.
+func parseIfMatch(r *http.Request) (versions []string, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, false
+	}
+	versions = []string{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") || len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		versions = append(versions, tag[1:len(tag)-1])
+	}
+	return versions, true
+}
+
+// applyIfMatch makes an RSVP change conditional on the request's If-Match.
+// Returns false after responding 412 when no listed tag can ever match.
+func applyIfMatch(w http.ResponseWriter, r *http.Request, change *store.RSVPChange) bool {
+	versions, ok := parseIfMatch(r)
+	if !ok {
+		return true
+	}
+	if len(versions) == 0 {
+		respondErrorCode(w, ErrCodeRSVPConflict, store.ErrRSVPConflict.Error(), http.StatusPreconditionFailed)
+		return false
+	}
+	change.IfMatch = versions
+	return true
+}
+
+// rsvpVersion loads an invite, its guests and its event invitations and
+// returns their current version
+func (h *Handler) rsvpVersion(ctx context.Context, inviteCode string) (string, error) {
+	invite, err := h.db.GetInviteByInviteCode(ctx, inviteCode)
+	if err != nil {
+		return "", err
+	}
+	guests, err := h.db.ListGuestsByInviteCode(ctx, inviteCode)
+	if err != nil {
+		return "", err
+	}
+	invitations, err := h.db.ListInviteEventsByInviteCode(ctx, inviteCode)
+	if err != nil {
+		return "", err
+	}
+	return store.RSVPVersion(invite, guests, invitations), nil
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/store"
+)
+
+// validateEventRSVPs checks the answers for the invite's other events: each
+// must be for an event the invite is invited to, at most once, and within
+// that event's limits
+func validateEventRSVPs(answers []EventRSVPRequest, invited []*store.InvitedEvent) error {
+	seen := make(map[string]bool, len(answers))
+	for i, answer := range answers {
+		var invitation *store.InviteEvent
+		for _, event := range invited {
+			if event.Invitation.EventKey == answer.Key {
+				invitation = event.Invitation
+				break
+			}
+		}
+		if invitation == nil {
+			return fmt.Errorf("events[%d].key not valid, the invite is not invited to %q", i, answer.Key)
+		}
+		if seen[answer.Key] {
+			return fmt.Errorf("events[%d].key %q is answered more than once", i, answer.Key)
+		}
+		seen[answer.Key] = true
+
+		if answer.AdultCount < 0 || answer.AdultCount > invitation.MaxAdults {
+			return fmt.Errorf("events[%d].adult_count not valid, must be between 0 and %d", i, invitation.MaxAdults)
+		}
+		if answer.KidCount < 0 || answer.KidCount > invitation.MaxKids {
+			return fmt.Errorf("events[%d].kid_count not valid, must be between 0 and %d", i, invitation.MaxKids)
+		}
+	}
+	return nil
+}
+
+// toEventRSVPParams converts the request's event answers to store params
+func toEventRSVPParams(req RSVPRequest) []*store.UpdateInviteEventRSVPParams {
+	params := make([]*store.UpdateInviteEventRSVPParams, 0, len(req.Events))
+	for _, answer := range req.Events {
+		params = append(params, &store.UpdateInviteEventRSVPParams{
+			ConfirmedAdults: answer.AdultCount,
+			ConfirmedKids:   answer.KidCount,
+			EventKey:        answer.Key,
+		})
+	}
+	return params
+}
+
+// toInviteEventResponses converts an invite's other events to API responses
+func toInviteEventResponses(events []*store.InvitedEvent) []InviteEventResponse {
+	responses := make([]InviteEventResponse, 0, len(events))
+	for _, invited := range events {
+		event, invitation := invited.Event, invited.Invitation
+		response := InviteEventResponse{
+			Key: event.EventKey,
+			Name: ScheduleEventI18nText{
+				ES: event.NameEs,
+				EN: event.NameEn,
+				CA: event.NameCa,
+			},
+			StartTime:       event.StartTime,
+			Timezone:        event.Timezone,
+			Location:        event.Location,
+			MaxAdults:       int(invitation.MaxAdults),
+			MaxKids:         int(invitation.MaxKids),
+			HasResponded:    invitation.ResponseAt != nil,
+			IsAttending:     invitation.ConfirmedAdults > 0,
+			ConfirmedAdults: int(invitation.ConfirmedAdults),
+			ConfirmedKids:   int(invitation.ConfirmedKids),
+		}
+		if invitation.ResponseAt != nil {
+			response.ResponseAt = invitation.ResponseAt.UTC().Format(time.RFC3339)
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lookupPrefixLength is how many leading characters of an invite code count
+// as its prefix. Short enough that an enumerator spread over many IPs still
+// piles up failures on each prefix.
+const lookupPrefixLength = 2
+
+// lookupForgetAfter is how long an IP or prefix must go without failed
+// lookups for its lockouts to stop escalating
+const lookupForgetAfter = 24 * time.Hour
+
+// maxLookupRecords caps the IPs and prefixes tracked, see prune
+const maxLookupRecords = 10000
+
+// maxSuspicious caps the IPs and prefixes listed in the admin stats
+const maxSuspicious = 20
+
+// LookupGuardConfig configures the failed lookup lockouts. Zero fields use
+// the defaults of DefaultLookupGuardConfig.
+type LookupGuardConfig struct {
+	Window         time.Duration // Failed lookups are counted within this window
+	IPFailures     int           // Failed lookups from one IP before it's locked out
+	PrefixFailures int           // Failed lookups of one code prefix, from any IP, before it's locked out
+	Lockout        time.Duration // First lockout, doubled for each one after it
+	MaxLockout     time.Duration // Longest lockout
+}
+
+// DefaultLookupGuardConfig returns the default lockout settings
+func DefaultLookupGuardConfig() LookupGuardConfig {
+	return LookupGuardConfig{
+		Window:         10 * time.Minute,
+		IPFailures:     5,
+		PrefixFailures: 20,
+		Lockout:        time.Minute,
+		MaxLockout:     time.Hour,
+	}
+}
+
+// LookupGuard locks out IPs (keyed by clientKey) and invite code prefixes
+// with too many failed lookups, since invite codes are the only credential.
+// Lockouts escalate while the failures go on. State is kept in memory, per
+// machine: with replicas, each machine counts only the lookups it served, so
+// a client reaching several of them gets each one's allowance.
+type LookupGuard struct {
+	mu       sync.Mutex
+	config   LookupGuardConfig
+	machine  string // Hostname, tells whose counters Stats returns
+	ips      map[string]*lookupRecord
+	prefixes map[string]*lookupRecord
+	failed   int64 // Failed lookups since start
+	lockouts int64 // Lockouts since start
+}
+
+// lookupRecord tracks the failed lookups of an IP or code prefix
+type lookupRecord struct {
+	windowStart time.Time
+	failures    int // Within the window starting at windowStart
+	lockouts    int // Escalates the next lockout
+	lockedUntil time.Time
+	lastFailure time.Time
+}
+
+// LookupActivity is an IP or code prefix with recent failed lookups
+type LookupActivity struct {
+	Key         string
+	Failures    int       // In the current window
+	Lockouts    int       // Since it was last forgotten
+	LockedUntil time.Time // Zero if not locked out
+}
+
+// LookupStats summarizes failed lookups since the server started, on this
+// machine only
+type LookupStats struct {
+	Machine       string
+	FailedLookups int64
+	Lockouts      int64
+	IPs           []*LookupActivity // Locked out first, then most failures
+	Prefixes      []*LookupActivity // Locked out first, then most failures
+}
+
+// NewLookupGuard creates a lookup guard
+func NewLookupGuard(config LookupGuardConfig) *LookupGuard {
+	defaults := DefaultLookupGuardConfig()
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.IPFailures <= 0 {
+		config.IPFailures = defaults.IPFailures
+	}
+	if config.PrefixFailures <= 0 {
+		config.PrefixFailures = defaults.PrefixFailures
+	}
+	if config.Lockout <= 0 {
+		config.Lockout = defaults.Lockout
+	}
+	if config.MaxLockout < config.Lockout {
+		config.MaxLockout = max(defaults.MaxLockout, config.Lockout)
+	}
+
+	machine, _ := os.Hostname()
+	return &LookupGuard{
+		config:   config,
+		machine:  machine,
+		ips:      make(map[string]*lookupRecord),
+		prefixes: make(map[string]*lookupRecord),
+	}
+}
+
+// Middleware rejects invite lookups from locked out IPs, or of locked out
+// code prefixes from IPs that failed lookups themselves, and counts the
+// lookups answered 404 as failed. Wraps handlers of routes with an
+// {invite_code}.
+func (g *LookupGuard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := clientKey(r)
+		prefix := codePrefix(r.PathValue("invite_code"))
+
+		if until := g.lockedUntil(ip, prefix, time.Now()); !until.IsZero() {
+			retryAfter := int(time.Until(until).Seconds()) + 1
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			respondErrorCode(w, "lookup_locked", "Too many failed invite code lookups, try again later", http.StatusTooManyRequests)
+			return
+		}
+
+		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(wrapped, r)
+
+		if wrapped.statusCode == http.StatusNotFound {
+			g.fail(ip, prefix, time.Now())
+		}
+	})
+}
+
+// lockedUntil returns when the lockout of the IP or prefix ends, whichever
+// is later, or zero if neither is locked out. A prefix lockout only applies
+// to IPs with failed lookups of their own within the window, so guests with
+// valid codes aren't locked out by someone else guessing.
+func (g *LookupGuard) lockedUntil(ip, prefix string, now time.Time) time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	records := []*lookupRecord{g.ips[ip]}
+	if ipRecord := g.ips[ip]; ipRecord != nil && now.Sub(ipRecord.lastFailure) <= g.config.Window {
+		records = append(records, g.prefixes[prefix])
+	}
+
+	var until time.Time
+	for _, record := range records {
+		if record != nil && record.lockedUntil.After(now) && record.lockedUntil.After(until) {
+			until = record.lockedUntil
+		}
+	}
+	return until
+}
+
+// fail records a failed lookup, locking out the IP or prefix once they
+// reach their limit
+func (g *LookupGuard) fail(ip, prefix string, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.failed++
+	if g.record(g.ips, ip, g.config.IPFailures, now) {
+		logLockout("IP", ip, g.ips[ip])
+	}
+	if g.record(g.prefixes, prefix, g.config.PrefixFailures, now) {
+		logLockout("code prefix", prefix, g.prefixes[prefix])
+	}
+}
+
+// record counts a failure for key and reports whether it got locked out
+func (g *LookupGuard) record(records map[string]*lookupRecord, key string, limit int, now time.Time) bool {
+	record := records[key]
+	if record == nil || now.Sub(record.lastFailure) > lookupForgetAfter {
+		if len(records) >= maxLookupRecords {
+			g.prune(records, now)
+		}
+		record = &lookupRecord{windowStart: now}
+		records[key] = record
+	}
+
+	if now.Sub(record.windowStart) > g.config.Window {
+		record.windowStart = now
+		record.failures = 0
+	}
+	record.failures++
+	record.lastFailure = now
+
+	if record.failures < limit || record.lockedUntil.After(now) {
+		return false
+	}
+
+	// Double the lockout each time, up to the maximum
+	lockout := g.config.Lockout
+	for i := 0; i < record.lockouts && lockout < g.config.MaxLockout; i++ {
+		lockout *= 2
+	}
+	record.lockouts++
+	record.lockedUntil = now.Add(min(lockout, g.config.MaxLockout))
+	record.windowStart = now
+	record.failures = 0
+	g.lockouts++
+	return true
+}
+
+// prune drops records that are neither locked out nor failed within the
+// window, or every record if that's not enough, so the maps stay bounded
+func (g *LookupGuard) prune(records map[string]*lookupRecord, now time.Time) {
+	for key, record := range records {
+		if !record.lockedUntil.After(now) && now.Sub(record.lastFailure) > g.config.Window {
+			delete(records, key)
+		}
+	}
+	if len(records) >= maxLookupRecords {
+		clear(records)
+	}
+}
+
+// Stats returns the failed lookups since start and the IPs and prefixes
+// that are locked out or failed within the window
+func (g *LookupGuard) Stats(now time.Time) *LookupStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return &LookupStats{
+		Machine:       g.machine,
+		FailedLookups: g.failed,
+		Lockouts:      g.lockouts,
+		IPs:           g.activity(g.ips, now),
+		Prefixes:      g.activity(g.prefixes, now),
+	}
+}
+
+// activity lists the suspicious records, the longest locked out first and
+// then the most failures
+func (g *LookupGuard) activity(records map[string]*lookupRecord, now time.Time) []*LookupActivity {
+	var activity []*LookupActivity
+	for key, record := range records {
+		locked := record.lockedUntil.After(now)
+		recent := now.Sub(record.windowStart) <= g.config.Window && record.failures > 0
+		if !locked && !recent {
+			continue
+		}
+		entry := &LookupActivity{Key: key, Lockouts: record.lockouts}
+		if recent {
+			entry.Failures = record.failures
+		}
+		if locked {
+			entry.LockedUntil = record.lockedUntil
+		}
+		activity = append(activity, entry)
+	}
+
+	slices.SortFunc(activity, func(a, b *LookupActivity) int {
+		if !a.LockedUntil.Equal(b.LockedUntil) {
+			return b.LockedUntil.Compare(a.LockedUntil)
+		}
+		if a.Failures != b.Failures {
+			return b.Failures - a.Failures
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	if len(activity) > maxSuspicious {
+		activity = activity[:maxSuspicious]
+	}
+	return activity
+}
+
+// codePrefix returns the prefix of an invite code that failures are grouped by
+func codePrefix(inviteCode string) string {
+	if len(inviteCode) > lookupPrefixLength {
+		return inviteCode[:lookupPrefixLength]
+	}
+	return inviteCode
+}
+
+// logLockout logs a new lockout of an IP or code prefix
+func logLockout(kind, key string, record *lookupRecord) {
+	log.Printf("Locked out %s %s until %s after repeated failed invite lookups (lockout #%d)",
+		kind, key, record.lockedUntil.UTC().Format(time.RFC3339), record.lockouts)
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testGuardConfig locks an IP out after 3 failures and a prefix after 5
+func testGuardConfig() LookupGuardConfig {
+	return LookupGuardConfig{
+		Window:         10 * time.Minute,
+		IPFailures:     3,
+		PrefixFailures: 5,
+		Lockout:        time.Minute,
+		MaxLockout:     4 * time.Minute,
+	}
+}
+
+func TestLookupGuardIPLockout(t *testing.T) {
+	g := NewLookupGuard(testGuardConfig())
+	now := time.Now()
+
+	for i := range 2 {
+		g.fail("203.0.113.1", fmt.Sprintf("A%d", i), now)
+	}
+	if until := g.lockedUntil("203.0.113.1", "ZZ", now); !until.IsZero() {
+		t.Fatalf("locked out after 2 failures, until %s", until)
+	}
+
+	g.fail("203.0.113.1", "A2", now)
+	if until := g.lockedUntil("203.0.113.1", "ZZ", now); !until.Equal(now.Add(time.Minute)) {
+		t.Errorf("lockedUntil after 3 failures = %s, want %s", until, now.Add(time.Minute))
+	}
+	if until := g.lockedUntil("203.0.113.2", "ZZ", now); !until.IsZero() {
+		t.Errorf("another IP is locked out until %s", until)
+	}
+	if until := g.lockedUntil("203.0.113.1", "ZZ", now.Add(time.Minute)); !until.IsZero() {
+		t.Errorf("still locked out after the lockout ended, until %s", until)
+	}
+}
+
+func TestLookupGuardEscalation(t *testing.T) {
+	g := NewLookupGuard(testGuardConfig())
+	now := time.Now()
+
+	// Each lockout doubles the last one, up to MaxLockout
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute} {
+		for range 3 {
+			g.fail("203.0.113.1", fmt.Sprintf("X%d", i), now)
+		}
+		until := g.lockedUntil("203.0.113.1", "ZZ", now)
+		if got := until.Sub(now); got != want {
+			t.Errorf("lockout %d = %s, want %s", i+1, got, want)
+		}
+		now = until
+	}
+	if got := g.Stats(now).Lockouts; got != 4 {
+		t.Errorf("Stats().Lockouts = %d, want 4", got)
+	}
+
+	// Failures while locked out don't extend the lockout
+	now = now.Add(time.Minute)
+	for range 3 {
+		g.fail("203.0.113.1", "Y0", now)
+	}
+	locked := g.lockedUntil("203.0.113.1", "ZZ", now)
+	for range 3 {
+		g.fail("203.0.113.1", "Y1", now.Add(time.Second))
+	}
+	if until := g.lockedUntil("203.0.113.1", "ZZ", now.Add(time.Second)); !until.Equal(locked) {
+		t.Errorf("lockout moved from %s to %s while locked out", locked, until)
+	}
+
+	// A day without failures starts over from the first lockout
+	now = now.Add(lookupForgetAfter + time.Hour)
+	for range 3 {
+		g.fail("203.0.113.1", "Z0", now)
+	}
+	if got := g.lockedUntil("203.0.113.1", "ZZ", now).Sub(now); got != time.Minute {
+		t.Errorf("lockout after being forgotten = %s, want %s", got, time.Minute)
+	}
+}
+
+func TestLookupGuardWindow(t *testing.T) {
+	g := NewLookupGuard(testGuardConfig())
+	now := time.Now()
+
+	// Failures spread over more than the window never add up to the limit
+	for i := range 6 {
+		at := now.Add(time.Duration(i) * 6 * time.Minute)
+		g.fail("203.0.113.1", fmt.Sprintf("W%d", i), at)
+		if until := g.lockedUntil("203.0.113.1", "ZZ", at); !until.IsZero() {
+			t.Fatalf("locked out after failure %d, until %s", i+1, until)
+		}
+	}
+}
+
+func TestLookupGuardPrefixLockout(t *testing.T) {
+	g := NewLookupGuard(testGuardConfig())
+	now := time.Now()
+
+	// Five IPs guessing codes starting with AB once each lock out the prefix
+	for i := range 5 {
+		g.fail(fmt.Sprintf("198.51.100.%d", i), "AB", now)
+	}
+	want := now.Add(time.Minute)
+
+	tests := []struct {
+		name   string
+		ip     string
+		prefix string
+		want   time.Time
+	}{
+		{name: "IP that failed lookups", ip: "198.51.100.0", prefix: "AB", want: want},
+		{name: "IP that never failed", ip: "203.0.113.1", prefix: "AB"},
+		{name: "IP that failed another prefix", ip: "203.0.113.2", prefix: "AB", want: want},
+		{name: "other prefix", ip: "198.51.100.0", prefix: "CD"},
+	}
+	g.fail("203.0.113.2", "CD", now)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if until := g.lockedUntil(tt.ip, tt.prefix, now); !until.Equal(tt.want) {
+				t.Errorf("lockedUntil(%s, %s) = %s, want %s", tt.ip, tt.prefix, until, tt.want)
+			}
+		})
+	}
+
+	// Failures older than the window no longer count as the IP's own
+	later := now.Add(11 * time.Minute)
+	g.fail("198.51.100.9", "AB", now)
+	for i := range 5 {
+		g.fail(fmt.Sprintf("192.0.2.%d", i), "AB", later)
+	}
+	if until := g.lockedUntil("198.51.100.9", "AB", later); !until.IsZero() {
+		t.Errorf("IP whose failures are older than the window is locked out until %s", until)
+	}
+	if until := g.lockedUntil("192.0.2.0", "AB", later); until.IsZero() {
+		t.Errorf("IP with recent failures isn't locked out of the prefix")
+	}
+}
+
+func TestLookupGuardPrune(t *testing.T) {
+	g := NewLookupGuard(testGuardConfig())
+	now := time.Now()
+
+	for i := range maxLookupRecords + 100 {
+		g.fail(fmt.Sprintf("10.%d.%d.%d", i>>16, i>>8&0xff, i&0xff), "AB", now)
+	}
+	if got := len(g.ips); got > maxLookupRecords {
+		t.Errorf("tracking %d IPs, want at most %d", got, maxLookupRecords)
+	}
+}
+
+func TestLookupGuardMiddleware(t *testing.T) {
+	g := NewLookupGuard(testGuardConfig())
+	mux := http.NewServeMux()
+	mux.Handle("GET /invite/{invite_code}", g.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("invite_code") == "GOOD1" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})))
+
+	lookup := func(code string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/invite/"+code, nil)
+		r.RemoteAddr = "203.0.113.1:4000"
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	for i, code := range []string{"GOOD1", "BAD1", "GOOD1", "BAD2", "BAD3"} {
+		if w := lookup(code); w.Code == http.StatusTooManyRequests {
+			t.Fatalf("lookup %d locked out", i+1)
+		}
+	}
+	w := lookup("GOOD1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("lookup after 3 failures = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Errorf("locked out response has no Retry-After")
+	}
+
+	stats := g.Stats(time.Now())
+	if stats.FailedLookups != 3 || stats.Lockouts != 1 {
+		t.Errorf("Stats() = %d failed, %d lockouts, want 3 and 1", stats.FailedLookups, stats.Lockouts)
+	}
+	if len(stats.IPs) != 1 || stats.IPs[0].Key != "203.0.113.1" || stats.IPs[0].LockedUntil.IsZero() {
+		t.Errorf("Stats().IPs doesn't list the locked out IP: %+v", stats.IPs)
+	}
+}
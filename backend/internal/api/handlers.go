@@ -6,7 +6,12 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/casassg/wedding/backend/internal/notify"
 	"github.com/casassg/wedding/backend/internal/sheets"
 	"github.com/casassg/wedding/backend/internal/store"
 	"github.com/pkg/errors"
@@ -14,13 +19,38 @@ import (
 
 // Handler holds the API dependencies
 type Handler struct {
-	db     *store.Store
-	syncer *sheets.Syncer
+	db           *store.Store
+	syncer       *sheets.Syncer
+	notifier     *notify.Notifier
+	rsvpDeadline time.Time // Default deadline, invites may override it
+	siteURL      string
+	timezone     *time.Location // Wedding's timezone
+	guard        *LookupGuard   // Failed invite lookups, shown in the admin stats
+	region       Region         // Replicas replay lookups of unknown codes to the primary
+
+	syncStaleAfter time.Duration // Zero disables the sync check in /health
+	started        time.Time     // Stands in for the last sync until one succeeds
 }
 
 // NewHandler creates a new API handler
-func NewHandler(database *store.Store, syncer *sheets.Syncer) *Handler {
-	return &Handler{db: database, syncer: syncer}
+func NewHandler(database *store.Store, syncer *sheets.Syncer, opts Options) *Handler {
+	timezone := opts.Timezone
+	if timezone == nil {
+		timezone = time.UTC
+	}
+	return &Handler{
+		db:           database,
+		syncer:       syncer,
+		notifier:     opts.Notifier,
+		rsvpDeadline: opts.RSVPDeadline,
+		siteURL:      opts.SiteURL,
+		timezone:     timezone,
+		guard:        NewLookupGuard(opts.LookupGuard),
+		region:       opts.Region,
+
+		syncStaleAfter: opts.SyncStaleAfter,
+		started:        time.Now(),
+	}
 }
 
 // GetInvite handles GET /api/v1/invite/{invite_code}
@@ -35,15 +65,24 @@ func (h *Handler) GetInvite(w http.ResponseWriter, r *http.Request) {
 	// Get invite from database
 	invite, err := h.db.GetInviteByInviteCode(r.Context(), inviteCode)
 	if errors.Is(err, sql.ErrNoRows) || (invite == nil) {
-		log.Printf("Invite not found for code %s, triggering sync", inviteCode)
-		if err := h.syncer.SyncOnce(r.Context()); err != nil {
+		// The guest may have been added to the sheet since the last sync,
+		// which only the primary can write to the database
+		if !h.region.IsPrimary() {
+			h.region.replay(w, r)
+			return
+		}
+		found, refreshErr := h.syncer.RefreshInvite(r.Context(), inviteCode)
+		if refreshErr != nil {
+			log.Printf("Error refreshing invites for code %s: %v", inviteCode, refreshErr)
+		}
+		if !found {
 			respondError(w, "Invite not found", http.StatusNotFound)
 			return
 		}
-		// Retry fetching invite after sync
+		// Retry fetching invite after the refresh
 		invite, err = h.db.GetInviteByInviteCode(r.Context(), inviteCode)
 		if invite == nil || errors.Is(err, sql.ErrNoRows) {
-			log.Printf("Invite still not found for code %s after sync", inviteCode)
+			log.Printf("Invite still not found for code %s after refresh", inviteCode)
 			respondError(w, "Invite not found", http.StatusNotFound)
 			return
 		}
@@ -53,9 +92,28 @@ func (h *Handler) GetInvite(w http.ResponseWriter, r *http.Request) {
 		respondError(w, "Invite not found", http.StatusNotFound)
 		return
 	}
+	if invite.Disabled() {
+		respondInviteDisabled(w, invite, r.URL.Query().Get("lang"))
+		return
+	}
 
-	// Return public response
-	respondJSON(w, ToInviteResponse(invite), http.StatusOK)
+	guests, err := h.db.ListGuestsByInviteCode(r.Context(), inviteCode)
+	if err != nil {
+		log.Printf("Error fetching guests for invite %s: %v", inviteCode, err)
+		respondError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	events, err := h.db.ListInvitedEvents(r.Context(), inviteCode)
+	if err != nil {
+		log.Printf("Error fetching events for invite %s: %v", inviteCode, err)
+		respondError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// Return public response, its version doubles as the ETag for If-Match
+	response := ToInviteResponse(invite, guests, events, h.deadlineFor(invite), time.Now())
+	w.Header().Set("ETag", formatETag(response.Version))
+	respondJSON(w, response, http.StatusOK)
 }
 
 // PostRSVP handles POST /api/v1/invite/{invite_code}/rsvp
@@ -88,9 +146,29 @@ func (h *Handler) PostRSVP(w http.ResponseWriter, r *http.Request) {
 		respondError(w, "Invite not found", http.StatusNotFound)
 		return
 	}
+	if invite.Disabled() {
+		respondInviteDisabled(w, invite, req.Lang)
+		return
+	}
 
-	// Validate request
-	if err := validateRSVP(req, invite); err != nil {
+	// Answers are final once the deadline has passed
+	if deadline := h.deadlineFor(invite); !canEditRSVP(deadline, time.Now()) {
+		lang := req.Lang
+		if !slices.Contains(Languages, lang) {
+			lang = invite.Lang
+		}
+		respondErrorCode(w, ErrCodeRSVPClosed, rsvpClosedMessage(lang, deadline.In(h.timezone)), http.StatusForbidden)
+		return
+	}
+
+	// Validate request (derives the counts from the guest list if present)
+	events, err := h.db.ListInvitedEvents(r.Context(), inviteCode)
+	if err != nil {
+		log.Printf("Error fetching events for invite %s: %v", inviteCode, err)
+		respondError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if err := validateRSVP(&req, invite, events); err != nil {
 		respondError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -105,25 +183,81 @@ func (h *Handler) PostRSVP(w http.ResponseWriter, r *http.Request) {
 		InputInviteCode:      inviteCode,
 	}
 
-	if err := h.db.UpdateRSVP(r.Context(), &dbReq); err != nil {
+	// With If-Match, only save if nobody changed the RSVP since it was loaded
+	change := store.RSVPChange{Source: store.RSVPSourceGuest, ClientIP: getIP(r)}
+	if !applyIfMatch(w, r, &change) {
+		return
+	}
+	err = h.db.SaveRSVP(r.Context(), &dbReq, toGuestParams(req), toEventRSVPParams(req), change)
+	if errors.Is(err, store.ErrRSVPRejected) {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, store.ErrRSVPConflict) {
+		respondErrorCode(w, ErrCodeRSVPConflict, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	if errors.Is(err, store.ErrInviteDisabled) {
+		respondInviteDisabled(w, invite, req.Lang)
+		return
+	}
+	if err != nil {
+		log.Printf("Error saving RSVP for invite %s: %v", inviteCode, err)
 		respondError(w, "Failed to save RSVP", http.StatusInternalServerError)
 		return
 	}
 
+	// Remember where to send the confirmation, then queue it. Failures are
+	// only logged: the RSVP itself is already saved.
+	if req.Email != invite.Email || req.Lang != invite.Lang {
+		contact := &store.UpdateInviteContactParams{Email: req.Email, Lang: req.Lang, InviteCode: inviteCode}
+		if err := h.db.UpdateInviteContact(r.Context(), contact); err != nil {
+			log.Printf("Error saving contact for invite %s: %v", inviteCode, err)
+		}
+	}
+	h.queueConfirmation(r, inviteCode)
+
 	// Async update to Google Sheets
 	h.syncer.TriggerSync()
 
-	// Return success
-	respondJSON(w, RSVPResponse{Success: true}, http.StatusOK)
+	// Return success with the new version, so the guest can keep editing
+	response := RSVPResponse{Success: true}
+	if version, err := h.rsvpVersion(r.Context(), inviteCode); err != nil {
+		log.Printf("Error reloading invite %s version: %v", inviteCode, err)
+	} else {
+		response.Version = version
+		w.Header().Set("ETag", formatETag(version))
+	}
+	respondJSON(w, response, http.StatusOK)
 }
 
 // Health handles GET /health
+// Reports "degraded" once no sync has succeeded within the stale period. It
+// still answers 200 since restarting the server won't fix the guest list.
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
-	respondJSON(w, HealthResponse{Status: "ok"}, http.StatusOK)
+	response := HealthResponse{Status: StatusOK}
+
+	if h.syncer.Enabled() && h.syncStaleAfter > 0 {
+		status, err := h.db.GetSyncStatus(r.Context(), 0)
+		if err != nil {
+			log.Printf("Error fetching sync status: %v", err)
+			respondError(w, "Failed to fetch sync status", http.StatusInternalServerError)
+			return
+		}
+		if status.LastSuccess != nil {
+			response.LastSyncAt = status.LastSuccess.FinishedAt.UTC().Format(time.RFC3339)
+		}
+		if status.Stale(time.Now(), h.started, h.syncStaleAfter) {
+			response.Status = StatusDegraded
+		}
+	}
+
+	respondJSON(w, response, http.StatusOK)
 }
 
 // GetSchedule handles GET /api/v1/schedule
-// Returns all public schedule events with timezone info
+// Returns all public schedule events with timezone info. Each event carries
+// its own timezone; the top-level one is the wedding's.
 func (h *Handler) GetSchedule(w http.ResponseWriter, r *http.Request) {
 	events, err := h.db.GetScheduleEvents(r.Context())
 	if err != nil {
@@ -133,23 +267,133 @@ func (h *Handler) GetSchedule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Convert to response format
+	zones := newEventZones(h.timezone)
 	eventResponses := make([]ScheduleEventResponse, 0, len(events))
 	for _, event := range events {
-		eventResponses = append(eventResponses, ToScheduleEventResponse(event))
+		eventResponses = append(eventResponses, ToScheduleEventResponse(event, zones.location(event)))
 	}
 
-	// Return response with timezone info (Copan is UTC-6, no DST)
+	// The wedding's offset at the first event, or now if there are none, so
+	// it matches the events when the zone has DST
+	at := time.Now()
+	if len(events) > 0 {
+		if start, err := time.Parse(time.RFC3339, events[0].StartTime); err == nil {
+			at = start
+		}
+	}
 	response := ScheduleResponse{
-		Timezone:       "America/Tegucigalpa",
-		TimezoneOffset: "-06:00",
+		Timezone:       h.timezone.String(),
+		TimezoneOffset: at.In(h.timezone).Format("-07:00"),
 		Events:         eventResponses,
 	}
 
 	respondJSON(w, response, http.StatusOK)
 }
 
-// validateRSVP checks if the RSVP request is valid
-func validateRSVP(req RSVPRequest, invite *store.Invite) error {
+// GetScheduleICS handles GET /api/v1/schedule.ics?lang=es|en|ca
+// Returns the public schedule as an iCalendar feed that calendar apps can
+// subscribe to, so schedule changes synced from the sheet reach guests.
+func (h *Handler) GetScheduleICS(w http.ResponseWriter, r *http.Request) {
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "es"
+	}
+	if !slices.Contains(Languages, lang) {
+		respondError(w, fmt.Sprintf("lang not valid, must be one of %s", strings.Join(Languages, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.db.GetScheduleEvents(r.Context())
+	if err != nil {
+		log.Printf("Error fetching schedule events: %v", err)
+		respondError(w, "Failed to fetch schedule", http.StatusInternalServerError)
+		return
+	}
+
+	calendar, err := renderScheduleICS(events, lang, time.Now(), h.timezone)
+	if err != nil {
+		log.Printf("Error rendering schedule calendar: %v", err)
+		respondError(w, "Failed to render schedule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="schedule.ics"`)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(calendar))
+}
+
+// queueConfirmation queues the RSVP confirmation email for an invite
+func (h *Handler) queueConfirmation(r *http.Request, inviteCode string) {
+	if !h.notifier.IsConfigured() {
+		return
+	}
+
+	invite, err := h.db.GetInviteByInviteCode(r.Context(), inviteCode)
+	if err != nil {
+		log.Printf("Error reloading invite %s for confirmation: %v", inviteCode, err)
+		return
+	}
+	guests, err := h.db.ListGuestsByInviteCode(r.Context(), inviteCode)
+	if err != nil {
+		log.Printf("Error fetching guests of invite %s for confirmation: %v", inviteCode, err)
+		return
+	}
+	events, err := h.db.ListInvitedEvents(r.Context(), inviteCode)
+	if err != nil {
+		log.Printf("Error fetching events of invite %s for confirmation: %v", inviteCode, err)
+		return
+	}
+
+	if err := h.notifier.QueueRSVPConfirmation(r.Context(), invite, guests, events); err != nil {
+		log.Printf("Error queueing confirmation for invite %s: %v", inviteCode, err)
+	}
+}
+
+// validateRSVP checks if the RSVP request is valid.
+// When a guest list is sent, adult_count and kid_count are taken from it.
+// Email and lang default to the ones stored on the invite.
+func validateRSVP(req *RSVPRequest, invite *store.Invite, events []*store.InvitedEvent) error {
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" {
+		req.Email = invite.Email
+	} else if addr, err := mail.ParseAddress(req.Email); err != nil || addr.Address != req.Email {
+		return fmt.Errorf("email not valid")
+	}
+
+	if req.Lang == "" {
+		req.Lang = invite.Lang
+	} else if !slices.Contains(Languages, req.Lang) {
+		return fmt.Errorf("lang not valid, must be one of %s", strings.Join(Languages, ", "))
+	}
+
+	if req.Guests != nil {
+		var adults, kids int64
+		for i, guest := range req.Guests {
+			if strings.TrimSpace(guest.Name) == "" {
+				return fmt.Errorf("guests[%d].name is required", i)
+			}
+			if guest.MealChoice != "" && !slices.Contains(MealChoices, guest.MealChoice) {
+				return fmt.Errorf("guests[%d].meal_choice not valid, must be one of %s", i, strings.Join(MealChoices, ", "))
+			}
+			if guest.IsKid {
+				kids++
+			} else {
+				adults++
+			}
+		}
+
+		if req.AdultCount != 0 && req.AdultCount != adults {
+			return fmt.Errorf("adult_count does not match the %d adult guest(s)", adults)
+		}
+		if req.KidCount != 0 && req.KidCount != kids {
+			return fmt.Errorf("kid_count does not match the %d kid guest(s)", kids)
+		}
+		req.AdultCount = adults
+		req.KidCount = kids
+	}
+
 	// If attending, adult_count is required
 	if req.AdultCount < 0 || req.AdultCount > invite.MaxAdults {
 		return fmt.Errorf("adult_count not valid, must be between 0 and %d", invite.MaxAdults)
@@ -159,7 +403,30 @@ func validateRSVP(req RSVPRequest, invite *store.Invite) error {
 		return fmt.Errorf("kid_count not valid, must be between 0 and %d", invite.MaxKids)
 	}
 
-	return nil
+	return validateEventRSVPs(req.Events, events)
+}
+
+// toGuestParams converts the request's guest list to store params.
+// Returns nil (keep existing guests) when no list was sent, unless the
+// invite is declining, in which case any previous guests are cleared.
+func toGuestParams(req RSVPRequest) []*store.InsertGuestParams {
+	if req.Guests == nil {
+		if req.AdultCount == 0 && req.KidCount == 0 {
+			return []*store.InsertGuestParams{}
+		}
+		return nil
+	}
+
+	guests := make([]*store.InsertGuestParams, 0, len(req.Guests))
+	for _, guest := range req.Guests {
+		guests = append(guests, &store.InsertGuestParams{
+			Name:       strings.TrimSpace(guest.Name),
+			IsKid:      guest.IsKid,
+			MealChoice: guest.MealChoice,
+			Allergies:  strings.TrimSpace(guest.Allergies),
+		})
+	}
+	return guests
 }
 
 // respondJSON sends a JSON response
@@ -173,3 +440,8 @@ func respondJSON(w http.ResponseWriter, data interface{}, status int) {
 func respondError(w http.ResponseWriter, message string, status int) {
 	respondJSON(w, ErrorResponse{Error: message}, status)
 }
+
+// respondErrorCode sends a JSON error response with a machine-readable code
+func respondErrorCode(w http.ResponseWriter, code, message string, status int) {
+	respondJSON(w, ErrorResponse{Error: message, Code: code}, status)
+}
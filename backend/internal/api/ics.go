@@ -0,0 +1,258 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/casassg/wedding/backend/internal/store"
+)
+
+// Calendar settings for the schedule.ics feed
+const (
+	calendarName    = "Laura & Gerard"
+	calendarProdID  = "-//lauraygerard.wedding//Schedule//EN"
+	calendarUIDHost = "lauraygerard.wedding"
+	calendarRefresh = "PT1H" // How often subscribed calendars should re-fetch
+)
+
+// icsTimeFormat is the RFC 5545 local DATE-TIME format used with a TZID
+const icsTimeFormat = "20060102T150405"
+
+// renderScheduleICS renders schedule events as an RFC 5545 VCALENDAR.
+// Names and descriptions use lang, falling back to Spanish when missing.
+// Events are in their own timezone, or loc (the wedding's) if they have none.
+func renderScheduleICS(events []*store.ScheduleEvent, lang string, now time.Time, loc *time.Location) (string, error) {
+	type span struct{ first, last time.Time }
+	zones := newEventZones(loc)
+	spans := map[*time.Location]*span{}
+	var order []*time.Location
+	use := func(loc *time.Location, t time.Time) {
+		if sp := spans[loc]; sp == nil {
+			spans[loc] = &span{t, t}
+			order = append(order, loc)
+		} else if t.Before(sp.first) {
+			sp.first = t
+		} else if t.After(sp.last) {
+			sp.last = t
+		}
+	}
+
+	type vevent struct {
+		event      *store.ScheduleEvent
+		loc        *time.Location
+		start, end time.Time // end is zero if the event has none
+	}
+	vevents := make([]vevent, 0, len(events))
+	for _, event := range events {
+		start, err := time.Parse(time.RFC3339, event.StartTime)
+		if err != nil {
+			return "", fmt.Errorf("invalid start time %q for event %q: %w", event.StartTime, event.EventNameEs, err)
+		}
+		ev := vevent{event: event, loc: zones.location(event), start: start}
+		use(ev.loc, start)
+		if event.EndTime != nil {
+			if end, err := time.Parse(time.RFC3339, *event.EndTime); err == nil && end.After(start) {
+				ev.end = end
+				use(ev.loc, end)
+			}
+		}
+		vevents = append(vevents, ev)
+	}
+	if len(order) == 0 {
+		use(loc, now) // An empty calendar still names the wedding's timezone
+	}
+
+	var b icsBuilder
+	b.line("BEGIN:VCALENDAR")
+	b.line("VERSION:2.0")
+	b.line("PRODID:" + calendarProdID)
+	b.line("CALSCALE:GREGORIAN")
+	b.line("METHOD:PUBLISH")
+	b.line("X-WR-CALNAME:" + icsEscape(calendarName))
+	b.line("X-WR-TIMEZONE:" + loc.String())
+	b.line("REFRESH-INTERVAL;VALUE=DURATION:" + calendarRefresh)
+	b.line("X-PUBLISHED-TTL:" + calendarRefresh)
+	for _, zone := range order {
+		b.vtimezone(zone, spans[zone].first, spans[zone].last)
+	}
+
+	stamp := now.UTC().Format(icsTimeFormat) + "Z"
+	seen := make(map[string]int, len(events))
+	for _, ev := range vevents {
+		name, description := localizedEvent(ev.event, lang)
+		tzid := ev.loc.String()
+
+		b.line("BEGIN:VEVENT")
+		b.line("UID:" + eventUID(ev.event, seen))
+		b.line("DTSTAMP:" + stamp)
+		b.line("DTSTART;TZID=" + tzid + ":" + ev.start.In(ev.loc).Format(icsTimeFormat))
+		if !ev.end.IsZero() {
+			b.line("DTEND;TZID=" + tzid + ":" + ev.end.In(ev.loc).Format(icsTimeFormat))
+		}
+		b.line("SUMMARY:" + icsEscape(name))
+		if ev.event.Location != "" {
+			b.line("LOCATION:" + icsEscape(ev.event.Location))
+		}
+		if description != "" {
+			b.line("DESCRIPTION:" + icsEscape(description))
+		}
+		b.line("END:VEVENT")
+	}
+
+	b.line("END:VCALENDAR")
+	return b.String(), nil
+}
+
+// eventZones resolves the timezone of schedule events, loading each name once
+type eventZones struct {
+	fallback *time.Location
+	loaded   map[string]*time.Location
+}
+
+// newEventZones returns eventZones that use fallback for events without a timezone
+func newEventZones(fallback *time.Location) *eventZones {
+	return &eventZones{fallback: fallback, loaded: map[string]*time.Location{}}
+}
+
+// location returns the event's timezone. Events synced before timezones were
+// stored, or with a zone this server doesn't know, use the fallback.
+func (z *eventZones) location(event *store.ScheduleEvent) *time.Location {
+	if event.Timezone == "" {
+		return z.fallback
+	}
+	if loc, ok := z.loaded[event.Timezone]; ok {
+		return loc
+	}
+	loc, err := time.LoadLocation(event.Timezone)
+	if err != nil {
+		log.Printf("Unknown timezone %q for event %q, using %s: %v", event.Timezone, event.EventNameEs, z.fallback, err)
+		loc = z.fallback
+	}
+	z.loaded[event.Timezone] = loc
+	return loc
+}
+
+// localizedEvent returns an event's name and description in lang, falling back to Spanish
+func localizedEvent(event *store.ScheduleEvent, lang string) (name, description string) {
+	name, description = event.EventNameEs, event.DescriptionEs
+	switch lang {
+	case "en":
+		name, description = firstNonEmpty(event.EventNameEn, name), firstNonEmpty(event.DescriptionEn, description)
+	case "ca":
+		name, description = firstNonEmpty(event.EventNameCa, name), firstNonEmpty(event.DescriptionCa, description)
+	}
+	return name, description
+}
+
+// eventUID returns the UID the sync stored for the event. Schedule events
+// are re-created on every sync, so the database ID can't be used. Events
+// synced before UIDs were stored get the one the sync will give them. seen
+// disambiguates events that share one.
+func eventUID(event *store.ScheduleEvent, seen map[string]int) string {
+	uid := event.Uid
+	if uid == "" {
+		uid = store.ScheduleEventUID(event.StartTime, event.EventNameEs)
+	}
+	if n := seen[uid]; n > 0 {
+		seen[uid] = n + 1
+		uid = fmt.Sprintf("%s-%d", uid, n)
+	} else {
+		seen[uid] = 1
+	}
+	return uid + "@" + calendarUIDHost
+}
+
+// icsBuilder writes CRLF-terminated content lines folded at 75 octets
+type icsBuilder struct {
+	strings.Builder
+}
+
+// line writes a content line, folding it as required by RFC 5545 section 3.1.
+// Folds never split a multi-byte UTF-8 character.
+func (b *icsBuilder) line(s string) {
+	const limit = 75
+	width := 0
+	for len(s) > 0 {
+		_, size := utf8.DecodeRuneInString(s)
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1 // The leading space counts towards the next line
+		}
+		b.WriteString(s[:size])
+		width += size
+		s = s[size:]
+	}
+	b.WriteString("\r\n")
+}
+
+// vtimezone writes a VTIMEZONE for loc with one observance per UTC offset
+// it has between first and last, so zones with DST render correctly
+func (b *icsBuilder) vtimezone(loc *time.Location, first, last time.Time) {
+	b.line("BEGIN:VTIMEZONE")
+	b.line("TZID:" + loc.String())
+	for t := first.In(loc); ; {
+		start, end := t.ZoneBounds()
+		name, offset := t.Zone()
+
+		// The onset is in the local time of the offset before it. Zones that
+		// never changed, or not since 1970, just start then.
+		onset, from := "19700101T000000", offset
+		if !start.IsZero() && start.Year() >= 1970 {
+			_, from = start.Add(-time.Second).Zone()
+			onset = start.UTC().Add(time.Duration(from) * time.Second).Format(icsTimeFormat)
+		}
+
+		kind := "STANDARD"
+		if t.IsDST() {
+			kind = "DAYLIGHT"
+		}
+		b.line("BEGIN:" + kind)
+		b.line("DTSTART:" + onset)
+		b.line("TZOFFSETFROM:" + icsOffset(from))
+		b.line("TZOFFSETTO:" + icsOffset(offset))
+		if name != "" && name[0] != '+' && name[0] != '-' {
+			b.line("TZNAME:" + name)
+		}
+		b.line("END:" + kind)
+
+		if end.IsZero() || end.After(last) {
+			break
+		}
+		t = end
+	}
+	b.line("END:VTIMEZONE")
+}
+
+// icsOffset formats a UTC offset in seconds as "-0600"
+func icsOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// icsEscape escapes TEXT values (RFC 5545 section 3.3.11)
+func icsEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -1,6 +1,9 @@
 package api
 
 import (
+	"container/list"
+	"context"
+	"crypto/subtle"
 	"log"
 	"net/http"
 	"slices"
@@ -59,8 +62,9 @@ func CORS(allowedOrigins []string) Middleware {
 
 			if allowed {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match")
+				w.Header().Set("Access-Control-Expose-Headers", "ETag")
 				w.Header().Set("Access-Control-Max-Age", "3600")
 			}
 
@@ -75,42 +79,181 @@ func CORS(allowedOrigins []string) Middleware {
 	}
 }
 
-// RateLimiter implements per-IP rate limiting using token bucket
+// AdminCredentials configures access to the admin API.
+// Either a bearer token, a basic auth user and password, or both.
+type AdminCredentials struct {
+	Token    string
+	User     string
+	Password string
+}
+
+// Enabled reports whether any admin credentials are configured
+func (c AdminCredentials) Enabled() bool {
+	return c.Token != "" || (c.User != "" && c.Password != "")
+}
+
+// AdminAuth middleware rejects requests without valid admin credentials,
+// sent as "Authorization: Bearer <token>" or HTTP basic auth
+func AdminAuth(creds AdminCredentials) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if creds.authorized(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			log.Printf("Unauthorized admin request from %s", getIP(r))
+			if creds.User != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="admin", charset="UTF-8"`)
+			}
+			respondError(w, "Unauthorized", http.StatusUnauthorized)
+		})
+	}
+}
+
+// authorized checks the request's credentials in constant time
+func (c AdminCredentials) authorized(r *http.Request) bool {
+	if c.Token != "" {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			return secureEqual(strings.TrimSpace(token), c.Token)
+		}
+	}
+
+	if c.User != "" && c.Password != "" {
+		if user, password, ok := r.BasicAuth(); ok {
+			// Evaluate both so timing doesn't reveal which one was wrong
+			userOK := secureEqual(user, c.User)
+			passwordOK := secureEqual(password, c.Password)
+			return userOK && passwordOK
+		}
+	}
+
+	return false
+}
+
+// secureEqual compares two secrets without leaking where they differ
+func secureEqual(given, expected string) bool {
+	return subtle.ConstantTimeCompare([]byte(given), []byte(expected)) == 1
+}
+
+// rateLimiterJanitorInterval is how often idle per-IP limiters are evicted
+const rateLimiterJanitorInterval = time.Minute
+
+// defaultMaxLimiters caps the IPs a RateLimiter tracks when not configured
+const defaultMaxLimiters = 10000
+
+// RateLimiter implements per-IP rate limiting using token bucket, keyed by
+// clientKey. IPs are kept in least recently used order: idle ones are
+// evicted by Start and the oldest ones once there are more than maxEntries.
 type RateLimiter struct {
-	mu       sync.Mutex
-	limiters map[string]*rate.Limiter
-	rate     rate.Limit
-	burst    int
+	mu         sync.Mutex
+	limiters   map[string]*list.Element // Values are *limiterEntry
+	lru        *list.List               // Most recently used first
+	rate       rate.Limit
+	burst      int
+	maxEntries int
 }
 
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(requestsPerMinute int) *RateLimiter {
+// limiterEntry is the limiter of one IP
+type limiterEntry struct {
+	ip       string
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimiter creates a new rate limiter tracking up to maxEntries IPs,
+// defaultMaxLimiters if zero
+func NewRateLimiter(requestsPerMinute, maxEntries int) *RateLimiter {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxLimiters
+	}
 	return &RateLimiter{
-		limiters: make(map[string]*rate.Limiter),
-		rate:     rate.Limit(float64(requestsPerMinute) / 60.0), // Convert to per-second rate
-		burst:    requestsPerMinute,
+		limiters:   make(map[string]*list.Element),
+		lru:        list.New(),
+		rate:       rate.Limit(float64(requestsPerMinute) / 60.0), // Convert to per-second rate
+		burst:      requestsPerMinute,
+		maxEntries: maxEntries,
 	}
 }
 
 // getLimiter returns the rate limiter for an IP
-func (rl *RateLimiter) getLimiter(ip string) *rate.Limiter {
+func (rl *RateLimiter) getLimiter(ip string, now time.Time) *rate.Limiter {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	limiter, exists := rl.limiters[ip]
-	if !exists {
-		limiter = rate.NewLimiter(rl.rate, rl.burst)
-		rl.limiters[ip] = limiter
+	if elem, exists := rl.limiters[ip]; exists {
+		entry := elem.Value.(*limiterEntry)
+		entry.lastSeen = now
+		rl.lru.MoveToFront(elem)
+		return entry.limiter
 	}
 
-	return limiter
+	// Evict the least recently used IPs to make room
+	for rl.lru.Len() >= rl.maxEntries {
+		rl.remove(rl.lru.Back())
+	}
+
+	entry := &limiterEntry{ip: ip, limiter: rate.NewLimiter(rl.rate, rl.burst), lastSeen: now}
+	rl.limiters[ip] = rl.lru.PushFront(entry)
+	return entry.limiter
+}
+
+// remove drops an IP's limiter
+func (rl *RateLimiter) remove(elem *list.Element) {
+	rl.lru.Remove(elem)
+	delete(rl.limiters, elem.Value.(*limiterEntry).ip)
+}
+
+// idleAfter is how long an IP must be idle for its bucket to be full again,
+// at which point dropping it is the same as keeping it
+func (rl *RateLimiter) idleAfter() time.Duration {
+	return time.Duration(float64(rl.burst) / float64(rl.rate) * float64(time.Second))
+}
+
+// evictIdle drops the limiters of IPs idle for longer than idleAfter and
+// returns how many were dropped
+func (rl *RateLimiter) evictIdle(now time.Time) int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	cutoff := now.Add(-rl.idleAfter())
+	evicted := 0
+	for elem := rl.lru.Back(); elem != nil; elem = rl.lru.Back() {
+		if elem.Value.(*limiterEntry).lastSeen.After(cutoff) {
+			break // The rest were used more recently
+		}
+		rl.remove(elem)
+		evicted++
+	}
+	return evicted
+}
+
+// Len returns how many IPs are tracked
+func (rl *RateLimiter) Len() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.lru.Len()
+}
+
+// Start evicts idle limiters every interval until ctx is done
+func (rl *RateLimiter) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rl.evictIdle(time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
 }
 
 // Middleware returns the rate limiting middleware
 func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := getIP(r)
-		limiter := rl.getLimiter(ip)
+		limiter := rl.getLimiter(clientKey(r), time.Now())
 
 		if !limiter.Allow() {
 			http.Error(w, `{"error":"Rate limit exceeded"}`, http.StatusTooManyRequests)
@@ -121,30 +264,11 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	})
 }
 
-// getIP extracts the client IP from the request
+// getIP returns the client IP resolved by TrustedProxies.Middleware, or the
+// connection's address if the request didn't go through it
 func getIP(r *http.Request) string {
-	// Try Fly-Client-IP header first (Fly.io specific)
-	if ip := r.Header.Get("Fly-Client-IP"); ip != "" {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
 		return ip
 	}
-
-	// Try X-Forwarded-For
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		ips := strings.Split(xff, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
-		}
-	}
-
-	// Try X-Real-IP
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
-	}
-
-	// Fall back to RemoteAddr
-	ip := r.RemoteAddr
-	if colon := strings.LastIndex(ip, ":"); colon != -1 {
-		ip = ip[:colon]
-	}
-	return ip
+	return remoteIP(r).String()
 }
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterEvictIdle(t *testing.T) {
+	rl := NewRateLimiter(60, 0) // Full again after a minute
+	start := time.Now()
+
+	rl.getLimiter("203.0.113.1", start)
+	rl.getLimiter("203.0.113.2", start.Add(30*time.Second))
+	rl.getLimiter("203.0.113.3", start.Add(50*time.Second))
+
+	if evicted := rl.evictIdle(start.Add(59 * time.Second)); evicted != 0 {
+		t.Errorf("evictIdle before anyone was idle a minute evicted %d", evicted)
+	}
+	if evicted := rl.evictIdle(start.Add(95 * time.Second)); evicted != 2 {
+		t.Errorf("evictIdle evicted %d, want 2", evicted)
+	}
+	if got := rl.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1", got)
+	}
+	if _, ok := rl.limiters["203.0.113.3"]; !ok {
+		t.Errorf("the most recently used IP was evicted")
+	}
+}
+
+func TestRateLimiterEvictIdleKeepsUsedEntries(t *testing.T) {
+	rl := NewRateLimiter(60, 0)
+	start := time.Now()
+
+	rl.getLimiter("203.0.113.1", start)
+	rl.getLimiter("203.0.113.2", start)
+	rl.getLimiter("203.0.113.1", start.Add(50*time.Second)) // Used again
+
+	if evicted := rl.evictIdle(start.Add(61 * time.Second)); evicted != 1 {
+		t.Errorf("evictIdle evicted %d, want 1", evicted)
+	}
+	if _, ok := rl.limiters["203.0.113.1"]; !ok {
+		t.Errorf("an IP used again was evicted")
+	}
+}
+
+func TestRateLimiterMaxEntries(t *testing.T) {
+	rl := NewRateLimiter(60, 2)
+	now := time.Now()
+
+	rl.getLimiter("203.0.113.1", now)
+	rl.getLimiter("203.0.113.2", now)
+	rl.getLimiter("203.0.113.1", now) // Now the most recently used
+	rl.getLimiter("203.0.113.3", now)
+
+	if got := rl.Len(); got != 2 {
+		t.Fatalf("Len() = %d, want 2", got)
+	}
+	if got := len(rl.limiters); got != 2 {
+		t.Fatalf("len(limiters) = %d, want 2", got)
+	}
+	if _, ok := rl.limiters["203.0.113.2"]; ok {
+		t.Errorf("the least recently used IP was kept")
+	}
+	for _, ip := range []string{"203.0.113.1", "203.0.113.3"} {
+		if _, ok := rl.limiters[ip]; !ok {
+			t.Errorf("%s was evicted", ip)
+		}
+	}
+
+	for i := range 100 {
+		rl.getLimiter(fmt.Sprintf("198.51.100.%d", i), now)
+	}
+	if got := rl.Len(); got != 2 {
+		t.Errorf("Len() after many IPs = %d, want 2", got)
+	}
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	rl := NewRateLimiter(2, 0)
+	handler := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	request := func(peer string) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = peer
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	for i, want := range []int{http.StatusNoContent, http.StatusNoContent, http.StatusTooManyRequests} {
+		if got := request("203.0.113.1:4000"); got != want {
+			t.Errorf("request %d = %d, want %d", i+1, got, want)
+		}
+	}
+
+	// Another address in the same IPv6 /64 shares the limit, another /64 doesn't
+	request("[2001:db8:1:2::1]:4000")
+	request("[2001:db8:1:2::2]:4000")
+	if got := request("[2001:db8:1:2::3]:4000"); got != http.StatusTooManyRequests {
+		t.Errorf("request from the same /64 = %d, want %d", got, http.StatusTooManyRequests)
+	}
+	if got := request("[2001:db8:1:3::1]:4000"); got != http.StatusNoContent {
+		t.Errorf("request from another /64 = %d, want %d", got, http.StatusNoContent)
+	}
+}
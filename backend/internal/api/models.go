@@ -1,50 +1,279 @@
 package api
 
 import (
+	"time"
+
 	"github.com/casassg/wedding/backend/internal/store"
 )
 
+// Languages are the supported site languages, Spanish is the default
+var Languages = []string{"es", "en", "ca"}
+
+// MealChoices are the menu options a guest can pick (empty means not chosen yet)
+var MealChoices = []string{"meat", "fish", "vegetarian", "vegan", "kids"}
+
 // InviteResponse is the public API response for GET /invite/{uuid}
 type InviteResponse struct {
-	Name         string `json:"name"`
-	MaxAdults    int    `json:"max_adults"`
-	MaxKids      int    `json:"max_kids"`
-	HasResponded bool   `json:"has_responded"`
-	IsAttending  bool   `json:"is_attending"`
+	Name            string                `json:"name"`
+	MaxAdults       int                   `json:"max_adults"`
+	MaxKids         int                   `json:"max_kids"`
+	HasResponded    bool                  `json:"has_responded"`
+	IsAttending     bool                  `json:"is_attending"`
+	ConfirmedAdults int                   `json:"confirmed_adults"` // Answers of a previous RSVP
+	ConfirmedKids   int                   `json:"confirmed_kids"`
+	DietaryInfo     string                `json:"dietary_info"`
+	MessageForUs    string                `json:"message_for_us"`
+	SongRequest     string                `json:"song_request"`
+	ResponseAt      string                `json:"response_at,omitempty"`   // ISO8601 UTC, empty if not responded
+	Version         string                `json:"version"`                 // Same as the ETag header, send it back as If-Match
+	Guests          []GuestResponse       `json:"guests"`                  // Guests entered in a previous RSVP
+	HasEmail        bool                  `json:"has_email"`               // A previous RSVP left an email, RSVPs without one keep it
+	MealChoices     []string              `json:"meal_choices"`            // Valid values for a guest's meal_choice
+	RSVPDeadline    string                `json:"rsvp_deadline,omitempty"` // ISO8601, empty if there is no deadline
+	CanEdit         bool                  `json:"can_edit"`                // False once the deadline has passed
+	Events          []InviteEventResponse `json:"events"`                  // Other events the invite is invited to, with their answers
+}
+
+// InviteEventResponse is an event besides the wedding an invite is invited to
+type InviteEventResponse struct {
+	Key             string                `json:"key"` // Send it back in the RSVP's events
+	Name            ScheduleEventI18nText `json:"name"`
+	StartTime       string                `json:"start_time,omitempty"` // ISO8601 date or date-time
+	Timezone        string                `json:"timezone,omitempty"`   // IANA timezone of start_time
+	Location        string                `json:"location,omitempty"`
+	MaxAdults       int                   `json:"max_adults"`
+	MaxKids         int                   `json:"max_kids"`
+	HasResponded    bool                  `json:"has_responded"`
+	IsAttending     bool                  `json:"is_attending"`
+	ConfirmedAdults int                   `json:"confirmed_adults"`
+	ConfirmedKids   int                   `json:"confirmed_kids"`
+	ResponseAt      string                `json:"response_at,omitempty"` // ISO8601 UTC, empty if not responded
+}
+
+// GuestResponse is a single guest of an invite
+type GuestResponse struct {
+	Name       string `json:"name"`
+	IsKid      bool   `json:"is_kid"`
+	MealChoice string `json:"meal_choice"`
+	Allergies  string `json:"allergies"`
 }
 
 // RSVPRequest is the request payload for POST /invite/{uuid}/rsvp
 type RSVPRequest struct {
-	AdultCount   int64  `json:"adult_count,omitempty"`
-	KidCount     int64  `json:"kid_count,omitempty"`
-	DietaryInfo  string `json:"dietary_info,omitempty"`
-	MessageForUs string `json:"message_for_us,omitempty"`
-	SongRequest  string `json:"song_request,omitempty"`
+	AdultCount   int64              `json:"adult_count,omitempty"`
+	KidCount     int64              `json:"kid_count,omitempty"`
+	DietaryInfo  string             `json:"dietary_info,omitempty"`
+	MessageForUs string             `json:"message_for_us,omitempty"`
+	SongRequest  string             `json:"song_request,omitempty"`
+	Guests       []GuestRequest     `json:"guests,omitempty"` // Optional, counts are derived from it when present
+	Email        string             `json:"email,omitempty"`  // Optional, receives a confirmation of the RSVP
+	Lang         string             `json:"lang,omitempty"`   // Language of the confirmation: es, en or ca
+	Events       []EventRSVPRequest `json:"events,omitempty"` // Answers for other events, the ones left out are kept
+}
+
+// EventRSVPRequest is the answer for one of the invite's other events
+type EventRSVPRequest struct {
+	Key        string `json:"key"`
+	AdultCount int64  `json:"adult_count"`
+	KidCount   int64  `json:"kid_count"`
+}
+
+// GuestRequest is a single guest in an RSVP request
+type GuestRequest struct {
+	Name       string `json:"name"`
+	IsKid      bool   `json:"is_kid"`
+	MealChoice string `json:"meal_choice,omitempty"`
+	Allergies  string `json:"allergies,omitempty"`
 }
 
 // RSVPResponse is the success response for POST /invite/{uuid}/rsvp
 type RSVPResponse struct {
-	Success bool `json:"success"`
+	Success bool   `json:"success"`
+	Version string `json:"version,omitempty"` // New version of the invite, also sent as the ETag header
+}
+
+// RSVPHistoryResponse is returned by GET /admin/invites/{invite_code}/history
+type RSVPHistoryResponse struct {
+	Events []RSVPEventResponse `json:"events"`
+}
+
+// RSVPEventResponse is a single change in an invite's RSVP timeline
+type RSVPEventResponse struct {
+	Event     string       `json:"event,omitempty"`     // Key of the event answered, empty for the wedding
+	Source    string       `json:"source"`              // guest, sheet or admin
+	ClientIP  string       `json:"client_ip,omitempty"` // Empty for sheet-driven changes
+	Old       RSVPSnapshot `json:"old"`
+	New       RSVPSnapshot `json:"new"`
+	CreatedAt string       `json:"created_at"` // ISO8601 UTC
+}
+
+// RSVPSnapshot holds the RSVP answers before or after a change
+type RSVPSnapshot struct {
+	AdultCount   int64  `json:"adult_count"`
+	KidCount     int64  `json:"kid_count"`
+	DietaryInfo  string `json:"dietary_info"`
+	MessageForUs string `json:"message_for_us"`
+	SongRequest  string `json:"song_request"`
+}
+
+// AdminInviteRequest is the request payload for creating or editing an invite
+// through the admin API. The invite code can't be changed once created.
+type AdminInviteRequest struct {
+	InviteCode string `json:"invite_code"`
+	Name       string `json:"name"`
+	MaxAdults  int64  `json:"max_adults"` // 1 or 2, the sheet only has a partner yes/no column
+	MaxKids    int64  `json:"max_kids"`
+}
+
+// AdminInviteResponse is an invite with all its data, returned by the admin API
+type AdminInviteResponse struct {
+	InviteCode      string                `json:"invite_code"`
+	Name            string                `json:"name"`
+	MaxAdults       int64                 `json:"max_adults"`
+	MaxKids         int64                 `json:"max_kids"`
+	ConfirmedAdults int64                 `json:"confirmed_adults"`
+	ConfirmedKids   int64                 `json:"confirmed_kids"`
+	DietaryInfo     string                `json:"dietary_info"`
+	MessageForUs    string                `json:"message_for_us"`
+	SongRequest     string                `json:"song_request"`
+	Email           string                `json:"email"`
+	Lang            string                `json:"lang"`
+	ResponseAt      string                `json:"response_at,omitempty"`   // ISO8601 UTC, empty if not responded
+	SheetRow        *int64                `json:"sheet_row,omitempty"`     // Empty until a new invite is synced
+	RSVPDeadline    string                `json:"rsvp_deadline,omitempty"` // Per-invite override from the sheet
+	PendingSync     bool                  `json:"pending_sync"`            // Has changes not yet written to the sheet
+	DisabledAt      string                `json:"disabled_at,omitempty"`   // Set when the invite's row was removed from the sheet
+	Guests          []GuestResponse       `json:"guests,omitempty"`        // Only when fetching a single invite
+	Events          []InviteEventResponse `json:"events,omitempty"`        // Only when fetching a single invite
+}
+
+// AdminInviteListResponse is returned by GET /admin/invites
+type AdminInviteListResponse struct {
+	Invites []AdminInviteResponse `json:"invites"`
+}
+
+// StatsResponse is returned by GET /admin/stats
+type StatsResponse struct {
+	Invites                  InviteCounts         `json:"invites"`
+	ResponseRate             float64              `json:"response_rate"` // Responded / total, 0 to 1
+	Adults                   HeadCount            `json:"adults"`
+	Kids                     HeadCount            `json:"kids"`
+	Dietary                  []DietaryCount       `json:"dietary"`
+	MealChoices              []MealChoiceCount    `json:"meal_choices"`
+	ResponsesByLastAnswerDay []DailyResponseRow   `json:"responses_by_last_answer_day"` // See DailyResponseRow
+	Lookups                  *LookupStatsResponse `json:"lookups,omitempty"`            // Failed invite lookups, not part of `server stats`
+}
+
+// LookupStatsResponse shows failed invite code lookups since the server
+// started, with the IPs and code prefixes that look like enumeration. The
+// counters are those of the machine that answered, see LookupGuard.
+type LookupStatsResponse struct {
+	Machine       string                   `json:"machine"` // Hostname, the Fly.io machine ID when deployed
+	FailedLookups int64                    `json:"failed_lookups"`
+	Lockouts      int64                    `json:"lockouts"`
+	IPs           []LookupActivityResponse `json:"ips"`
+	Prefixes      []LookupActivityResponse `json:"prefixes"`
+}
+
+// LookupActivityResponse is an IP or code prefix with recent failed lookups
+type LookupActivityResponse struct {
+	Key         string `json:"key"`
+	Failures    int    `json:"failures"` // In the current window
+	Lockouts    int    `json:"lockouts"`
+	LockedUntil string `json:"locked_until,omitempty"` // RFC 3339, empty if not locked out
+}
+
+// InviteCounts breaks invites down by response status
+type InviteCounts struct {
+	Total     int64 `json:"total"`
+	Responded int64 `json:"responded"`
+	Attending int64 `json:"attending"` // Responded with at least one guest
+	Declined  int64 `json:"declined"`  // Responded with nobody attending
+	Pending   int64 `json:"pending"`   // Not responded yet
+}
+
+// HeadCount compares the maximum invited guests with the confirmed ones
+type HeadCount struct {
+	Invited   int64 `json:"invited"`
+	Confirmed int64 `json:"confirmed"`
+}
+
+// DietaryCount is the number of attending invites and guests sharing the same dietary info
+type DietaryCount struct {
+	DietaryInfo string `json:"dietary_info"`
+	Invites     int64  `json:"invites"`
+	Guests      int64  `json:"guests"`
+}
+
+// MealChoiceCount is the number of guests that picked a meal ("" if not chosen yet)
+type MealChoiceCount struct {
+	MealChoice string `json:"meal_choice"`
+	Guests     int64  `json:"guests"`
+}
+
+// DailyResponseRow holds the invites whose latest answer is on a single UTC
+// day, plus running totals. An invite that changes its answer moves to the
+// day of the change, so the totals end at today's numbers but an earlier
+// day's totals aren't what was known on that day.
+type DailyResponseRow struct {
+	Day             string `json:"day"` // YYYY-MM-DD
+	Responses       int64  `json:"responses"`
+	ConfirmedAdults int64  `json:"confirmed_adults"`
+	ConfirmedKids   int64  `json:"confirmed_kids"`
+	Declines        int64  `json:"declines"`
+	TotalResponses  int64  `json:"total_responses"` // Cumulative up to this day
+	TotalConfirmed  int64  `json:"total_confirmed"` // Cumulative adults + kids up to this day
+}
+
+// SyncStatusResponse is returned by GET /admin/sync/status
+type SyncStatusResponse struct {
+	Status      string            `json:"status"`       // StatusOK or StatusDegraded, as in /health
+	Enabled     bool              `json:"enabled"`      // False when no guest list source is configured
+	LastSuccess *SyncRunResponse  `json:"last_success"` // Null if no sync has succeeded yet
+	Runs        []SyncRunResponse `json:"runs"`         // Newest first
+}
+
+// SyncRunResponse is a single sync cycle
+type SyncRunResponse struct {
+	ID             int64  `json:"id"`
+	StartedAt      string `json:"started_at"` // ISO8601 UTC
+	FinishedAt     string `json:"finished_at"`
+	DurationMs     int64  `json:"duration_ms"`
+	InvitesRead    int64  `json:"invites_read"`    // Sheet to database
+	RSVPsPushed    int64  `json:"rsvps_pushed"`    // Database to sheet
+	RSVPsFailed    int64  `json:"rsvps_failed"`    // Left pending for the next cycle
+	ScheduleEvents int64  `json:"schedule_events"` // Sheet to database
+	Error          string `json:"error,omitempty"`
 }
 
 // ErrorResponse is returned for API errors
 type ErrorResponse struct {
 	Error string `json:"error"`
+	Code  string `json:"code,omitempty"` // Set for errors the frontend handles specially
 }
 
+// Health states reported by /health and the sync status endpoint
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded" // No sync has succeeded for too long
+)
+
 // HealthResponse is returned by /health
 type HealthResponse struct {
-	Status string `json:"status"`
+	Status     string `json:"status"`
+	LastSyncAt string `json:"last_sync_at,omitempty"` // Last successful sync, ISO8601 UTC
 }
 
 // ScheduleEventResponse is a single event in the schedule
 // Returns all language variants so the frontend can pick the right one
 type ScheduleEventResponse struct {
-	StartTime   string                `json:"start_time"`  // ISO8601 format: "2026-12-19T16:00:00-06:00"
-	EndTime     string                `json:"end_time"`    // ISO8601 format or empty
-	Name        ScheduleEventI18nText `json:"name"`        // Event name in all languages
-	Location    string                `json:"location"`    // Event location
-	Description ScheduleEventI18nText `json:"description"` // Event description in all languages
+	StartTime      string                `json:"start_time"`      // ISO8601 format: "2026-12-19T16:00:00-06:00"
+	EndTime        string                `json:"end_time"`        // ISO8601 format or empty
+	Timezone       string                `json:"timezone"`        // IANA timezone of the event: "Europe/Madrid"
+	TimezoneOffset string                `json:"timezone_offset"` // UTC offset at the start time: "+01:00"
+	Name           ScheduleEventI18nText `json:"name"`            // Event name in all languages
+	Location       string                `json:"location"`        // Event location
+	Description    ScheduleEventI18nText `json:"description"`     // Event description in all languages
 }
 
 // ScheduleEventI18nText holds text in all supported languages
@@ -56,21 +285,28 @@ type ScheduleEventI18nText struct {
 
 // ScheduleResponse is returned by GET /api/v1/schedule
 type ScheduleResponse struct {
-	Timezone       string                  `json:"timezone"`        // IANA timezone: "America/Tegucigalpa"
-	TimezoneOffset string                  `json:"timezone_offset"` // UTC offset: "-06:00"
+	Timezone       string                  `json:"timezone"`        // Wedding's IANA timezone: "America/Tegucigalpa"
+	TimezoneOffset string                  `json:"timezone_offset"` // Its UTC offset at the first event: "-06:00"
 	Events         []ScheduleEventResponse `json:"events"`
 }
 
-// ToScheduleEventResponse converts a store.ScheduleEvent to API response
-func ToScheduleEventResponse(event *store.ScheduleEvent) ScheduleEventResponse {
+// ToScheduleEventResponse converts a store.ScheduleEvent in loc to API response
+func ToScheduleEventResponse(event *store.ScheduleEvent, loc *time.Location) ScheduleEventResponse {
 	endTime := ""
 	if event.EndTime != nil {
 		endTime = *event.EndTime
 	}
 
+	offset := ""
+	if start, err := time.Parse(time.RFC3339, event.StartTime); err == nil {
+		offset = start.In(loc).Format("-07:00")
+	}
+
 	return ScheduleEventResponse{
-		StartTime: event.StartTime,
-		EndTime:   endTime,
+		StartTime:      event.StartTime,
+		EndTime:        endTime,
+		Timezone:       loc.String(),
+		TimezoneOffset: offset,
 		Name: ScheduleEventI18nText{
 			ES: event.EventNameEs,
 			EN: event.EventNameEn,
@@ -85,13 +321,228 @@ func ToScheduleEventResponse(event *store.ScheduleEvent) ScheduleEventResponse {
 	}
 }
 
-// ToInviteResponse converts sqlc Invite to API InviteResponse
-func ToInviteResponse(invite *store.Invite) InviteResponse {
-	return InviteResponse{
-		Name:         invite.Name,
-		MaxAdults:    int(invite.MaxAdults),
-		MaxKids:      int(invite.MaxKids),
-		HasResponded: invite.ResponseAt != nil,
-		IsAttending:  invite.ConfirmedAdults > 0,
+// ToInviteResponse converts sqlc Invite, its guests and its other events to API InviteResponse.
+// deadline is the invite's effective RSVP deadline, nil if there is none.
+func ToInviteResponse(invite *store.Invite, guests []*store.Guest, events []*store.InvitedEvent, deadline *time.Time, now time.Time) InviteResponse {
+	response := InviteResponse{
+		Name:            invite.Name,
+		MaxAdults:       int(invite.MaxAdults),
+		MaxKids:         int(invite.MaxKids),
+		HasResponded:    invite.ResponseAt != nil,
+		IsAttending:     invite.ConfirmedAdults > 0,
+		ConfirmedAdults: int(invite.ConfirmedAdults),
+		ConfirmedKids:   int(invite.ConfirmedKids),
+		DietaryInfo:     invite.DietaryInfo,
+		MessageForUs:    invite.MessageForUs,
+		SongRequest:     invite.SongRequest,
+		Version:         store.RSVPVersion(invite, guests, store.Invitations(events)),
+		Guests:          toGuestResponses(guests),
+		HasEmail:        invite.Email != "",
+		MealChoices:     MealChoices,
+		CanEdit:         canEditRSVP(deadline, now),
+		Events:          toInviteEventResponses(events),
+	}
+	if invite.ResponseAt != nil {
+		response.ResponseAt = invite.ResponseAt.UTC().Format(time.RFC3339)
+	}
+	if deadline != nil {
+		response.RSVPDeadline = deadline.Format(time.RFC3339)
+	}
+	return response
+}
+
+// ToAdminInviteResponse converts sqlc Invite and its guests to the admin API response.
+// guests may be nil when listing invites.
+func ToAdminInviteResponse(invite *store.Invite, guests []*store.Guest) AdminInviteResponse {
+	response := AdminInviteResponse{
+		InviteCode:      invite.InviteCode,
+		Name:            invite.Name,
+		MaxAdults:       invite.MaxAdults,
+		MaxKids:         invite.MaxKids,
+		ConfirmedAdults: invite.ConfirmedAdults,
+		ConfirmedKids:   invite.ConfirmedKids,
+		DietaryInfo:     invite.DietaryInfo,
+		MessageForUs:    invite.MessageForUs,
+		SongRequest:     invite.SongRequest,
+		Email:           invite.Email,
+		Lang:            invite.Lang,
+		SheetRow:        invite.SheetRow,
+		PendingSync:     invite.LocalChangedAt != nil || invite.SheetRow == nil,
+	}
+	if invite.ResponseAt != nil {
+		response.ResponseAt = invite.ResponseAt.UTC().Format(time.RFC3339)
+		response.PendingSync = response.PendingSync || invite.ResponseAt.After(invite.UpdatedAt)
+	}
+	if invite.RsvpDeadline != nil {
+		response.RSVPDeadline = invite.RsvpDeadline.Format(time.RFC3339)
+	}
+	if invite.DisabledAt != nil {
+		// Disabled invites are never written to the sheet
+		response.DisabledAt = invite.DisabledAt.UTC().Format(time.RFC3339)
+		response.PendingSync = false
+	}
+	if guests != nil {
+		response.Guests = toGuestResponses(guests)
+	}
+	return response
+}
+
+// toGuestResponses converts sqlc Guests to API GuestResponses
+func toGuestResponses(guests []*store.Guest) []GuestResponse {
+	responses := make([]GuestResponse, 0, len(guests))
+	for _, guest := range guests {
+		responses = append(responses, GuestResponse{
+			Name:       guest.Name,
+			IsKid:      guest.IsKid,
+			MealChoice: guest.MealChoice,
+			Allergies:  guest.Allergies,
+		})
+	}
+	return responses
+}
+
+// ToAdminRSVPHistoryResponse converts sqlc RsvpEvents to the admin API timeline, including client IPs
+func ToAdminRSVPHistoryResponse(events []*store.RsvpEvent) RSVPHistoryResponse {
+	responses := make([]RSVPEventResponse, 0, len(events))
+	for _, event := range events {
+		response := RSVPEventResponse{
+			Event:    event.EventKey,
+			Source:   event.Source,
+			ClientIP: event.ClientIp,
+			Old: RSVPSnapshot{
+				AdultCount:   event.OldConfirmedAdults,
+				KidCount:     event.OldConfirmedKids,
+				DietaryInfo:  event.OldDietaryInfo,
+				MessageForUs: event.OldMessageForUs,
+				SongRequest:  event.OldSongRequest,
+			},
+			New: RSVPSnapshot{
+				AdultCount:   event.NewConfirmedAdults,
+				KidCount:     event.NewConfirmedKids,
+				DietaryInfo:  event.NewDietaryInfo,
+				MessageForUs: event.NewMessageForUs,
+				SongRequest:  event.NewSongRequest,
+			},
+			CreatedAt: event.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		responses = append(responses, response)
+	}
+	return RSVPHistoryResponse{Events: responses}
+}
+
+// ToLookupStatsResponse converts LookupStats to the API response
+func ToLookupStatsResponse(stats *LookupStats) *LookupStatsResponse {
+	return &LookupStatsResponse{
+		Machine:       stats.Machine,
+		FailedLookups: stats.FailedLookups,
+		Lockouts:      stats.Lockouts,
+		IPs:           toLookupActivityResponses(stats.IPs),
+		Prefixes:      toLookupActivityResponses(stats.Prefixes),
+	}
+}
+
+// toLookupActivityResponses converts lookup activity to API responses
+func toLookupActivityResponses(activity []*LookupActivity) []LookupActivityResponse {
+	responses := make([]LookupActivityResponse, 0, len(activity))
+	for _, entry := range activity {
+		response := LookupActivityResponse{
+			Key:      entry.Key,
+			Failures: entry.Failures,
+			Lockouts: entry.Lockouts,
+		}
+		if !entry.LockedUntil.IsZero() {
+			response.LockedUntil = entry.LockedUntil.UTC().Format(time.RFC3339)
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// ToStatsResponse converts store.Stats to the API response
+func ToStatsResponse(stats *store.Stats) StatsResponse {
+	totals := stats.Totals
+	response := StatsResponse{
+		Invites: InviteCounts{
+			Total:     totals.TotalInvites,
+			Responded: totals.RespondedInvites,
+			Attending: totals.AttendingInvites(),
+			Declined:  totals.DeclinedInvites,
+			Pending:   totals.PendingInvites(),
+		},
+		ResponseRate:             totals.ResponseRate(),
+		Adults:                   HeadCount{Invited: totals.InvitedAdults, Confirmed: totals.ConfirmedAdults},
+		Kids:                     HeadCount{Invited: totals.InvitedKids, Confirmed: totals.ConfirmedKids},
+		Dietary:                  make([]DietaryCount, 0, len(stats.Dietary)),
+		MealChoices:              make([]MealChoiceCount, 0, len(stats.MealChoices)),
+		ResponsesByLastAnswerDay: make([]DailyResponseRow, 0, len(stats.ResponsesByLastAnswerDay)),
+	}
+
+	for _, row := range stats.Dietary {
+		response.Dietary = append(response.Dietary, DietaryCount{
+			DietaryInfo: row.DietaryInfo,
+			Invites:     row.Invites,
+			Guests:      row.Guests,
+		})
+	}
+
+	for _, row := range stats.MealChoices {
+		response.MealChoices = append(response.MealChoices, MealChoiceCount{
+			MealChoice: row.MealChoice,
+			Guests:     row.Guests,
+		})
+	}
+
+	var totalResponses, totalConfirmed int64
+	for _, row := range stats.ResponsesByLastAnswerDay {
+		totalResponses += row.Responses
+		totalConfirmed += row.ConfirmedAdults + row.ConfirmedKids
+		response.ResponsesByLastAnswerDay = append(response.ResponsesByLastAnswerDay, DailyResponseRow{
+			Day:             row.Day,
+			Responses:       row.Responses,
+			ConfirmedAdults: row.ConfirmedAdults,
+			ConfirmedKids:   row.ConfirmedKids,
+			Declines:        row.Declines,
+			TotalResponses:  totalResponses,
+			TotalConfirmed:  totalConfirmed,
+		})
+	}
+
+	return response
+}
+
+// ToSyncStatusResponse converts store.SyncStatus to the API response
+func ToSyncStatusResponse(status *store.SyncStatus, enabled, stale bool) SyncStatusResponse {
+	response := SyncStatusResponse{
+		Status:  StatusOK,
+		Enabled: enabled,
+		Runs:    make([]SyncRunResponse, 0, len(status.Runs)),
+	}
+	if stale {
+		response.Status = StatusDegraded
+	}
+
+	if status.LastSuccess != nil {
+		run := ToSyncRunResponse(status.LastSuccess)
+		response.LastSuccess = &run
+	}
+	for _, run := range status.Runs {
+		response.Runs = append(response.Runs, ToSyncRunResponse(run))
+	}
+
+	return response
+}
+
+// ToSyncRunResponse converts a store.SyncRun to the API response
+func ToSyncRunResponse(run *store.SyncRun) SyncRunResponse {
+	return SyncRunResponse{
+		ID:             run.ID,
+		StartedAt:      run.StartedAt.UTC().Format(time.RFC3339),
+		FinishedAt:     run.FinishedAt.UTC().Format(time.RFC3339),
+		DurationMs:     run.DurationMs,
+		InvitesRead:    run.InvitesRead,
+		RSVPsPushed:    run.RsvpsPushed,
+		RSVPsFailed:    run.RsvpsFailed,
+		ScheduleEvents: run.ScheduleEvents,
+		Error:          run.Error,
 	}
 }
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package api
+
+import "os"
+
+// isMountPoint reports whether dir exists, there's no portable way to tell a
+// mount from a plain directory here
+func isMountPoint(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
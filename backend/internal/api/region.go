@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// litefsPrimaryFile is the file LiteFS keeps in its mount on replicas only,
+// holding the hostname of the primary
+const litefsPrimaryFile = ".primary"
+
+// Region tells whether this machine has the writable database. Machines
+// that don't serve reads from a replicated copy and replay writes to the
+// primary. With LiteFS its lease decides, which can move between machines
+// at any time; without it, machines outside the primary region are
+// replicas, which is also how to try it locally.
+type Region struct {
+	Primary   string // Region holding the writable database, empty when not replicated
+	Current   string // Region of this machine, empty when not on Fly.io
+	LiteFSDir string // LiteFS mount holding the database, empty when not using LiteFS
+}
+
+// IsPrimary reports whether this machine has the writable database. Without
+// LiteFS it does unless both regions are set and differ.
+func (r Region) IsPrimary() bool {
+	if r.LiteFSDir != "" {
+		primary, _ := r.litefsPrimary()
+		return primary
+	}
+	return r.Primary == "" || r.Current == "" || r.Current == r.Primary
+}
+
+// litefsPrimary reports whether LiteFS made this machine the primary and,
+// when it didn't, the hostname of the one it did. A .primary file that
+// can't be read counts as a replica with an unknown primary, and so does a
+// missing one when LiteFS isn't mounted, e.g. the server was started by
+// hand: the database there isn't replicated and mustn't be written to.
+func (r Region) litefsPrimary() (bool, string) {
+	data, err := os.ReadFile(filepath.Join(r.LiteFSDir, litefsPrimaryFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return isMountPoint(r.LiteFSDir), ""
+	}
+	if err != nil {
+		return false, ""
+	}
+	return false, strings.TrimSpace(string(data))
+}
+
+// replayTarget returns the fly-replay target of the primary: the machine
+// holding the LiteFS lease, or else the primary region. Empty if unknown.
+func (r Region) replayTarget() string {
+	if r.LiteFSDir != "" {
+		if _, host := r.litefsPrimary(); host != "" {
+			return "instance=" + host
+		}
+	}
+	if r.Primary != "" {
+		return "region=" + r.Primary
+	}
+	return ""
+}
+
+// String describes the region for logging
+func (r Region) String() string {
+	switch {
+	case r.LiteFSDir != "" && r.IsPrimary():
+		return fmt.Sprintf("primary (LiteFS lease in %s)", r.LiteFSDir)
+	case r.LiteFSDir != "" && !isMountPoint(r.LiteFSDir):
+		return fmt.Sprintf("replica (LiteFS not mounted at %s)", r.LiteFSDir)
+	case r.LiteFSDir != "":
+		_, host := r.litefsPrimary()
+		return fmt.Sprintf("replica (LiteFS, primary %s)", host)
+	case r.Primary == "" || r.Current == "":
+		return "primary (single region)"
+	case r.IsPrimary():
+		return fmt.Sprintf("primary (%s)", r.Current)
+	default:
+		return fmt.Sprintf("replica (%s, primary %s)", r.Current, r.Primary)
+	}
+}
+
+// Middleware replays requests to the primary when this machine isn't it.
+// Wraps handlers that write to the database.
+func (r Region) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !r.IsPrimary() {
+			r.replay(w, req)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// replay asks Fly.io's proxy to send the request to the primary instead,
+// through the fly-replay header. A request that was already replayed and
+// still didn't land on the primary fails rather than loop, as does one
+// whose primary isn't known.
+func (r Region) replay(w http.ResponseWriter, req *http.Request) {
+	target := r.replayTarget()
+	if src := req.Header.Get("Fly-Replay-Src"); src != "" || target == "" {
+		log.Printf("Not replaying %s %s to the primary (target %q, replayed from %q)", req.Method, req.URL.Path, target, src)
+		respondErrorCode(w, "primary_unavailable", "Primary unavailable, try again later", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Fly-Replay", target)
+	respondErrorCode(w, "replay", "Replaying request to the primary", http.StatusConflict)
+}
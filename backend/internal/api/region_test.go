@@ -0,0 +1,62 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegionLiteFSPrimary(t *testing.T) {
+	// A plain directory, like the empty /litefs in the image when LiteFS
+	// isn't running
+	unmounted := t.TempDir()
+
+	replica := t.TempDir()
+	if err := os.WriteFile(filepath.Join(replica, litefsPrimaryFile), []byte("e784079b449d68\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		dir        string
+		wantTarget string
+	}{
+		{name: "replica", dir: replica, wantTarget: "instance=e784079b449d68"},
+		{name: "not mounted", dir: unmounted, wantTarget: "region=iad"},
+		{name: "missing mount", dir: filepath.Join(unmounted, "litefs"), wantTarget: "region=iad"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Region{Primary: "iad", Current: "iad", LiteFSDir: tt.dir}
+			if r.IsPrimary() {
+				t.Errorf("IsPrimary() = true, want false")
+			}
+			if got := r.replayTarget(); got != tt.wantTarget {
+				t.Errorf("replayTarget() = %q, want %q", got, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestRegionLiteFSPrimaryMounted(t *testing.T) {
+	// Any mount point without a .primary file stands in for the primary's
+	// LiteFS mount
+	if !isMountPoint("/proc") {
+		t.Skip("/proc isn't a mount point here")
+	}
+	r := Region{Primary: "iad", Current: "iad", LiteFSDir: "/proc"}
+	if !r.IsPrimary() {
+		t.Errorf("IsPrimary() = false with LiteFS mounted and no %s file", litefsPrimaryFile)
+	}
+}
+
+func TestIsMountPoint(t *testing.T) {
+	dir := t.TempDir()
+	if isMountPoint(dir) {
+		t.Errorf("isMountPoint(%s) = true for a temporary directory", dir)
+	}
+	if isMountPoint(filepath.Join(dir, "missing")) {
+		t.Errorf("isMountPoint() = true for a missing directory")
+	}
+}
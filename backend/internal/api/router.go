@@ -1,33 +1,130 @@
 package api
 
 import (
+	"context"
+	"log"
 	"net/http"
+	"time"
 
+	"github.com/casassg/wedding/backend/internal/notify"
 	"github.com/casassg/wedding/backend/internal/sheets"
 	"github.com/casassg/wedding/backend/internal/store"
 )
 
-// NewRouter creates the HTTP router with all routes and middleware
-func NewRouter(database *store.Store, syncer *sheets.Syncer, allowedOrigins []string) http.Handler {
-	handler := NewHandler(database, syncer)
+// Options configures the HTTP router
+type Options struct {
+	AllowedOrigins []string
+	Admin          AdminCredentials  // Admin API is disabled when empty
+	Notifier       *notify.Notifier  // Sends RSVP confirmations, may be unconfigured
+	RSVPDeadline   time.Time         // Default RSVP deadline, zero for none
+	SiteURL        string            // Public site, used for invitation links and QR codes
+	SyncStaleAfter time.Duration     // /health is degraded when no sync succeeded for this long, zero to never
+	Timezone       *time.Location    // Wedding's timezone, for deadlines and events without their own. UTC if nil
+	RateLimits     RateLimits        // Per-IP request limits by route
+	LookupGuard    LookupGuardConfig // Lockouts after failed invite code lookups
+	TrustedProxies *TrustedProxies   // Whose forwarded headers tell the client IP, no one's if nil
+	Region         Region            // Writes are replayed to the primary region from replicas
+}
+
+// RateLimits are the per-IP requests per minute allowed on each kind of
+// route. Zero fields use the defaults of DefaultRateLimits.
+type RateLimits struct {
+	Invite   int // Invite lookups and RSVPs, strict since invite codes are the only credential
+	Schedule int // Schedule and its calendar feed, polled by the site and calendar apps
+	Default  int // Everything else, like /health and the admin API
+	MaxIPs   int // IPs tracked by each limiter, least recently seen evicted first
+}
 
-	// Create rate limiter (10 requests per minute)
-	rateLimiter := NewRateLimiter(10)
+// DefaultRateLimits returns the default per-IP limits
+func DefaultRateLimits() RateLimits {
+	return RateLimits{Invite: 10, Schedule: 120, Default: 60, MaxIPs: defaultMaxLimiters}
+}
+
+// withDefaults fills zero limits with the defaults
+func (l RateLimits) withDefaults() RateLimits {
+	defaults := DefaultRateLimits()
+	if l.Invite <= 0 {
+		l.Invite = defaults.Invite
+	}
+	if l.Schedule <= 0 {
+		l.Schedule = defaults.Schedule
+	}
+	if l.Default <= 0 {
+		l.Default = defaults.Default
+	}
+	if l.MaxIPs <= 0 {
+		l.MaxIPs = defaults.MaxIPs
+	}
+	return l
+}
+
+// NewRouter creates the HTTP router with all routes and middleware. Idle
+// rate limiter entries are evicted in the background until ctx is done.
+func NewRouter(ctx context.Context, database *store.Store, syncer *sheets.Syncer, opts Options) http.Handler {
+	handler := NewHandler(database, syncer, opts)
+
+	// Create rate limiters, per IP and kind of route
+	limits := opts.RateLimits.withDefaults()
+	inviteLimiter := NewRateLimiter(limits.Invite, limits.MaxIPs)
+	scheduleLimiter := NewRateLimiter(limits.Schedule, limits.MaxIPs)
+	defaultLimiter := NewRateLimiter(limits.Default, limits.MaxIPs)
+	for _, limiter := range []*RateLimiter{inviteLimiter, scheduleLimiter, defaultLimiter} {
+		go limiter.Start(ctx, rateLimiterJanitorInterval)
+	}
+
+	trusted := opts.TrustedProxies
+	if trusted == nil {
+		trusted = &TrustedProxies{}
+	}
+	log.Printf("Trusted proxies: %s", trusted)
+
+	// Invite routes also go through the lookup guard, after the rate limit.
+	// Writes are replayed to the primary before either, which applies them.
+	invite := func(h http.HandlerFunc) http.Handler {
+		return Chain(h, inviteLimiter.Middleware, handler.guard.Middleware)
+	}
+	inviteWrite := func(h http.HandlerFunc) http.Handler {
+		return Chain(invite(h), opts.Region.Middleware)
+	}
+	schedule := func(h http.HandlerFunc) http.Handler {
+		return Chain(h, scheduleLimiter.Middleware)
+	}
 
 	// Create mux
 	mux := http.NewServeMux()
 
 	// Register routes
-	mux.HandleFunc("/health", handler.Health)
-	mux.HandleFunc("GET /api/v1/invite/{invite_code}/", handler.GetInvite)
-	mux.HandleFunc("POST /api/v1/invite/{invite_code}/rsvp", handler.PostRSVP)
-	mux.HandleFunc("GET /api/v1/schedule", handler.GetSchedule)
+	mux.Handle("/health", Chain(http.HandlerFunc(handler.Health), defaultLimiter.Middleware))
+	mux.Handle("GET /api/v1/invite/{invite_code}/", invite(handler.GetInvite))
+	mux.Handle("POST /api/v1/invite/{invite_code}/rsvp", inviteWrite(handler.PostRSVP))
+	mux.Handle("GET /api/v1/schedule", schedule(handler.GetSchedule))
+	mux.Handle("GET /api/v1/schedule.ics", schedule(handler.GetScheduleICS))
+
+	// Admin routes, all behind admin authentication and served by the
+	// primary, which also has the sync status and lookup stats
+	if opts.Admin.Enabled() {
+		adminMux := http.NewServeMux()
+		adminMux.HandleFunc("GET /api/v1/admin/invites", handler.AdminListInvites)
+		adminMux.HandleFunc("POST /api/v1/admin/invites", handler.AdminCreateInvite)
+		adminMux.HandleFunc("GET /api/v1/admin/invites/{invite_code}", handler.AdminGetInvite)
+		adminMux.HandleFunc("PUT /api/v1/admin/invites/{invite_code}", handler.AdminUpdateInvite)
+		adminMux.HandleFunc("DELETE /api/v1/admin/invites/{invite_code}", handler.AdminDeleteInvite)
+		adminMux.HandleFunc("POST /api/v1/admin/invites/{invite_code}/rsvp", handler.AdminPostRSVP)
+		adminMux.HandleFunc("GET /api/v1/admin/invites/{invite_code}/history", handler.AdminGetInviteHistory)
+		adminMux.HandleFunc("GET /api/v1/admin/invites/{invite_code}/qr", handler.AdminGetInviteQR)
+		adminMux.HandleFunc("GET /api/v1/admin/qr.zip", handler.AdminGetQRZip)
+		adminMux.HandleFunc("GET /api/v1/admin/stats", handler.AdminGetStats)
+		adminMux.HandleFunc("GET /api/v1/admin/sync/status", handler.AdminGetSyncStatus)
+		mux.Handle("/api/v1/admin/", Chain(adminMux, opts.Region.Middleware, defaultLimiter.Middleware, AdminAuth(opts.Admin)))
+	} else {
+		log.Println("Admin API disabled (ADMIN_TOKEN or ADMIN_USER/ADMIN_PASSWORD not set)")
+	}
 
 	// Apply middleware chain
 	return Chain(
 		mux,
+		trusted.Middleware,
 		Logging,
-		CORS(allowedOrigins),
-		rateLimiter.Middleware,
+		CORS(opts.AllowedOrigins),
 	)
 }
@@ -0,0 +1,219 @@
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/store"
+)
+
+// Email kinds stored in email_outbox
+const (
+	KindConfirmation = "confirmation"
+	KindDigest       = "digest"
+)
+
+const (
+	// dispatchBatchSize is the number of due messages sent per tick
+	dispatchBatchSize = 20
+
+	// maxAttempts is how many times a message is tried before giving up
+	maxAttempts = 8
+
+	// Failed sends are retried after 1m, 2m, 4m... capped at maxBackoff
+	baseBackoff = time.Minute
+	maxBackoff  = 6 * time.Hour
+)
+
+// Config configures the notifier
+type Config struct {
+	AdminEmail     string        // Digest recipient, digests are disabled when empty
+	DigestInterval time.Duration // Minimum time between digests
+}
+
+// Notifier queues notification emails in the database and delivers them in
+// the background, so sending never blocks or fails an RSVP.
+type Notifier struct {
+	store  *store.Store
+	mailer Mailer
+	config Config
+}
+
+// NewNotifier creates a notifier. A nil mailer disables notifications.
+func NewNotifier(s *store.Store, mailer Mailer, config Config) *Notifier {
+	return &Notifier{
+		store:  s,
+		mailer: mailer,
+		config: config,
+	}
+}
+
+// IsConfigured returns whether a mailer is configured
+func (n *Notifier) IsConfigured() bool {
+	return n != nil && n.mailer != nil
+}
+
+// QueueRSVPConfirmation queues a localized summary of an invite's RSVP,
+// including its answers for the events it's invited to, to the email the
+// guest left. Does nothing if there is no email or no mailer.
+func (n *Notifier) QueueRSVPConfirmation(ctx context.Context, invite *store.Invite, guests []*store.Guest, events []*store.InvitedEvent) error {
+	if !n.IsConfigured() || invite.Email == "" {
+		return nil
+	}
+
+	subject, body, err := renderConfirmation(invite, guests, events)
+	if err != nil {
+		return err
+	}
+
+	return n.store.EnqueueEmail(ctx, &store.EnqueueEmailParams{
+		Kind:       KindConfirmation,
+		InviteCode: invite.InviteCode,
+		Recipient:  invite.Email,
+		Subject:    subject,
+		Body:       body,
+	})
+}
+
+// Start delivers queued messages and digests until ctx is cancelled
+func (n *Notifier) Start(ctx context.Context, interval time.Duration) {
+	if !n.IsConfigured() {
+		log.Println("Email notifications disabled (no mailer configured)")
+		return
+	}
+
+	log.Printf("Starting email dispatcher every %s", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n.config.AdminEmail != "" {
+			if err := n.queueDigest(ctx); err != nil {
+				log.Printf("Error queueing digest: %v", err)
+			}
+		}
+		if err := n.DispatchOnce(ctx); err != nil {
+			log.Printf("Error dispatching emails: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Println("Stopping email dispatcher")
+			return
+		}
+	}
+}
+
+// DispatchOnce sends the messages that are due. Failures are recorded on
+// the message and retried later with exponential backoff.
+func (n *Notifier) DispatchOnce(ctx context.Context) error {
+	messages, err := n.store.ListDueEmails(ctx, &store.ListDueEmailsParams{
+		MaxAttempts: maxAttempts,
+		Limit:       dispatchBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list due emails: %w", err)
+	}
+
+	for _, msg := range messages {
+		sendCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		err := n.mailer.Send(sendCtx, &Message{
+			ID:      msg.ID,
+			To:      msg.Recipient,
+			Subject: msg.Subject,
+			Body:    msg.Body,
+		})
+		cancel()
+
+		if err != nil {
+			backoff := retryBackoff(msg.Attempts)
+			if msg.Attempts+1 >= maxAttempts {
+				log.Printf("Giving up on %s email %d to %s after %d attempts: %v", msg.Kind, msg.ID, msg.Recipient, maxAttempts, err)
+			} else {
+				log.Printf("Failed to send %s email %d to %s (attempt %d, retrying in %s): %v",
+					msg.Kind, msg.ID, msg.Recipient, msg.Attempts+1, backoff, err)
+			}
+			if err := n.store.MarkEmailFailed(ctx, &store.MarkEmailFailedParams{
+				LastError:      err.Error(),
+				BackoffSeconds: int64(backoff / time.Second),
+				ID:             msg.ID,
+			}); err != nil {
+				log.Printf("Failed to record email %d failure: %v", msg.ID, err)
+			}
+			continue
+		}
+
+		log.Printf("Sent %s email %d to %s", msg.Kind, msg.ID, msg.Recipient)
+		if err := n.store.MarkEmailSent(ctx, msg.ID); err != nil {
+			log.Printf("Failed to mark email %d as sent: %v", msg.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// queueDigest queues a summary of the RSVP changes since the last digest,
+// at most once per digest interval and only when something changed
+func (n *Notifier) queueDigest(ctx context.Context) error {
+	since, err := n.store.GetLastDigestAt(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		since = time.Now().Add(-n.config.DigestInterval) // First digest
+	} else if err != nil {
+		return fmt.Errorf("failed to load last digest: %w", err)
+	}
+
+	if time.Since(since) < n.config.DigestInterval {
+		return nil
+	}
+
+	events, err := n.store.ListRSVPEventsSince(ctx, since)
+	if err != nil {
+		return fmt.Errorf("failed to list RSVP events: %w", err)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	names := make(map[string]string, len(events))
+	for _, event := range events {
+		if _, ok := names[event.InviteCode]; ok {
+			continue
+		}
+		invite, err := n.store.GetInviteByInviteCode(ctx, event.InviteCode)
+		if err == nil {
+			names[event.InviteCode] = invite.Name
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to load invite %s: %w", event.InviteCode, err)
+		}
+	}
+
+	stats, err := n.store.GetStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	subject, body := renderDigest(events, names, stats, since)
+	log.Printf("Queueing RSVP digest with %d change(s) for %s", len(events), n.config.AdminEmail)
+
+	return n.store.EnqueueEmail(ctx, &store.EnqueueEmailParams{
+		Kind:      KindDigest,
+		Recipient: n.config.AdminEmail,
+		Subject:   subject,
+		Body:      body,
+	})
+}
+
+// retryBackoff returns the delay before retrying a message that failed attempts+1 times
+func retryBackoff(attempts int64) time.Duration {
+	backoff := baseBackoff
+	for i := int64(0); i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
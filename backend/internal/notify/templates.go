@@ -0,0 +1,212 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/store"
+)
+
+// confirmationData is passed to the confirmation templates
+type confirmationData struct {
+	Name         string
+	Attending    bool
+	Adults       int64
+	Kids         int64
+	Guests       []*store.Guest
+	DietaryInfo  string
+	SongRequest  string
+	MessageForUs string
+	Events       []eventAnswerData
+}
+
+// eventAnswerData is an answer for another event in the confirmation
+type eventAnswerData struct {
+	Name      string // In the invite's language
+	Attending bool
+	Adults    int64
+	Kids      int64
+}
+
+// confirmationSubjects are the confirmation email subjects by language
+var confirmationSubjects = map[string]string{
+	"es": "Hemos recibido tu respuesta - Laura & Gerard",
+	"en": "We got your RSVP - Laura & Gerard",
+	"ca": "Hem rebut la teva resposta - Laura & Gerard",
+}
+
+// confirmationTemplates are the confirmation email bodies by language
+var confirmationTemplates = map[string]*template.Template{
+	"es": template.Must(template.New("es").Parse(`Hola {{.Name}},
+
+¡Gracias por responder! Esto es lo que hemos recibido:
+{{if .Attending}}
+Adultos: {{.Adults}}
+Niños: {{.Kids}}
+{{- range .Guests}}
+  - {{.Name}}{{if .IsKid}} (niño/a){{end}}{{if .MealChoice}}, menú: {{.MealChoice}}{{end}}{{if .Allergies}}, alergias: {{.Allergies}}{{end}}
+{{- end}}
+{{- if .DietaryInfo}}
+Necesidades alimentarias: {{.DietaryInfo}}{{end}}
+{{- if .SongRequest}}
+Canción: {{.SongRequest}}{{end}}
+{{else}}
+Sentimos mucho que no podáis venir, ¡os echaremos de menos!
+{{end}}
+{{- if .Events}}
+Otros eventos:
+{{- range .Events}}
+  - {{.Name}}: {{if .Attending}}{{.Adults}} adultos, {{.Kids}} niños{{else}}no podéis venir{{end}}
+{{- end}}
+{{end}}
+{{- if .MessageForUs}}
+Tu mensaje: {{.MessageForUs}}
+{{end}}
+Si algo cambia, puedes modificar tu respuesta con el mismo enlace de la invitación.
+
+Laura & Gerard
+`)),
+	"en": template.Must(template.New("en").Parse(`Hi {{.Name}},
+
+Thank you for your RSVP! This is what we received:
+{{if .Attending}}
+Adults: {{.Adults}}
+Kids: {{.Kids}}
+{{- range .Guests}}
+  - {{.Name}}{{if .IsKid}} (kid){{end}}{{if .MealChoice}}, meal: {{.MealChoice}}{{end}}{{if .Allergies}}, allergies: {{.Allergies}}{{end}}
+{{- end}}
+{{- if .DietaryInfo}}
+Dietary needs: {{.DietaryInfo}}{{end}}
+{{- if .SongRequest}}
+Song request: {{.SongRequest}}{{end}}
+{{else}}
+We're sorry you can't make it, we'll miss you!
+{{end}}
+{{- if .Events}}
+Other events:
+{{- range .Events}}
+  - {{.Name}}: {{if .Attending}}{{.Adults}} adults, {{.Kids}} kids{{else}}not coming{{end}}
+{{- end}}
+{{end}}
+{{- if .MessageForUs}}
+Your message: {{.MessageForUs}}
+{{end}}
+If anything changes, you can update your answer with the same invitation link.
+
+Laura & Gerard
+`)),
+	"ca": template.Must(template.New("ca").Parse(`Hola {{.Name}},
+
+Gràcies per respondre! Això és el que hem rebut:
+{{if .Attending}}
+Adults: {{.Adults}}
+Nens: {{.Kids}}
+{{- range .Guests}}
+  - {{.Name}}{{if .IsKid}} (nen/a){{end}}{{if .MealChoice}}, menú: {{.MealChoice}}{{end}}{{if .Allergies}}, al·lèrgies: {{.Allergies}}{{end}}
+{{- end}}
+{{- if .DietaryInfo}}
+Necessitats alimentàries: {{.DietaryInfo}}{{end}}
+{{- if .SongRequest}}
+Cançó: {{.SongRequest}}{{end}}
+{{else}}
+Sentim molt que no pugueu venir, us trobarem a faltar!
+{{end}}
+{{- if .Events}}
+Altres esdeveniments:
+{{- range .Events}}
+  - {{.Name}}: {{if .Attending}}{{.Adults}} adults, {{.Kids}} nens{{else}}no podeu venir{{end}}
+{{- end}}
+{{end}}
+{{- if .MessageForUs}}
+El teu missatge: {{.MessageForUs}}
+{{end}}
+Si alguna cosa canvia, pots modificar la resposta amb el mateix enllaç de la invitació.
+
+Laura & Gerard
+`)),
+}
+
+// renderConfirmation renders the RSVP confirmation in the invite's language,
+// Spanish by default. Events lists the answered ones besides the wedding.
+func renderConfirmation(invite *store.Invite, guests []*store.Guest, events []*store.InvitedEvent) (subject, body string, err error) {
+	lang := invite.Lang
+	tmpl, ok := confirmationTemplates[lang]
+	if !ok {
+		lang = "es"
+		tmpl = confirmationTemplates[lang]
+	}
+
+	data := confirmationData{
+		Name:         invite.Name,
+		Attending:    invite.ConfirmedAdults+invite.ConfirmedKids > 0,
+		Adults:       invite.ConfirmedAdults,
+		Kids:         invite.ConfirmedKids,
+		Guests:       guests,
+		DietaryInfo:  invite.DietaryInfo,
+		SongRequest:  invite.SongRequest,
+		MessageForUs: invite.MessageForUs,
+	}
+	for _, invited := range events {
+		event, invitation := invited.Event, invited.Invitation
+		if invitation.ResponseAt == nil {
+			continue
+		}
+		name := map[string]string{"es": event.NameEs, "en": event.NameEn, "ca": event.NameCa}[lang]
+		if name == "" {
+			name = event.NameEs
+		}
+		data.Events = append(data.Events, eventAnswerData{
+			Name:      name,
+			Attending: invitation.ConfirmedAdults+invitation.ConfirmedKids > 0,
+			Adults:    invitation.ConfirmedAdults,
+			Kids:      invitation.ConfirmedKids,
+		})
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", "", fmt.Errorf("failed to render confirmation: %w", err)
+	}
+	return confirmationSubjects[lang], b.String(), nil
+}
+
+// renderDigest renders the summary of RSVP changes sent to the couple.
+// names maps invite codes to invite names for the events' invites.
+func renderDigest(events []*store.RsvpEvent, names map[string]string, stats *store.Stats, since time.Time) (subject, body string) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d RSVP change(s) since %s UTC\n\n", len(events), since.UTC().Format("2006-01-02 15:04"))
+
+	for _, event := range events {
+		name := names[event.InviteCode]
+		if name == "" {
+			name = "(deleted invite)"
+		}
+		if event.EventKey != "" {
+			name += ", event " + event.EventKey
+		}
+		fmt.Fprintf(&b, "- %s (%s) via %s at %s: adults %d -> %d, kids %d -> %d\n",
+			name, event.InviteCode, event.Source, event.CreatedAt.UTC().Format("2006-01-02 15:04"),
+			event.OldConfirmedAdults, event.NewConfirmedAdults,
+			event.OldConfirmedKids, event.NewConfirmedKids,
+		)
+		if event.NewDietaryInfo != event.OldDietaryInfo && event.NewDietaryInfo != "" {
+			fmt.Fprintf(&b, "    dietary: %s\n", event.NewDietaryInfo)
+		}
+		if event.NewSongRequest != event.OldSongRequest && event.NewSongRequest != "" {
+			fmt.Fprintf(&b, "    song: %s\n", event.NewSongRequest)
+		}
+		if event.NewMessageForUs != event.OldMessageForUs && event.NewMessageForUs != "" {
+			fmt.Fprintf(&b, "    message: %s\n", event.NewMessageForUs)
+		}
+	}
+
+	totals := stats.Totals
+	fmt.Fprintf(&b, "\nTotals: %d of %d invites responded (%d attending, %d declined, %d pending)\n",
+		totals.RespondedInvites, totals.TotalInvites, totals.AttendingInvites(), totals.DeclinedInvites, totals.PendingInvites())
+	fmt.Fprintf(&b, "Headcount: %d adults of %d invited, %d kids of %d invited\n",
+		totals.ConfirmedAdults, totals.InvitedAdults, totals.ConfirmedKids, totals.InvitedKids)
+
+	return fmt.Sprintf("RSVP digest: %d new change(s)", len(events)), b.String()
+}
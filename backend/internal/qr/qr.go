@@ -0,0 +1,148 @@
+// Package qr renders printable invitation links and QR codes.
+package qr
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/store"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Image formats
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// DefaultSize is the PNG width and height in pixels, about 5cm at 300 DPI
+const DefaultSize = 600
+
+// Options configures the rendered links and images
+type Options struct {
+	SiteURL string // e.g. https://lauraygerard.wedding
+	Lang    string // Language prefix for the link, empty to let the site pick
+	Format  string // FormatPNG or FormatSVG
+	Size    int    // PNG size in pixels, SVGs scale freely
+}
+
+// Link returns an invite's URL. English is the site's default language and
+// has no prefix; other languages live under /es/ and /ca/.
+func Link(siteURL, lang, inviteCode string) string {
+	path := "/"
+	if lang != "" && lang != "en" {
+		path = "/" + lang + "/"
+	}
+	return strings.TrimRight(siteURL, "/") + path + "?code=" + url.QueryEscape(inviteCode)
+}
+
+// Render returns the QR code of content as a PNG or SVG image
+func Render(content string, opts Options) ([]byte, error) {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	switch opts.Format {
+	case FormatSVG:
+		return svg(code.Bitmap()), nil
+	case FormatPNG, "":
+		size := opts.Size
+		if size <= 0 {
+			size = DefaultSize
+		}
+		return code.PNG(size)
+	default:
+		return nil, fmt.Errorf("unsupported format %q, expected png or svg", opts.Format)
+	}
+}
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) string {
+	if format == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// svg draws a QR bitmap (quiet zone included) as one path of unit squares
+func svg(bitmap [][]bool) []byte {
+	var b strings.Builder
+	n := len(bitmap)
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, n, n)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, n, n)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return []byte(b.String())
+}
+
+// WriteZIP writes a ZIP with one QR image per invite plus manifest.csv,
+// which lists each invite's name, limits, link and image file for the printer.
+func WriteZIP(w io.Writer, invites []*store.Invite, opts Options) error {
+	if opts.Format == "" {
+		opts.Format = FormatPNG
+	}
+
+	zw := zip.NewWriter(w)
+	create := func(name string) (io.Writer, error) {
+		return zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	}
+	manifest := [][]string{{"invite_code", "name", "max_adults", "max_kids", "url", "file"}}
+
+	for _, invite := range invites {
+		link := Link(opts.SiteURL, opts.Lang, invite.InviteCode)
+		image, err := Render(link, opts)
+		if err != nil {
+			return fmt.Errorf("invite %s: %w", invite.InviteCode, err)
+		}
+
+		file := "qr/" + inviteFileName(invite.InviteCode) + "." + opts.Format
+		f, err := create(file)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(image); err != nil {
+			return err
+		}
+
+		manifest = append(manifest, []string{
+			invite.InviteCode,
+			invite.Name,
+			fmt.Sprint(invite.MaxAdults),
+			fmt.Sprint(invite.MaxKids),
+			link,
+			file,
+		})
+	}
+
+	f, err := create("manifest.csv")
+	if err != nil {
+		return err
+	}
+	if err := csv.NewWriter(f).WriteAll(manifest); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// inviteFileName makes an invite code safe to use as a file name
+func inviteFileName(inviteCode string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, inviteCode)
+}
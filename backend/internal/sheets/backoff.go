@@ -0,0 +1,77 @@
+package sheets
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// Retry settings for Sheets API calls. Quota errors (429) are retried after
+// 1s, 2s, 4s... plus jitter, or after the server's Retry-After when it sends one.
+const (
+	maxAttempts    = 5
+	baseRetryDelay = time.Second
+	maxRetryDelay  = 30 * time.Second
+)
+
+// withBackoff calls fn until it succeeds, fails with an error that isn't
+// worth retrying, or maxAttempts is reached
+func withBackoff(ctx context.Context, fn func() error) error {
+	backoff := baseRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		wait, retry := retryDelay(err, backoff, time.Now())
+		if !retry || attempt == maxAttempts {
+			return err
+		}
+
+		log.Printf("Sheets API error, retrying in %s (attempt %d/%d): %v", wait.Round(time.Millisecond), attempt, maxAttempts, err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff = min(backoff*2, maxRetryDelay)
+	}
+}
+
+// retryDelay reports whether err is a quota or transient server error and
+// how long to wait before retrying it
+func retryDelay(err error, backoff time.Duration, now time.Time) (time.Duration, bool) {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return 0, false
+	}
+
+	switch apiErr.Code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+
+	if wait, ok := parseRetryAfter(apiErr.Header.Get("Retry-After"), now); ok {
+		return min(wait, maxRetryDelay), true
+	}
+	return backoff + rand.N(backoff/2+1), true
+}
+
+// parseRetryAfter parses a Retry-After header, either seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
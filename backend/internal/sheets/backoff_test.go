@@ -0,0 +1,136 @@
+package sheets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// apiError returns a Sheets API error with the given status and Retry-After
+func apiError(code int, retryAfter string) error {
+	err := &googleapi.Error{Code: code, Header: http.Header{}}
+	if retryAfter != "" {
+		err.Header.Set("Retry-After", retryAfter)
+	}
+	return err
+}
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	backoff := 2 * time.Second
+
+	tests := []struct {
+		name      string
+		err       error
+		wantRetry bool
+		min, max  time.Duration // Jitter makes the backoff a range
+	}{
+		{name: "quota without Retry-After", err: apiError(http.StatusTooManyRequests, ""), wantRetry: true, min: backoff, max: backoff + backoff/2},
+		{name: "Retry-After in seconds", err: apiError(http.StatusTooManyRequests, "7"), wantRetry: true, min: 7 * time.Second, max: 7 * time.Second},
+		{name: "Retry-After of zero", err: apiError(http.StatusTooManyRequests, "0"), wantRetry: true},
+		{
+			name:      "Retry-After as an HTTP date",
+			err:       apiError(http.StatusTooManyRequests, now.Add(12*time.Second).Format(http.TimeFormat)),
+			wantRetry: true,
+			min:       12 * time.Second,
+			max:       12 * time.Second,
+		},
+		{
+			name:      "Retry-After date in the past",
+			err:       apiError(http.StatusTooManyRequests, now.Add(-time.Minute).Format(http.TimeFormat)),
+			wantRetry: true,
+		},
+		{name: "Retry-After capped", err: apiError(http.StatusTooManyRequests, "3600"), wantRetry: true, min: maxRetryDelay, max: maxRetryDelay},
+		{
+			name:      "Retry-After date capped",
+			err:       apiError(http.StatusTooManyRequests, now.Add(time.Hour).Format(http.TimeFormat)),
+			wantRetry: true,
+			min:       maxRetryDelay,
+			max:       maxRetryDelay,
+		},
+		{name: "unparsable Retry-After", err: apiError(http.StatusTooManyRequests, "soon"), wantRetry: true, min: backoff, max: backoff + backoff/2},
+		{name: "negative Retry-After", err: apiError(http.StatusTooManyRequests, "-5"), wantRetry: true, min: backoff, max: backoff + backoff/2},
+		{name: "server error", err: apiError(http.StatusServiceUnavailable, "3"), wantRetry: true, min: 3 * time.Second, max: 3 * time.Second},
+		{name: "bad gateway", err: apiError(http.StatusBadGateway, ""), wantRetry: true, min: backoff, max: backoff + backoff/2},
+		{name: "wrapped", err: fmt.Errorf("read: %w", apiError(http.StatusTooManyRequests, "1")), wantRetry: true, min: time.Second, max: time.Second},
+		{name: "bad request", err: apiError(http.StatusBadRequest, "1")},
+		{name: "forbidden", err: apiError(http.StatusForbidden, "")},
+		{name: "not found", err: apiError(http.StatusNotFound, "")},
+		{name: "not implemented", err: apiError(http.StatusNotImplemented, "")},
+		{name: "not an API error", err: errors.New("connection reset")},
+		{name: "success", err: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, retry := retryDelay(tt.err, backoff, now)
+			if retry != tt.wantRetry {
+				t.Fatalf("retryDelay() retry = %v, want %v", retry, tt.wantRetry)
+			}
+			if wait < tt.min || wait > tt.max {
+				t.Errorf("retryDelay() = %s, want between %s and %s", wait, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestWithBackoff(t *testing.T) {
+	tests := []struct {
+		name         string
+		errs         []error // Returned by each attempt, the last one from then on
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "success", errs: []error{nil}, wantAttempts: 1},
+		{
+			name:         "quota error then success",
+			errs:         []error{apiError(http.StatusTooManyRequests, "0"), apiError(http.StatusInternalServerError, "0"), nil},
+			wantAttempts: 3,
+		},
+		{name: "quota error every time", errs: []error{apiError(http.StatusTooManyRequests, "0")}, wantErr: apiError(http.StatusTooManyRequests, "0"), wantAttempts: maxAttempts},
+		{name: "not found", errs: []error{apiError(http.StatusNotFound, "0")}, wantErr: apiError(http.StatusNotFound, "0"), wantAttempts: 1},
+		{name: "other error", errs: []error{errors.New("boom")}, wantErr: errors.New("boom"), wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := withBackoff(context.Background(), func() error {
+				err := tt.errs[min(attempts, len(tt.errs)-1)]
+				attempts++
+				return err
+			})
+			if fmt.Sprint(err) != fmt.Sprint(tt.wantErr) {
+				t.Errorf("withBackoff() = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("%d attempts, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestWithBackoffStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	start := time.Now()
+	err := withBackoff(ctx, func() error {
+		attempts++
+		cancel() // Gone while the first call was running
+		return apiError(http.StatusTooManyRequests, "20")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("withBackoff() = %v, want %v", err, context.Canceled)
+	}
+	if attempts != 1 {
+		t.Errorf("%d attempts, want 1", attempts)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("withBackoff() waited %s after the context was cancelled", elapsed)
+	}
+}
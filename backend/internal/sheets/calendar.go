@@ -0,0 +1,136 @@
+package sheets
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // The runtime image has no zoneinfo, embed it for LoadLocation
+)
+
+// Calendar is how the guest list's dates and times are read when they don't
+// say it themselves: the year of day headers like "Friday Dec 18" and the
+// timezone of schedule times and date-only RSVP deadlines.
+type Calendar struct {
+	Year     int
+	Location *time.Location
+}
+
+// NewCalendar creates a calendar for a wedding year and IANA timezone
+func NewCalendar(year int, timezone string) (*Calendar, error) {
+	if year < 1 || year > 9999 {
+		return nil, fmt.Errorf("invalid year %d", year)
+	}
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	return &Calendar{Year: year, Location: loc}, nil
+}
+
+// loadLocation loads an IANA timezone such as "Europe/Madrid"
+func loadLocation(name string) (*time.Location, error) {
+	// LoadLocation reads "" as UTC and "Local" as the server's zone, neither
+	// of which is what a guest list means
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("invalid timezone %q, expected an IANA name like Europe/Madrid", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q, expected an IANA name like Europe/Madrid: %w", name, err)
+	}
+	return loc, nil
+}
+
+// deadlineDateFormats are the date-only deadline formats, read as the end of that day
+var deadlineDateFormats = []string{"2006-01-02", "02/01/2006", "2/1/2006"}
+
+// ParseDeadline parses an RSVP deadline. A date without a time ("2026-11-15"
+// or "15/11/2026") means the end of that day in the calendar's timezone; an
+// RFC 3339 timestamp is used as is.
+func (c *Calendar) ParseDeadline(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range deadlineDateFormats {
+		if day, err := time.ParseInLocation(layout, s, c.Location); err == nil {
+			return day.AddDate(0, 0, 1).Add(-time.Second), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid deadline %q, expected YYYY-MM-DD, DD/MM/YYYY or RFC 3339", s)
+}
+
+// Day header formats in the Schedule sheet. The weekday is optional for ISO
+// dates, and the year is optional for month names:
+// "Friday Dec 18", "Friday Dec 18, 2026", "Friday 2026-12-18" or "2026-12-18"
+var (
+	dayHeaderNameRegex = regexp.MustCompile(`(?i)^(monday|tuesday|wednesday|thursday|friday|saturday|sunday),?\s+([a-z]+)\.?\s+(\d{1,2})(?:,?\s+(\d{4}))?$`)
+	dayHeaderISORegex  = regexp.MustCompile(`(?i)^(?:(monday|tuesday|wednesday|thursday|friday|saturday|sunday),?\s+)?(\d{4}-\d{2}-\d{2})$`)
+)
+
+// monthNumbers maps English month names and abbreviations to their number
+var monthNumbers = map[string]time.Month{
+	"jan": time.January, "january": time.January,
+	"feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March,
+	"apr": time.April, "april": time.April,
+	"may": time.May,
+	"jun": time.June, "june": time.June,
+	"jul": time.July, "july": time.July,
+	"aug": time.August, "august": time.August,
+	"sep": time.September, "sept": time.September, "september": time.September,
+	"oct": time.October, "october": time.October,
+	"nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+// parseDayHeader reads a day header row into a date (midnight UTC, only the
+// date matters). Weekday and month names must be in English, e.g. "Friday
+// Dec 18"; a header in Spanish or Catalan like "Viernes 18 de diciembre"
+// isn't one, so use an ISO date there. A header without a year takes the year of the previous
+// header, or the calendar's for the first one, and moves on to the next year
+// when its date would go backwards, so "Thursday Dec 31" followed by
+// "Friday Jan 1" spans New Year. ok is false if text isn't a day header.
+func (c *Calendar) parseDayHeader(text string, previous time.Time) (day time.Time, ok bool) {
+	var weekday string
+	if m := dayHeaderISORegex.FindStringSubmatch(text); m != nil {
+		d, err := time.Parse("2006-01-02", m[2])
+		if err != nil {
+			return time.Time{}, false
+		}
+		weekday, day = m[1], d
+	} else if m := dayHeaderNameRegex.FindStringSubmatch(text); m != nil {
+		month, found := monthNumbers[strings.ToLower(m[2])]
+		if !found {
+			return time.Time{}, false
+		}
+		dayOfMonth, _ := strconv.Atoi(m[3])
+		if m[4] != "" {
+			year, _ := strconv.Atoi(m[4])
+			day = time.Date(year, month, dayOfMonth, 0, 0, 0, 0, time.UTC)
+		} else {
+			year := c.Year
+			if !previous.IsZero() {
+				year = previous.Year()
+			}
+			day = time.Date(year, month, dayOfMonth, 0, 0, 0, 0, time.UTC)
+			if !previous.IsZero() && day.Before(previous) {
+				day = day.AddDate(1, 0, 0)
+			}
+		}
+		if day.Day() != dayOfMonth {
+			return time.Time{}, false // "Feb 30"
+		}
+		weekday = m[1]
+	} else {
+		return time.Time{}, false
+	}
+
+	if weekday != "" && !strings.EqualFold(weekday, day.Weekday().String()) {
+		log.Printf("Schedule: day header '%s' says %s but %s is a %s, check the year", text, weekday, day.Format("2006-01-02"), day.Weekday())
+	}
+	return day, true
+}
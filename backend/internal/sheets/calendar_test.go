@@ -0,0 +1,45 @@
+package sheets
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDayHeader(t *testing.T) {
+	cal, err := NewCalendar(2026, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec31 := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		text     string
+		previous time.Time
+		want     string // Empty if not a day header
+	}{
+		{text: "Friday Dec 18", want: "2026-12-18"},
+		{text: "friday, december 18, 2027", want: "2027-12-18"},
+		{text: "Friday Sept. 18", want: "2026-09-18"},
+		{text: "2027-12-18", want: "2027-12-18"},
+		{text: "Saturday 2026-12-19", want: "2026-12-19"},
+		{text: "Friday Jan 1", previous: dec31, want: "2027-01-01"},
+		{text: "Friday Feb 30"},
+		{text: "Viernes 18 de diciembre"},
+		{text: "Divendres 18 de desembre"},
+		{text: "Friday Dic 18"},
+		{text: "Ceremonia"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			day, ok := cal.parseDayHeader(tt.text, tt.previous)
+			var got string
+			if ok {
+				got = day.Format("2006-01-02")
+			}
+			if got != tt.want {
+				t.Errorf("parseDayHeader(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
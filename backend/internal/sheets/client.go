@@ -3,12 +3,14 @@ package sheets
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/casassg/wedding/backend/internal/store"
@@ -18,13 +20,19 @@ import (
 
 // Client wraps the Google Sheets API client
 type Client struct {
-	service   *sheets.Service
-	sheetID   string
-	sheetName string
+	service       *sheets.Service
+	sheetID       string
+	sheetName     string
+	columnAliases map[string][]string
+	calendar      *Calendar // Year and timezone of schedule dates and deadlines
+
+	mu      sync.Mutex
+	columns ColumnMap // Resolved from the header row on each read
 }
 
-// NewClient creates a new Google Sheets client
-func NewClient(ctx context.Context) (*Client, error) {
+// NewClient creates a new Google Sheets client. Dates in the sheet are read
+// with cal.
+func NewClient(ctx context.Context, cal *Calendar) (*Client, error) {
 	sheetID := os.Getenv("GOOGLE_SHEET_ID")
 	if sheetID == "" {
 		log.Println("Warning: GOOGLE_SHEET_ID not set, sync disabled")
@@ -70,12 +78,20 @@ func NewClient(ctx context.Context) (*Client, error) {
 		sheetName = "Guests"
 	}
 
+	// Extra header aliases, e.g. "partner=Plus one|Pareja;invite_code=Code"
+	columnAliases, err := ParseColumnAliases(os.Getenv("GOOGLE_SHEET_COLUMN_ALIASES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GOOGLE_SHEET_COLUMN_ALIASES: %w", err)
+	}
+
 	log.Printf("Google Sheets client initialized for sheet: %s (name: %s)", sheetID, sheetName)
 
 	return &Client{
-		service:   service,
-		sheetID:   sheetID,
-		sheetName: sheetName,
+		service:       service,
+		sheetID:       sheetID,
+		sheetName:     sheetName,
+		columnAliases: columnAliases,
+		calendar:      cal,
 	}, nil
 }
 
@@ -84,108 +100,482 @@ func (c *Client) IsConfigured() bool {
 	return c.service != nil
 }
 
-// ReadSheet reads all invite data from the sheet
-func (c *Client) ReadSheet(ctx context.Context) ([]*store.UpsertInviteParams, error) {
+// ReadInvites reads all invite data from the Guests sheet
+func (c *Client) ReadInvites(ctx context.Context) ([]*store.UpsertInviteParams, error) {
+	rows, err := c.ReadGuestRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return codedRows(rows), nil
+}
+
+// ReadGuestRows reads every named row of the Guests sheet, with or without
+// an invite code
+func (c *Client) ReadGuestRows(ctx context.Context) ([]*store.UpsertInviteParams, error) {
 	if !c.IsConfigured() {
 		return nil, nil // Return empty when not configured
 	}
 
-	// Read data from 'Guests' sheet (rows 2+, columns A-N)
-	// Column mapping:
-	// A: Name, B: Parella, C: Fills, D: Location, E: State, F: Total, G: No Hijos
-	// H: Invite Code, I: Adults confirmed, J: Kids confirmed, K: Dietary, L: Message for us, M: Song request, N: Updated At
-	readRange := fmt.Sprintf("'%s'!A2:N", c.sheetName)
-	resp, err := c.service.Spreadsheets.Values.Get(c.sheetID, readRange).Context(ctx).Do()
+	values, cols, err := c.readGuests(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read sheet: %w", err)
+		return nil, err
 	}
 
+	rows := parseGuestRows(values[1:], cols, c.calendar)
+
+	log.Printf("Read %d invites from Google Sheet '%s'", len(codedRows(rows)), c.sheetName)
+	return rows, nil
+}
+
+// codedRows returns the rows that have an invite code
+func codedRows(rows []*store.UpsertInviteParams) []*store.UpsertInviteParams {
+	var coded []*store.UpsertInviteParams
+	for _, row := range rows {
+		if row.InviteCode != "" {
+			coded = append(coded, row)
+		}
+	}
+	return coded
+}
+
+// parseGuestRows converts data rows (header excluded) into invite params,
+// skipping rows without a name. Rows without an invite code are kept for
+// code assignment, see codedRows.
+// Row numbers start at 2 since sheet rows are 1-based and row 1 is the header.
+func parseGuestRows(values [][]interface{}, cols ColumnMap, cal *Calendar) []*store.UpsertInviteParams {
 	var rows []*store.UpsertInviteParams
-	for i, row := range resp.Values {
-		rowNum := int64(i + 2) // Sheet rows start at 1, and we skip header row
+	for i, row := range values {
+		rowNum := int64(i + 2)
 
 		// Parse row data
-		sheetRow := store.UpsertInviteParams{SheetRow: &rowNum}
-
-		// Column A: Name
-		if len(row) > 0 {
-			sheetRow.Name = toString(row[0])
+		sheetRow := store.UpsertInviteParams{
+			SheetRow:        &rowNum,
+			Name:            strings.TrimSpace(toString(cols.Cell(row, ColName))),
+			InviteCode:      strings.TrimSpace(toString(cols.Cell(row, ColInviteCode))),
+			MaxKids:         toInt(cols.Cell(row, ColKids)),
+			ConfirmedAdults: toInt(cols.Cell(row, ColAdultsConfirmed)),
 		}
 
-		// Column B: Parella (Si/No)
-		// Convert Parella to max_adults
-		maxAdults := int64(1)
-		if len(row) > 1 {
-			if strings.ToLower(toString(row[1])) == "si" {
-				maxAdults = 2
+		// Optional per-invite deadline override, ignored when unparseable
+		if raw := strings.TrimSpace(toString(cols.Cell(row, ColRSVPDeadline))); raw != "" {
+			deadline, err := cal.ParseDeadline(raw)
+			if err != nil {
+				log.Printf("Row %d: ignoring RSVP deadline: %v", rowNum, err)
+			} else {
+				sheetRow.RsvpDeadline = &deadline
 			}
 		}
-		sheetRow.MaxAdults = maxAdults
 
-		// Column C: Fills (kids)
-		if len(row) > 2 {
-			sheetRow.MaxKids = toInt(row[2])
+		// Convert Parella (Si/No) to max_adults
+		sheetRow.MaxAdults = 1
+		if strings.ToLower(strings.TrimSpace(toString(cols.Cell(row, ColPartner)))) == "si" {
+			sheetRow.MaxAdults = 2
 		}
 
-		// Column H: Invite Code (index 7)
-		if len(row) > 7 {
-			sheetRow.InviteCode = toString(row[7])
+		// Skip rows without a name
+		if sheetRow.Name == "" {
+			continue
 		}
 
-		// Column I: Adults confirmed (index 8)
-		if len(row) > 8 {
-			sheetRow.ConfirmedAdults = toInt(row[8])
+		rows = append(rows, &sheetRow)
+	}
+	return rows
+}
+
+// maxBatchRanges caps the rows written by a single BatchUpdate request
+const maxBatchRanges = 200
+
+// WriteRSVPs writes RSVP response data back to the sheet, batching all rows
+// into as few Values.BatchUpdate requests as possible. The invite code column
+// is read first so each answer goes to the row that holds its code now.
+func (c *Client) WriteRSVPs(ctx context.Context, invites []*store.Invite) []error {
+	errs := make([]error, len(invites))
+	if !c.IsConfigured() {
+		return errs // No-op when not configured
+	}
+
+	cols, err := c.guestColumns(ctx)
+	var codes [][]interface{}
+	if err == nil {
+		codes, err = c.readCodeColumn(ctx, cols)
+	}
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
 		}
+		return errs
+	}
 
-		// Skip rows without invite code or name
-		if sheetRow.InviteCode == "" || sheetRow.Name == "" {
+	rows := make([]int64, len(invites))
+	for i, invite := range invites {
+		rows[i], errs[i] = resolveRow(codes, invite.InviteCode, invite.SheetRow)
+	}
+
+	cells := make([]map[string]interface{}, len(invites))
+	for i, invite := range invites {
+		cells[i] = rsvpValues(invite)
+	}
+	c.writeRows(ctx, c.sheetName, cols, rsvpColumns, cells, rows, errs)
+	return errs
+}
+
+// writeRows writes cells to rows of a tab in as few BatchUpdate requests as
+// possible, skipping rows that already have an error in errs
+func (c *Client) writeRows(ctx context.Context, tab string, cols ColumnMap, keys []string, cells []map[string]interface{}, rows []int64, errs []error) {
+	for start := 0; start < len(rows); start += maxBatchRanges {
+		end := min(start+maxBatchRanges, len(rows))
+		c.writeBatch(ctx, tab, cols, keys, cells[start:end], rows[start:end], errs[start:end])
+	}
+}
+
+// writeBatch writes cells to rows in one BatchUpdate request, recording in
+// errs every row the API didn't report as updated
+func (c *Client) writeBatch(ctx context.Context, tab string, cols ColumnMap, keys []string, cells []map[string]interface{}, rows []int64, errs []error) {
+	data := make([]*sheets.ValueRange, 0, len(rows))
+	for i := range rows {
+		if errs[i] != nil {
 			continue
 		}
+		data = append(data, rowRange(tab, rows[i], cols, keys, cells[i]))
+	}
+	if len(data) == 0 {
+		return
+	}
 
-		rows = append(rows, &sheetRow)
+	req := &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "RAW",
+		Data:             data,
+	}
+	var resp *sheets.BatchUpdateValuesResponse
+	err := withBackoff(ctx, func() (err error) {
+		resp, err = c.service.Spreadsheets.Values.BatchUpdate(c.sheetID, req).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to write to sheet: %w", err)
 	}
 
-	log.Printf("Read %d invites from Google Sheet '%s'", len(rows), c.sheetName)
-	return rows, nil
+	// Per-range results, a row only counts as written if the API says so
+	updated := make(map[int64]bool, len(data))
+	if resp != nil {
+		for _, result := range resp.Responses {
+			if matches := updatedRowRegex.FindStringSubmatch(result.UpdatedRange); matches != nil {
+				rowNum, _ := strconv.ParseInt(matches[1], 10, 64)
+				updated[rowNum] = true
+			}
+		}
+	}
+	for i := range rows {
+		if errs[i] != nil || updated[rows[i]] {
+			continue
+		}
+		if err != nil {
+			errs[i] = err
+		} else {
+			errs[i] = fmt.Errorf("row %d was not updated", rows[i])
+		}
+	}
 }
 
-// WriteRSVP writes RSVP response data back to the sheet
-func (c *Client) WriteRSVP(ctx context.Context, data *store.Invite) error {
+// AppendInvite adds a new invite row at the bottom of the sheet and returns its row number
+func (c *Client) AppendInvite(ctx context.Context, data *store.Invite) (int64, error) {
 	if !c.IsConfigured() {
-		return nil // No-op when not configured
+		return 0, errors.New("google sheets not configured")
 	}
 
-	if data.SheetRow == nil {
-		return fmt.Errorf("no sheet row number for invite %s", data.InviteCode)
+	cols, err := c.guestColumns(ctx)
+	if err != nil {
+		return 0, err
 	}
 
-	rowNum := *data.SheetRow
-
-	responseAt := time.Now().UTC()
-	if data.ResponseAt != nil {
-		responseAt = *data.ResponseAt
+	// New rows are written in full so every other column starts out empty
+	_, last := cols.Span(GuestColumnKeys())
+	values := make([]interface{}, last+1)
+	for i := range values {
+		values[i] = ""
 	}
-
-	// Prepare values for columns I-N (Adults confirmed, Kids confirmed, Dietary, Message for us, Song request, Updated At)
-	values := []interface{}{
-		data.ConfirmedAdults, // Column I: Adults confirmed
-		data.ConfirmedKids,   // Column J: Kids confirmed
-		data.DietaryInfo,     // Column K: Dietary
-		data.MessageForUs,    // Column L: Message for us
-		data.SongRequest,     // Column M: Song request
-		responseAt,           // Column O: Response At
+	for key, value := range inviteValues(data) {
+		values[cols[key]] = value
 	}
 
-	// Write to sheet
-	writeRange := fmt.Sprintf("'%s'!I%d:N%d", c.sheetName, rowNum, rowNum)
+	appendRange := fmt.Sprintf("'%s'", c.sheetName)
 	valueRange := &sheets.ValueRange{
 		Values: [][]interface{}{values},
 	}
 
-	_, err := c.service.Spreadsheets.Values.Update(c.sheetID, writeRange, valueRange).
+	resp, err := c.service.Spreadsheets.Values.Append(c.sheetID, appendRange, valueRange).
 		ValueInputOption("RAW").
+		InsertDataOption("INSERT_ROWS").
 		Context(ctx).
 		Do()
+	if err != nil {
+		return 0, fmt.Errorf("failed to append to sheet: %w", err)
+	}
+	if resp.Updates == nil {
+		return 0, fmt.Errorf("append to sheet returned no updated range")
+	}
+
+	// UpdatedRange looks like "'Guests'!A12:N12"
+	matches := updatedRowRegex.FindStringSubmatch(resp.Updates.UpdatedRange)
+	if matches == nil {
+		return 0, fmt.Errorf("unexpected updated range %q", resp.Updates.UpdatedRange)
+	}
+	return strconv.ParseInt(matches[1], 10, 64)
+}
+
+// updatedRowRegex extracts the first row number from an A1 range
+var updatedRowRegex = regexp.MustCompile(`![A-Z]+(\d+)`)
+
+// WriteInvite writes an invite's name and limits back to its row
+func (c *Client) WriteInvite(ctx context.Context, data *store.Invite) error {
+	if !c.IsConfigured() {
+		return nil // No-op when not configured
+	}
+
+	cols, err := c.guestColumns(ctx)
+	if err != nil {
+		return err
+	}
+
+	codes, err := c.readCodeColumn(ctx, cols)
+	if err != nil {
+		return err
+	}
+	rowNum, err := resolveRow(codes, data.InviteCode, data.SheetRow)
+	if err != nil {
+		return err
+	}
+
+	values := inviteValues(data)
+	delete(values, ColInviteCode) // Codes are never changed in place
+
+	return c.writeRow(ctx, rowNum, cols, inviteColumns, values)
+}
+
+// DeleteInvite removes an invite's row from the sheet and returns the row
+// number it was found at. Rows are found by invite code, see resolveRow.
+func (c *Client) DeleteInvite(ctx context.Context, inviteCode string, sheetRow int64) (int64, error) {
+	if !c.IsConfigured() {
+		return 0, errors.New("google sheets not configured")
+	}
+
+	cols, err := c.guestColumns(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	codes, err := c.readCodeColumn(ctx, cols)
+	if err != nil {
+		return 0, err
+	}
+
+	rowNum, err := resolveRow(codes, inviteCode, &sheetRow)
+	if err != nil {
+		return 0, err
+	}
+
+	sheetGID, err := c.sheetGID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	req := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{
+			DeleteDimension: &sheets.DeleteDimensionRequest{
+				Range: &sheets.DimensionRange{
+					SheetId:    sheetGID,
+					Dimension:  "ROWS",
+					StartIndex: rowNum - 1, // Zero-based, end exclusive
+					EndIndex:   rowNum,
+				},
+			},
+		}},
+	}
+
+	if _, err := c.service.Spreadsheets.BatchUpdate(c.sheetID, req).Context(ctx).Do(); err != nil {
+		return 0, fmt.Errorf("failed to delete sheet row %d: %w", rowNum, err)
+	}
+
+	return rowNum, nil
+}
+
+// WriteInviteCodes writes generated invite codes to their rows in a single
+// batch request, after checking that none of the rows moved since they were read
+func (c *Client) WriteInviteCodes(ctx context.Context, codes []*CodeAssignment) error {
+	if !c.IsConfigured() {
+		return errors.New("google sheets not configured")
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+
+	values, cols, err := c.readGuests(ctx)
+	if err != nil {
+		return err
+	}
+	if err := checkUncodedRows(values, cols, codes); err != nil {
+		return err
+	}
+
+	data := make([]*sheets.ValueRange, 0, len(codes))
+	for _, code := range codes {
+		data = append(data, &sheets.ValueRange{
+			Range:  fmt.Sprintf("'%s'!%s%d", c.sheetName, cols.Letter(ColInviteCode), code.Row),
+			Values: [][]interface{}{{code.InviteCode}},
+		})
+	}
+
+	req := &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "RAW",
+		Data:             data,
+	}
+	err = withBackoff(ctx, func() error {
+		_, err := c.service.Spreadsheets.Values.BatchUpdate(c.sheetID, req).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write invite codes: %w", err)
+	}
+	return nil
+}
+
+// readGuests reads the whole 'Guests' sheet including the header row. Columns
+// are resolved by header name so inserting or moving columns is safe.
+func (c *Client) readGuests(ctx context.Context) ([][]interface{}, ColumnMap, error) {
+	readRange := fmt.Sprintf("'%s'", c.sheetName)
+	var resp *sheets.ValueRange
+	err := withBackoff(ctx, func() (err error) {
+		resp, err = c.service.Spreadsheets.Values.Get(c.sheetID, readRange).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read sheet: %w", err)
+	}
+	if len(resp.Values) == 0 {
+		return nil, nil, fmt.Errorf("sheet '%s' is empty, expected a header row", c.sheetName)
+	}
+
+	cols, err := ResolveGuestColumns(resp.Values[0], c.columnAliases)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sheet '%s': %w", c.sheetName, err)
+	}
+	c.setColumns(cols)
+	return resp.Values, cols, nil
+}
+
+// checkUncodedRows verifies that every row still has the same name and no
+// invite code, so codes are never written to the wrong guest
+func checkUncodedRows(values [][]interface{}, cols ColumnMap, codes []*CodeAssignment) error {
+	for _, code := range codes {
+		i := int(code.Row - 1)
+		if i < 1 || i >= len(values) ||
+			strings.TrimSpace(toString(cols.Cell(values[i], ColName))) != code.Name ||
+			strings.TrimSpace(toString(cols.Cell(values[i], ColInviteCode))) != "" {
+			return fmt.Errorf("row %d (%s) changed since it was read, run again", code.Row, code.Name)
+		}
+	}
+	return nil
+}
+
+// readCodeColumn reads the invite code column, header included, one single-cell
+// row per sheet row
+func (c *Client) readCodeColumn(ctx context.Context, cols ColumnMap) ([][]interface{}, error) {
+	letter := cols.Letter(ColInviteCode)
+	codeRange := fmt.Sprintf("'%s'!%s:%s", c.sheetName, letter, letter)
+
+	var resp *sheets.ValueRange
+	err := withBackoff(ctx, func() (err error) {
+		resp, err = c.service.Spreadsheets.Values.Get(c.sheetID, codeRange).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read invite codes: %w", err)
+	}
+	return resp.Values, nil
+}
+
+// resolveRow returns the row that holds inviteCode in a code column read,
+// logging when it's no longer the row recorded at the last read. Fails
+// rather than pick a row if the code is missing or in several rows.
+func resolveRow(codes [][]interface{}, inviteCode string, sheetRow *int64) (int64, error) {
+	var expected int64
+	if sheetRow != nil {
+		expected = *sheetRow
+	}
+
+	rowNum, err := findCodeRow(codes, inviteCode)
+	if err != nil {
+		return 0, fmt.Errorf("invite %s: %w", inviteCode, err)
+	}
+	if rowNum != expected {
+		log.Printf("Invite %s moved from row %d to row %d since the last read", inviteCode, expected, rowNum)
+	}
+	return rowNum, nil
+}
+
+// findCodeRow returns the 1-based row holding inviteCode in a single-column
+// read. Returns ErrInviteNotFound when the code isn't found, and
+// ErrDuplicateInviteCode when it's in more than one row.
+func findCodeRow(values [][]interface{}, inviteCode string) (int64, error) {
+	var found int64
+	for rowNum := int64(2); rowNum <= int64(len(values)); rowNum++ {
+		if len(values[rowNum-1]) == 0 || strings.TrimSpace(toString(values[rowNum-1][0])) != inviteCode {
+			continue
+		}
+		if found != 0 {
+			return 0, fmt.Errorf("%w: rows %d and %d", ErrDuplicateInviteCode, found, rowNum)
+		}
+		found = rowNum
+	}
+	if found == 0 {
+		return 0, ErrInviteNotFound
+	}
+	return found, nil
+}
+
+// sheetGID returns the numeric ID of the guests tab, needed to delete rows
+func (c *Client) sheetGID(ctx context.Context) (int64, error) {
+	tabs, err := c.sheetTabs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	gid, ok := tabs[c.sheetName]
+	if !ok {
+		return 0, fmt.Errorf("sheet '%s' not found", c.sheetName)
+	}
+	return gid, nil
+}
+
+// sheetTabs returns the numeric ID of every tab in the spreadsheet by title
+func (c *Client) sheetTabs(ctx context.Context) (map[string]int64, error) {
+	var resp *sheets.Spreadsheet
+	err := withBackoff(ctx, func() (err error) {
+		resp, err = c.service.Spreadsheets.Get(c.sheetID).Fields("sheets.properties").Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spreadsheet: %w", err)
+	}
+	tabs := make(map[string]int64, len(resp.Sheets))
+	for _, sheet := range resp.Sheets {
+		if sheet.Properties != nil {
+			tabs[sheet.Properties.Title] = sheet.Properties.SheetId
+		}
+	}
+	return tabs, nil
+}
+
+// writeRow writes the given cell values to a row, see rowRange
+func (c *Client) writeRow(ctx context.Context, rowNum int64, cols ColumnMap, keys []string, cells map[string]interface{}) error {
+	valueRange := rowRange(c.sheetName, rowNum, cols, keys, cells)
+
+	err := withBackoff(ctx, func() error {
+		_, err := c.service.Spreadsheets.Values.Update(c.sheetID, valueRange.Range, valueRange).
+			ValueInputOption("RAW").
+			Context(ctx).
+			Do()
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to write to sheet: %w", err)
 	}
@@ -193,6 +583,92 @@ func (c *Client) WriteRSVP(ctx context.Context, data *store.Invite) error {
 	return nil
 }
 
+// rowRange builds the value range for writing cells to a row of a tab. It
+// spans the columns covering keys; cells in between that belong to other
+// columns are left as nil, which the Sheets API skips.
+func rowRange(tab string, rowNum int64, cols ColumnMap, keys []string, cells map[string]interface{}) *sheets.ValueRange {
+	first, last := cols.Span(keys)
+	values := make([]interface{}, last-first+1)
+	for key, value := range cells {
+		values[cols[key]-first] = value
+	}
+
+	return &sheets.ValueRange{
+		Range:  fmt.Sprintf("'%s'!%s%d:%s%d", tab, columnLetter(first), rowNum, columnLetter(last), rowNum),
+		Values: [][]interface{}{values},
+	}
+}
+
+// rsvpValues returns the RSVP cell values for an invite keyed by column
+func rsvpValues(data *store.Invite) map[string]interface{} {
+	responseAt := time.Now().UTC()
+	if data.ResponseAt != nil {
+		responseAt = *data.ResponseAt
+	}
+
+	return map[string]interface{}{
+		ColAdultsConfirmed: data.ConfirmedAdults,
+		ColKidsConfirmed:   data.ConfirmedKids,
+		ColDietary:         data.DietaryInfo,
+		ColMessage:         data.MessageForUs,
+		ColSong:            data.SongRequest,
+		ColResponseAt:      responseAt,
+	}
+}
+
+// inviteValues returns the master data cell values for an invite keyed by column
+func inviteValues(data *store.Invite) map[string]interface{} {
+	partner := "No"
+	if data.MaxAdults >= 2 {
+		partner = "Si"
+	}
+
+	return map[string]interface{}{
+		ColName:       data.Name,
+		ColPartner:    partner,
+		ColKids:       data.MaxKids,
+		ColInviteCode: data.InviteCode,
+	}
+}
+
+// guestColumns returns the column mapping resolved by the last ReadSheet,
+// reading the header row if the sheet hasn't been read yet.
+func (c *Client) guestColumns(ctx context.Context) (ColumnMap, error) {
+	c.mu.Lock()
+	cols := c.columns
+	c.mu.Unlock()
+	if cols != nil {
+		return cols, nil
+	}
+
+	headerRange := fmt.Sprintf("'%s'!1:1", c.sheetName)
+	var resp *sheets.ValueRange
+	err := withBackoff(ctx, func() (err error) {
+		resp, err = c.service.Spreadsheets.Values.Get(c.sheetID, headerRange).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header row: %w", err)
+	}
+	if len(resp.Values) == 0 {
+		return nil, fmt.Errorf("sheet '%s' has no header row", c.sheetName)
+	}
+
+	cols, err = ResolveGuestColumns(resp.Values[0], c.columnAliases)
+	if err != nil {
+		return nil, fmt.Errorf("sheet '%s': %w", c.sheetName, err)
+	}
+	c.setColumns(cols)
+	return cols, nil
+}
+
+// setColumns caches the resolved column mapping
+func (c *Client) setColumns(cols ColumnMap) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.columns = cols
+}
+
 // Helper functions for type conversion
 
 func toString(v interface{}) string {
@@ -220,6 +696,133 @@ func toInt(v interface{}) int64 {
 	return 0
 }
 
+// ReadEvents reads the events from the "Events" tab and who is invited to
+// each from the event's own tab, all tabs in a single request.
+// Returns nil if the spreadsheet has no "Events" tab.
+func (c *Client) ReadEvents(ctx context.Context) ([]*EventRow, error) {
+	if !c.IsConfigured() {
+		return nil, nil // Return empty when not configured
+	}
+
+	tabs, err := c.sheetTabs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := tabs[eventsTab]; !ok {
+		return nil, nil // No events, leave DB as is
+	}
+
+	var resp *sheets.ValueRange
+	err = withBackoff(ctx, func() (err error) {
+		resp, err = c.service.Spreadsheets.Values.Get(c.sheetID, fmt.Sprintf("'%s'", eventsTab)).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events sheet: %w", err)
+	}
+	if len(resp.Values) == 0 {
+		return []*EventRow{}, nil // Header removed, no events left
+	}
+	cols, err := resolveColumns(resp.Values[0], eventColumns, nil)
+	if err != nil {
+		return nil, fmt.Errorf("sheet '%s': %w", eventsTab, err)
+	}
+	events := parseEventRows(resp.Values[1:], cols, c.calendar)
+	if len(events) == 0 {
+		return events, nil
+	}
+
+	ranges := make([]string, len(events))
+	for i, event := range events {
+		if _, ok := tabs[event.Tab]; !ok {
+			return nil, fmt.Errorf("event %s: sheet '%s' not found", event.Key, event.Tab)
+		}
+		ranges[i] = fmt.Sprintf("'%s'", event.Tab)
+	}
+	var batch *sheets.BatchGetValuesResponse
+	err = withBackoff(ctx, func() (err error) {
+		batch, err = c.service.Spreadsheets.Values.BatchGet(c.sheetID).Ranges(ranges...).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event sheets: %w", err)
+	}
+	if len(batch.ValueRanges) != len(events) {
+		return nil, fmt.Errorf("read %d event sheets, expected %d", len(batch.ValueRanges), len(events))
+	}
+
+	invitations := 0
+	for i, event := range events {
+		cols, err := c.eventColumns(event.Tab, batch.ValueRanges[i].Values)
+		if err != nil {
+			return nil, err
+		}
+		event.Invitations = parseInvitationRows(batch.ValueRanges[i].Values, cols)
+		invitations += len(event.Invitations)
+	}
+
+	log.Printf("Read %d events with %d invitations from Google Sheet '%s'", len(events), invitations, eventsTab)
+	return events, nil
+}
+
+// WriteEventRSVPs writes answers for one event back to its tab, batching all
+// rows like WriteRSVPs. The tab is read first so each answer goes to the row
+// that holds its code now.
+func (c *Client) WriteEventRSVPs(ctx context.Context, event *store.Event, invitations []*store.InviteEvent) []error {
+	errs := make([]error, len(invitations))
+	if !c.IsConfigured() {
+		return errs // No-op when not configured
+	}
+
+	var resp *sheets.ValueRange
+	err := withBackoff(ctx, func() (err error) {
+		resp, err = c.service.Spreadsheets.Values.Get(c.sheetID, fmt.Sprintf("'%s'", event.SheetTab)).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to read sheet '%s': %w", event.SheetTab, err)
+	}
+	var cols ColumnMap
+	if err == nil {
+		cols, err = c.eventColumns(event.SheetTab, resp.Values)
+	}
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	// Same single-column shape as readCodeColumn
+	codes := make([][]interface{}, len(resp.Values))
+	for i, row := range resp.Values {
+		codes[i] = []interface{}{cols.Cell(row, ColInviteCode)}
+	}
+
+	rows := make([]int64, len(invitations))
+	cells := make([]map[string]interface{}, len(invitations))
+	for i, invitation := range invitations {
+		rows[i], errs[i] = resolveRow(codes, invitation.InviteCode, invitation.SheetRow)
+		cells[i] = eventRSVPValues(invitation)
+	}
+
+	c.writeRows(ctx, event.SheetTab, cols, eventRSVPColumns, cells, rows, errs)
+	return errs
+}
+
+// eventColumns resolves the header row of an event's tab. The guest list's
+// column aliases apply here too.
+func (c *Client) eventColumns(tab string, values [][]interface{}) (ColumnMap, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("sheet '%s' is empty, expected a header row", tab)
+	}
+	cols, err := resolveColumns(values[0], eventTabColumns, c.columnAliases)
+	if err != nil {
+		return nil, fmt.Errorf("sheet '%s': %w", tab, err)
+	}
+	return cols, nil
+}
+
 // ScheduleEventRow represents a schedule event parsed from Google Sheets
 // Supports multilingual event names and descriptions (ES=default, EN, CA)
 type ScheduleEventRow struct {
@@ -232,52 +835,45 @@ type ScheduleEventRow struct {
 	DescriptionES string  // Spanish (from "Description" column G)
 	DescriptionEN string  // English (from column J)
 	DescriptionCA string  // Catalan (from column K)
+	Timezone      string  // IANA timezone of the start and end times (column L, else the wedding's)
 }
 
-// ReadScheduleSheet reads schedule events from the "Schedule" sheet
+// ReadSchedule reads schedule events from the "Schedule" sheet
 // Only returns public events (filtered here before returning).
-// Column mapping (based on user's sheet):
-// A: Start Time, B: End Time, C: Public (checkbox), D: Evento (Spanish name)
-// E: Team/Person, F: Location, G: Description (Spanish)
-// H: Event name (English), I: Nombre catalan, J: Descripcion English, K: Descripcion Catalan
-func (c *Client) ReadScheduleSheet(ctx context.Context, weddingYear int) ([]*ScheduleEventRow, error) {
+func (c *Client) ReadSchedule(ctx context.Context) ([]*ScheduleEventRow, error) {
 	if !c.IsConfigured() {
 		return nil, nil // Return empty when not configured
 	}
 
-	// Read data from 'Schedule' sheet (rows 2+, columns A-K)
-	readRange := "'Schedule'!A2:K"
-	resp, err := c.service.Spreadsheets.Values.Get(c.sheetID, readRange).Context(ctx).Do()
+	// Read data from 'Schedule' sheet (rows 2+, columns A-L)
+	readRange := "'Schedule'!A2:L"
+	var resp *sheets.ValueRange
+	err := withBackoff(ctx, func() (err error) {
+		resp, err = c.service.Spreadsheets.Values.Get(c.sheetID, readRange).Context(ctx).Do()
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to read schedule sheet: %w", err)
 	}
 
+	events := parseScheduleRows(resp.Values, c.calendar)
+
+	log.Printf("Read %d public schedule events from Google Sheet 'Schedule'", len(events))
+	return events, nil
+}
+
+// parseScheduleRows converts Schedule sheet rows (header excluded) into public events.
+// Column mapping (based on user's sheet):
+// A: Start Time, B: End Time, C: Public (checkbox), D: Evento (Spanish name)
+// E: Team/Person, F: Location, G: Description (Spanish)
+// H: Event name (English), I: Nombre catalan, J: Descripcion English, K: Descripcion Catalan
+// L: Timezone (optional IANA name, e.g. "Europe/Madrid")
+// Day header rows (see Calendar.parseDayHeader) set the date of the events below them.
+func parseScheduleRows(values [][]interface{}, cal *Calendar) []*ScheduleEventRow {
 	var events []*ScheduleEventRow
-	var currentDate string // Track current date from day header rows (ISO format)
-
-	// Regex to match day header like "Friday Dec 18" or "Saturday Dec 19"
-	dayHeaderRegex := regexp.MustCompile(`(?i)^(monday|tuesday|wednesday|thursday|friday|saturday|sunday)\s+(\w+)\s+(\d+)$`)
-
-	// Month name to number mapping
-	monthMap := map[string]int{
-		"jan": 1, "january": 1,
-		"feb": 2, "february": 2,
-		"mar": 3, "march": 3,
-		"apr": 4, "april": 4,
-		"may": 5,
-		"jun": 6, "june": 6,
-		"jul": 7, "july": 7,
-		"aug": 8, "august": 8,
-		"sep": 9, "september": 9,
-		"oct": 10, "october": 10,
-		"nov": 11, "november": 11,
-		"dec": 12, "december": 12,
-	}
-
-	// Copan timezone (UTC-6)
-	copanLoc := time.FixedZone("America/Tegucigalpa", -6*60*60)
-
-	for _, row := range resp.Values {
+	var currentDate time.Time // Date of the last day header row
+
+	for _, row := range values {
 		// Column A: Start Time
 		startTimeRaw := ""
 		if len(row) > 0 {
@@ -341,28 +937,23 @@ func (c *Client) ReadScheduleSheet(ctx context.Context, weddingYear int) ([]*Sch
 			descriptionCA = strings.TrimSpace(toString(row[10]))
 		}
 
-		// Check if this is a day header row (empty times, event name matches day pattern)
+		// Column L: Timezone (empty for the wedding's timezone)
+		timezone := ""
+		if len(row) > 11 {
+			timezone = strings.TrimSpace(toString(row[11]))
+		}
+
+		// Check if this is a day header row (empty times, event name is a date)
 		if startTimeRaw == "" && endTimeRaw == "" && eventNameES != "" {
-			matches := dayHeaderRegex.FindStringSubmatch(eventNameES)
-			if matches != nil {
-				// Parse month and day from "Friday Dec 18"
-				monthStr := strings.ToLower(matches[2])
-				dayStr := matches[3]
-
-				if monthNum, ok := monthMap[monthStr]; ok {
-					day, err := strconv.Atoi(dayStr)
-					if err == nil {
-						// Format as ISO date with the wedding year
-						currentDate = fmt.Sprintf("%d-%02d-%02d", weddingYear, monthNum, day)
-						log.Printf("Schedule: Found day header '%s' -> date %s", eventNameES, currentDate)
-					}
-				}
+			if day, ok := cal.parseDayHeader(eventNameES, currentDate); ok {
+				currentDate = day
+				log.Printf("Schedule: Found day header '%s' -> date %s", eventNameES, day.Format("2006-01-02"))
 				continue // Skip day header rows, don't add as events
 			}
 		}
 
 		// Skip rows without event name, without a current date context, or non-public
-		if eventNameES == "" || currentDate == "" || !isPublic {
+		if eventNameES == "" || currentDate.IsZero() || !isPublic {
 			continue
 		}
 
@@ -375,8 +966,18 @@ func (c *Client) ReadScheduleSheet(ctx context.Context, weddingYear int) ([]*Sch
 		startTime24 := parseTimeTo24h(startTimeRaw)
 		endTime24 := parseTimeTo24h(endTimeRaw)
 
-		// Build full datetime from date + time in Copan timezone
-		startDateTime, err := parseDateTime(currentDate, startTime24, copanLoc)
+		// Times are in the event's own timezone if it has one, else the wedding's
+		loc := cal.Location
+		if timezone != "" {
+			var err error
+			if loc, err = loadLocation(timezone); err != nil {
+				log.Printf("Schedule: Skipping event '%s' - %v", eventNameES, err)
+				continue
+			}
+		}
+
+		// Build full datetime from date + time
+		startDateTime, err := parseDateTime(currentDate, startTime24, loc)
 		if err != nil {
 			log.Printf("Schedule: Skipping event '%s' - invalid start time: %v", eventNameES, err)
 			continue
@@ -387,8 +988,14 @@ func (c *Client) ReadScheduleSheet(ctx context.Context, weddingYear int) ([]*Sch
 
 		var endTimeISO *string
 		if endTime24 != "" {
-			endDT, err := parseDateTime(currentDate, endTime24, copanLoc)
-			if err == nil {
+			endDT, err := parseDateTime(currentDate, endTime24, loc)
+			switch {
+			case err != nil:
+			case endDT.Before(startDateTime):
+				// Rows only have a day header's date, so an end past midnight
+				// can't be told apart from a typo
+				log.Printf("Schedule: Dropping end time of event '%s' - %s is before the start %s", eventNameES, endTimeRaw, startTimeRaw)
+			default:
 				s := endDT.Format(time.RFC3339)
 				endTimeISO = &s
 			}
@@ -404,19 +1011,19 @@ func (c *Client) ReadScheduleSheet(ctx context.Context, weddingYear int) ([]*Sch
 			DescriptionES: descriptionES,
 			DescriptionEN: descriptionEN,
 			DescriptionCA: descriptionCA,
+			Timezone:      loc.String(),
 		}
 
 		events = append(events, event)
 	}
 
-	log.Printf("Read %d public schedule events from Google Sheet 'Schedule'", len(events))
-	return events, nil
+	return events
 }
 
 // parseDateTime combines a date string and time string into a time.Time in the given location
-func parseDateTime(dateStr, timeStr string, loc *time.Location) (time.Time, error) {
-	// dateStr is "2026-12-19", timeStr is "16:00"
-	combined := dateStr + "T" + timeStr + ":00"
+func parseDateTime(date time.Time, timeStr string, loc *time.Location) (time.Time, error) {
+	// timeStr is "16:00"
+	combined := date.Format("2006-01-02") + "T" + timeStr + ":00"
 	return time.ParseInLocation("2006-01-02T15:04:05", combined, loc)
 }
 
@@ -0,0 +1,155 @@
+package sheets
+
+import (
+	"errors"
+	"testing"
+)
+
+// codeColumn builds a code column read, header included
+func codeColumn(codes ...string) [][]interface{} {
+	values := [][]interface{}{{"Invite Code"}}
+	for _, code := range codes {
+		if code == "" {
+			values = append(values, []interface{}{}) // The API drops empty trailing cells
+			continue
+		}
+		values = append(values, []interface{}{code})
+	}
+	return values
+}
+
+func TestResolveRow(t *testing.T) {
+	row := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		name     string
+		codes    [][]interface{}
+		code     string
+		sheetRow *int64
+		want     int64
+		wantErr  error
+	}{
+		{
+			name:     "row still matches",
+			codes:    codeColumn("ANA1", "BOB1", "CAT1"),
+			code:     "BOB1",
+			sheetRow: row(3),
+			want:     3,
+		},
+		{
+			name:     "sheet sorted since the last read",
+			codes:    codeColumn("CAT1", "ANA1", "BOB1"),
+			code:     "BOB1",
+			sheetRow: row(3),
+			want:     4,
+		},
+		{
+			name:     "row inserted above",
+			codes:    codeColumn("", "ANA1", "BOB1"),
+			code:     "ANA1",
+			sheetRow: row(2),
+			want:     3,
+		},
+		{
+			name:     "whitespace around the code",
+			codes:    [][]interface{}{{"Invite Code"}, {" ANA1 "}},
+			code:     "ANA1",
+			sheetRow: row(2),
+			want:     2,
+		},
+		{
+			name:  "no row recorded",
+			codes: codeColumn("ANA1", "BOB1"),
+			code:  "BOB1",
+			want:  3,
+		},
+		{
+			name:     "code twice, one at the recorded row",
+			codes:    codeColumn("ANA1", "BOB1", "ANA1"),
+			code:     "ANA1",
+			sheetRow: row(2),
+			wantErr:  ErrDuplicateInviteCode,
+		},
+		{
+			name:     "code twice, neither at the recorded row",
+			codes:    codeColumn("CAT1", "ANA1", "ANA1"),
+			code:     "ANA1",
+			sheetRow: row(2),
+			wantErr:  ErrDuplicateInviteCode,
+		},
+		{
+			name:     "code missing",
+			codes:    codeColumn("ANA1", "CAT1"),
+			code:     "BOB1",
+			sheetRow: row(3),
+			wantErr:  ErrInviteNotFound,
+		},
+		{
+			name:     "only the header matches",
+			codes:    codeColumn("ANA1"),
+			code:     "Invite Code",
+			sheetRow: row(1),
+			wantErr:  ErrInviteNotFound,
+		},
+		{
+			name:     "empty sheet",
+			codes:    nil,
+			code:     "ANA1",
+			sheetRow: row(2),
+			wantErr:  ErrInviteNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveRow(tt.codes, tt.code, tt.sheetRow)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || got != 0 {
+					t.Fatalf("resolveRow() = %d, %v, want 0, %v", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveRow(): %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveRow() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCodeRowDuplicateNamesRows(t *testing.T) {
+	_, err := findCodeRow(codeColumn("ANA1", "BOB1", "ANA1"), "ANA1")
+	if want := "invite code in more than one row of the guest list: rows 2 and 4"; err == nil || err.Error() != want {
+		t.Errorf("findCodeRow() error = %v, want %q", err, want)
+	}
+}
+
+func TestParseScheduleRowsEndTime(t *testing.T) {
+	cal, err := NewCalendar(2026, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := [][]interface{}{
+		{"", "", "", "Friday Dec 18"},
+		{"4:00 PM", "6:00 PM", "TRUE", "Ceremonia"},
+		{"10:00 PM", "2:00 AM", "TRUE", "Fiesta"},
+		{"11:00 PM", "", "TRUE", "Cena"},
+	}
+
+	events := parseScheduleRows(values, cal)
+	if len(events) != 3 {
+		t.Fatalf("parseScheduleRows() returned %d events, want 3", len(events))
+	}
+	want := []string{"2026-12-18T18:00:00Z", "", ""}
+	for i, event := range events {
+		var got string
+		if event.EndTime != nil {
+			got = *event.EndTime
+		}
+		if got != want[i] {
+			t.Errorf("%s end time = %q, want %q", event.EventNameES, got, want[i])
+		}
+	}
+}
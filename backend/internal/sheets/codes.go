@@ -0,0 +1,161 @@
+package sheets
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/casassg/wedding/backend/internal/store"
+	"github.com/pkg/errors"
+)
+
+// Invite code defaults. The alphabet leaves out characters that are easy to
+// mix up when read aloud or typed from paper (0/O, 1/I/L, 2/Z, 5/S, 8/B).
+const (
+	DefaultCodeAlphabet = "ACDEFGHJKMNPQRTUVWXY34679"
+	DefaultCodeLength   = 6
+)
+
+// minCodeSpace is the minimum number of possible codes, so they can't be guessed
+const minCodeSpace = 1 << 24
+
+// codeAttempts is how many random codes are tried before giving up on a row
+const codeAttempts = 20
+
+// CodeAssignment is a guest list row that has a name but no invite code
+type CodeAssignment struct {
+	Row         int64  `json:"row"` // Sheet row, or position in a YAML guest list
+	Name        string `json:"name"`
+	InviteCode  string `json:"invite_code"`           // Empty until generated
+	Placeholder bool   `json:"placeholder,omitempty"` // Dry run only, the real run generates a different code
+}
+
+// CodeGenerator creates random invite codes from an alphabet
+type CodeGenerator struct {
+	alphabet []rune
+	length   int
+}
+
+// NewCodeGenerator creates a generator for codes of length characters.
+// Returns an error if the alphabet has duplicates or characters that don't
+// survive in a URL, or if it allows too few codes to be unguessable.
+func NewCodeGenerator(alphabet string, length int) (*CodeGenerator, error) {
+	runes := []rune(alphabet)
+	seen := make(map[rune]bool, len(runes))
+	for _, r := range runes {
+		if seen[r] {
+			return nil, fmt.Errorf("invite code alphabet has %q more than once", r)
+		}
+		if r <= ' ' || strings.ContainsRune("/?#%&+", r) {
+			return nil, fmt.Errorf("invite code alphabet can't contain %q", r)
+		}
+		seen[r] = true
+	}
+	if len(runes) < 2 || length < 1 || math.Pow(float64(len(runes)), float64(length)) < minCodeSpace {
+		return nil, fmt.Errorf("%d characters of a %d letter alphabet allow too few codes, use a longer code or alphabet",
+			length, len(runes))
+	}
+	return &CodeGenerator{alphabet: runes, length: length}, nil
+}
+
+// Generate returns a random code for which taken reports false
+func (g *CodeGenerator) Generate(taken func(code string) (bool, error)) (string, error) {
+	size := big.NewInt(int64(len(g.alphabet)))
+	for range codeAttempts {
+		code := make([]rune, g.length)
+		for i := range code {
+			n, err := rand.Int(rand.Reader, size)
+			if err != nil {
+				return "", fmt.Errorf("failed to read random bytes: %w", err)
+			}
+			code[i] = g.alphabet[n.Int64()]
+		}
+		exists, err := taken(string(code))
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return string(code), nil
+		}
+	}
+	return "", fmt.Errorf("no free invite code after %d attempts, use a longer code", codeAttempts)
+}
+
+// SetCodeGenerator makes every sync cycle assign codes to rows that have a
+// name but no invite code before reading invites. Nil disables it.
+func (s *Syncer) SetCodeGenerator(gen *CodeGenerator) {
+	s.codes = gen
+}
+
+// AssignInviteCodes generates codes for rows with a name but no invite code
+// and, unless dryRun is set, writes them all back in a single batch. Codes
+// are unique across the guest list, the database and pending deletions. A
+// dry run's codes are placeholders, since they're random and not kept.
+func (s *Syncer) AssignInviteCodes(ctx context.Context, gen *CodeGenerator, dryRun bool) ([]*CodeAssignment, error) {
+	rows, err := s.source.ReadGuestRows(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read guest list")
+	}
+	return s.assignCodes(ctx, gen, rows, dryRun)
+}
+
+// assignCodes is AssignInviteCodes for rows already read with ReadGuestRows.
+// Unless dryRun is set, the rows that got a code are updated with it.
+func (s *Syncer) assignCodes(ctx context.Context, gen *CodeGenerator, rows []*store.UpsertInviteParams, dryRun bool) ([]*CodeAssignment, error) {
+	// Rows that already have a code may not be in the database yet
+	var codes []*CodeAssignment
+	var uncoded []*store.UpsertInviteParams // Row of each of codes
+	used := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		if row.InviteCode != "" {
+			used[row.InviteCode] = true
+			continue
+		}
+		codes = append(codes, &CodeAssignment{Row: *row.SheetRow, Name: row.Name})
+		uncoded = append(uncoded, row)
+	}
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	taken := func(code string) (bool, error) {
+		if used[code] {
+			return true, nil
+		}
+		if _, err := s.store.GetInviteByInviteCode(ctx, code); !errors.Is(err, sql.ErrNoRows) {
+			return err == nil, err
+		}
+		if _, err := s.store.GetInviteDeletion(ctx, code); !errors.Is(err, sql.ErrNoRows) {
+			return err == nil, err
+		}
+		return false, nil
+	}
+
+	for _, code := range codes {
+		inviteCode, err := gen.Generate(taken)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to generate code for row %d", code.Row)
+		}
+		code.InviteCode = inviteCode
+		code.Placeholder = dryRun
+		used[inviteCode] = true
+	}
+
+	if dryRun {
+		return codes, nil
+	}
+	if err := s.source.WriteInviteCodes(ctx, codes); err != nil {
+		return nil, errors.Wrap(err, "failed to write invite codes")
+	}
+	for i, code := range codes {
+		uncoded[i].InviteCode = code.InviteCode
+	}
+
+	log.Printf("Assigned %d new invite code(s)", len(codes))
+	return codes, nil
+}
@@ -0,0 +1,253 @@
+package sheets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Guest sheet column keys. The header row is resolved into these keys so the
+// rest of the client never depends on a column's position.
+const (
+	ColName            = "name"
+	ColPartner         = "partner"
+	ColKids            = "kids"
+	ColInviteCode      = "invite_code"
+	ColAdultsConfirmed = "adults_confirmed"
+	ColKidsConfirmed   = "kids_confirmed"
+	ColDietary         = "dietary"
+	ColMessage         = "message"
+	ColSong            = "song"
+	ColResponseAt      = "response_at"
+	ColRSVPDeadline    = "rsvp_deadline"
+)
+
+// Events tab column keys, one row per event besides the wedding
+const (
+	ColEventKey      = "key"
+	ColEventNameES   = "name_es"
+	ColEventNameEN   = "name_en"
+	ColEventNameCA   = "name_ca"
+	ColEventStart    = "start"
+	ColEventTimezone = "timezone"
+	ColEventLocation = "location"
+	ColEventTab      = "tab"
+)
+
+// ColAdults is the adults limit column of an event's tab. Its other columns
+// share the Guests sheet keys.
+const ColAdults = "adults"
+
+// columnSpec describes a logical column and the header names it can appear as
+type columnSpec struct {
+	Key      string
+	Aliases  []string
+	Required bool
+}
+
+// guestColumns lists the Guests sheet columns in their historical order (A..N),
+// followed by optional columns.
+// Aliases are matched case-insensitively after trimming whitespace.
+var guestColumns = []columnSpec{
+	{Key: ColName, Aliases: []string{"Name", "Nombre", "Nom"}, Required: true},
+	{Key: ColPartner, Aliases: []string{"Parella", "Pareja", "Plus one", "Plus-one"}, Required: true},
+	{Key: ColKids, Aliases: []string{"Fills", "Hijos", "Kids"}, Required: true},
+	{Key: ColInviteCode, Aliases: []string{"Invite Code", "Invite", "Code"}, Required: true},
+	{Key: ColAdultsConfirmed, Aliases: []string{"Adults confirmed"}, Required: true},
+	{Key: ColKidsConfirmed, Aliases: []string{"Kids confirmed"}, Required: true},
+	{Key: ColDietary, Aliases: []string{"Dietary", "Dietary info"}, Required: true},
+	{Key: ColMessage, Aliases: []string{"Message for us", "Message"}, Required: true},
+	{Key: ColSong, Aliases: []string{"Song request", "Song"}, Required: true},
+	{Key: ColResponseAt, Aliases: []string{"Updated At", "Response At"}, Required: true},
+	{Key: ColRSVPDeadline, Aliases: []string{"RSVP deadline", "Deadline", "Fecha límite", "Data límit"}},
+}
+
+// eventColumns lists the Events tab columns. Tab defaults to the key.
+var eventColumns = []columnSpec{
+	{Key: ColEventKey, Aliases: []string{"Key", "Event key", "ID"}, Required: true},
+	{Key: ColEventNameES, Aliases: []string{"Name ES", "Evento", "Nombre", "Name"}, Required: true},
+	{Key: ColEventNameEN, Aliases: []string{"Name EN", "Event name"}},
+	{Key: ColEventNameCA, Aliases: []string{"Name CA", "Nom", "Nombre catalan"}},
+	{Key: ColEventStart, Aliases: []string{"Start", "Start Time", "Date", "Fecha"}},
+	{Key: ColEventTimezone, Aliases: []string{"Timezone", "Time zone"}},
+	{Key: ColEventLocation, Aliases: []string{"Location", "Lugar"}},
+	{Key: ColEventTab, Aliases: []string{"Tab", "Sheet"}},
+}
+
+// eventTabColumns lists the columns of an event's own tab, one row per
+// invite invited to it. Without limits the invite's own apply.
+var eventTabColumns = []columnSpec{
+	{Key: ColInviteCode, Aliases: []string{"Invite Code", "Invite", "Code"}, Required: true},
+	{Key: ColAdults, Aliases: []string{"Adults", "Max adults", "Adultos"}},
+	{Key: ColKids, Aliases: []string{"Kids", "Max kids", "Hijos", "Fills"}},
+	{Key: ColAdultsConfirmed, Aliases: []string{"Adults confirmed"}, Required: true},
+	{Key: ColKidsConfirmed, Aliases: []string{"Kids confirmed"}, Required: true},
+	{Key: ColResponseAt, Aliases: []string{"Updated At", "Response At"}, Required: true},
+}
+
+// eventRSVPColumns are the columns written back to an event's tab for each response
+var eventRSVPColumns = []string{
+	ColAdultsConfirmed,
+	ColKidsConfirmed,
+	ColResponseAt,
+}
+
+// rsvpColumns are the columns written back to the sheet for each response
+var rsvpColumns = []string{
+	ColAdultsConfirmed,
+	ColKidsConfirmed,
+	ColDietary,
+	ColMessage,
+	ColSong,
+	ColResponseAt,
+}
+
+// inviteColumns are the invite master data columns written by the admin API
+var inviteColumns = []string{
+	ColName,
+	ColPartner,
+	ColKids,
+}
+
+// ColumnMap maps logical column keys to zero-based column indices
+type ColumnMap map[string]int
+
+// resolveColumns matches a header row against the column specs.
+// extraAliases adds header names per key on top of the built-in ones.
+// Returns an error listing every required column that could not be found.
+func resolveColumns(header []interface{}, specs []columnSpec, extraAliases map[string][]string) (ColumnMap, error) {
+	positions := make(map[string]int, len(header))
+	for i, cell := range header {
+		name := normalizeHeader(toString(cell))
+		if name == "" {
+			continue
+		}
+		// First occurrence wins so a duplicated header can't shadow the original
+		if _, exists := positions[name]; !exists {
+			positions[name] = i
+		}
+	}
+
+	cols := make(ColumnMap, len(specs))
+	var missing []string
+	for _, spec := range specs {
+		aliases := append(append([]string{}, extraAliases[spec.Key]...), spec.Aliases...)
+		for _, alias := range aliases {
+			if idx, ok := positions[normalizeHeader(alias)]; ok {
+				cols[spec.Key] = idx
+				break
+			}
+		}
+		if _, ok := cols[spec.Key]; !ok && spec.Required {
+			missing = append(missing, fmt.Sprintf("%s (%s)", spec.Key, strings.Join(aliases, "/")))
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required header(s): %s", strings.Join(missing, ", "))
+	}
+
+	return cols, nil
+}
+
+// Has reports whether the column was found in the header row
+func (m ColumnMap) Has(key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// Cell returns the value of a column in a row, or nil when the row is short
+func (m ColumnMap) Cell(row []interface{}, key string) interface{} {
+	idx, ok := m[key]
+	if !ok || idx >= len(row) {
+		return nil
+	}
+	return row[idx]
+}
+
+// Letter returns the A1 column letter for a key
+func (m ColumnMap) Letter(key string) string {
+	return columnLetter(m[key])
+}
+
+// Span returns the lowest and highest column index among the given keys
+func (m ColumnMap) Span(keys []string) (first, last int) {
+	first, last = -1, -1
+	for _, key := range keys {
+		idx, ok := m[key]
+		if !ok {
+			continue
+		}
+		if first == -1 || idx < first {
+			first = idx
+		}
+		if idx > last {
+			last = idx
+		}
+	}
+	return first, last
+}
+
+// ResolveGuestColumns resolves the Guests sheet header row
+func ResolveGuestColumns(header []interface{}, extraAliases map[string][]string) (ColumnMap, error) {
+	return resolveColumns(header, guestColumns, extraAliases)
+}
+
+// GuestColumnKeys returns the Guests sheet column keys in their default order
+func GuestColumnKeys() []string {
+	keys := make([]string, 0, len(guestColumns))
+	for _, spec := range guestColumns {
+		keys = append(keys, spec.Key)
+	}
+	return keys
+}
+
+// ParseColumnAliases parses extra header aliases from a string like
+// "partner=Plus one|Pareja;invite_code=Code".
+func ParseColumnAliases(s string) (map[string][]string, error) {
+	aliases := make(map[string][]string)
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, names, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid column alias %q, expected key=Header|Other", entry)
+		}
+		key = strings.TrimSpace(key)
+		if !isGuestColumn(key) {
+			return nil, fmt.Errorf("unknown column %q in alias %q", key, entry)
+		}
+		for _, name := range strings.Split(names, "|") {
+			if name = strings.TrimSpace(name); name != "" {
+				aliases[key] = append(aliases[key], name)
+			}
+		}
+	}
+	return aliases, nil
+}
+
+// isGuestColumn reports whether key is a known Guests sheet column
+func isGuestColumn(key string) bool {
+	for _, spec := range guestColumns {
+		if spec.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeHeader lowercases a header and collapses internal whitespace
+func normalizeHeader(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// columnLetter converts a zero-based column index to A1 notation (0 -> A, 26 -> AA)
+func columnLetter(idx int) string {
+	letters := ""
+	for idx >= 0 {
+		letters = string(rune('A'+idx%26)) + letters
+		idx = idx/26 - 1
+	}
+	return letters
+}
@@ -0,0 +1,197 @@
+package sheets
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// guestHeader is a Guests sheet header row in the historical order
+func guestHeader() []interface{} {
+	return []interface{}{
+		"Name", "Parella", "Fills", "Invite Code", "Adults confirmed", "Kids confirmed",
+		"Dietary", "Message for us", "Song request", "Updated At",
+	}
+}
+
+func TestResolveColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  []interface{}
+		aliases map[string][]string
+		want    map[string]int // Only the keys checked
+		wantErr string         // Substring of the error
+	}{
+		{
+			name:   "historical order",
+			header: guestHeader(),
+			want:   map[string]int{ColName: 0, ColInviteCode: 3, ColResponseAt: 9},
+		},
+		{
+			name: "other aliases, case and whitespace",
+			header: []interface{}{
+				"  RESPONSE at", "nombre", "Plus   one", "hijos", "CODE", "adults CONFIRMED", "Kids confirmed",
+				"Dietary info", "Message", "Song", "Fecha límite",
+			},
+			want: map[string]int{ColResponseAt: 0, ColName: 1, ColPartner: 2, ColInviteCode: 4, ColRSVPDeadline: 10},
+		},
+		{
+			name:   "earlier alias wins",
+			header: append(guestHeader(), "Invite"),
+			want:   map[string]int{ColInviteCode: 3},
+		},
+		{
+			name:   "duplicated header keeps the first",
+			header: append(guestHeader(), "Name", "Invite Code"),
+			want:   map[string]int{ColName: 0, ColInviteCode: 3},
+		},
+		{
+			name:    "extra alias",
+			header:  []interface{}{"Guest", "Parella", "Fills", "Invite Code", "Adults confirmed", "Kids confirmed", "Dietary", "Message", "Song", "Updated At"},
+			aliases: map[string][]string{ColName: {"guest"}},
+			want:    map[string]int{ColName: 0},
+		},
+		{
+			name:    "extra alias before the built-in ones",
+			header:  append(guestHeader(), "Who"),
+			aliases: map[string][]string{ColName: {"Who"}},
+			want:    map[string]int{ColName: 10},
+		},
+		{
+			name:   "optional column missing",
+			header: guestHeader(),
+			want:   map[string]int{ColRSVPDeadline: -1},
+		},
+		{
+			name:    "required column missing",
+			header:  guestHeader()[1:],
+			wantErr: "missing required header(s): name (Name/Nombre/Nom)",
+		},
+		{
+			name:    "every missing column listed",
+			header:  []interface{}{"Name", "", nil, "Invite Code"},
+			wantErr: "partner (Parella/Pareja/Plus one/Plus-one), kids (",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, err := resolveColumns(tt.header, guestColumns, tt.aliases)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveColumns() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveColumns(): %v", err)
+			}
+			for key, want := range tt.want {
+				got, ok := cols[key]
+				if !ok {
+					got = -1
+				}
+				if got != want {
+					t.Errorf("column %s = %d, want %d", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseColumnAliases(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    map[string][]string
+		wantErr bool
+	}{
+		{value: "", want: map[string][]string{}},
+		{value: "name=Guest", want: map[string][]string{ColName: {"Guest"}}},
+		{
+			value: " partner = Plus one | Pareja ;; invite_code=Code;",
+			want:  map[string][]string{ColPartner: {"Plus one", "Pareja"}, ColInviteCode: {"Code"}},
+		},
+		{value: "name=Guest;name=Who", want: map[string][]string{ColName: {"Guest", "Who"}}},
+		{value: "name=", want: map[string][]string{}},
+		{value: "name", wantErr: true},
+		{value: "name:Guest", wantErr: true},
+		{value: "Name=Guest", wantErr: true},
+		{value: "email=Email", wantErr: true},
+		{value: "name=Guest;adults=Adults", wantErr: true}, // An event tab column
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseColumnAliases(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseColumnAliases(%q) = %v, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseColumnAliases(%q): %v", tt.value, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseColumnAliases(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnLetter(t *testing.T) {
+	tests := []struct {
+		idx  int
+		want string
+	}{
+		{0, "A"},
+		{13, "N"},
+		{25, "Z"},
+		{26, "AA"},
+		{27, "AB"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+
+	for _, tt := range tests {
+		if got := columnLetter(tt.idx); got != tt.want {
+			t.Errorf("columnLetter(%d) = %q, want %q", tt.idx, got, tt.want)
+		}
+	}
+}
+
+func TestColumnMapSpan(t *testing.T) {
+	// Columns moved past Z, with a gap
+	cols := ColumnMap{ColAdultsConfirmed: 27, ColKidsConfirmed: 25, ColResponseAt: 30, ColSong: 3}
+
+	tests := []struct {
+		name        string
+		keys        []string
+		first, last int
+		letters     string
+	}{
+		{name: "past Z", keys: eventRSVPColumns, first: 25, last: 30, letters: "Z:AE"},
+		{name: "missing keys skipped", keys: []string{ColDietary, ColResponseAt, ColMessage}, first: 30, last: 30, letters: "AE:AE"},
+		{name: "none found", keys: []string{ColDietary}, first: -1, last: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, last := cols.Span(tt.keys)
+			if first != tt.first || last != tt.last {
+				t.Fatalf("Span() = %d, %d, want %d, %d", first, last, tt.first, tt.last)
+			}
+			if first < 0 {
+				return
+			}
+			if got := columnLetter(first) + ":" + columnLetter(last); got != tt.letters {
+				t.Errorf("Span() letters = %s, want %s", got, tt.letters)
+			}
+		})
+	}
+	if got := cols.Letter(ColAdultsConfirmed); got != "AB" {
+		t.Errorf("Letter(%s) = %s, want AB", ColAdultsConfirmed, got)
+	}
+}
@@ -0,0 +1,646 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/store"
+	"github.com/pkg/errors"
+)
+
+// SyncDiff lists what a sync cycle would change in the database and the
+// guest list, in the order the cycle applies it
+type SyncDiff struct {
+	CodesAssigned      []*CodeAssignment `json:"codes_assigned,omitempty"` // Only when code assignment is enabled
+	InvitesAdded       []*InviteDiff     `json:"invites_added"`
+	InvitesChanged     []*InviteDiff     `json:"invites_changed"`
+	InvitesRemoved     []*InviteDiff     `json:"invites_removed"`  // In the database but not the sheet, see SetRemovedInvites
+	InvitesToWrite     []*InviteDiff     `json:"invites_to_write"` // Admin API creations and edits
+	RSVPsToPush        []*RSVPPush       `json:"rsvps_to_push"`
+	RowsToDelete       []*InviteDiff     `json:"rows_to_delete"` // Invites deleted through the admin API
+	ScheduleAdded      []*ScheduleDiff   `json:"schedule_added"`
+	ScheduleChanged    []*ScheduleDiff   `json:"schedule_changed"`
+	ScheduleRemoved    []*ScheduleDiff   `json:"schedule_removed"`
+	EventsAdded        []*EventDiff      `json:"events_added"`
+	EventsChanged      []*EventDiff      `json:"events_changed"`
+	EventsRemoved      []*EventDiff      `json:"events_removed"`
+	InvitationsAdded   []*InvitationDiff `json:"invitations_added"`
+	InvitationsChanged []*InvitationDiff `json:"invitations_changed"`
+	InvitationsRemoved []*InvitationDiff `json:"invitations_removed"`
+	EventRSVPsToPush   []*EventRSVPPush  `json:"event_rsvps_to_push"`
+}
+
+// Empty reports whether the sync cycle would change nothing
+func (d *SyncDiff) Empty() bool {
+	return len(d.CodesAssigned)+len(d.InvitesAdded)+len(d.InvitesChanged)+len(d.InvitesRemoved)+
+		len(d.InvitesToWrite)+len(d.RSVPsToPush)+len(d.RowsToDelete)+
+		len(d.ScheduleAdded)+len(d.ScheduleChanged)+len(d.ScheduleRemoved)+
+		len(d.EventsAdded)+len(d.EventsChanged)+len(d.EventsRemoved)+
+		len(d.InvitationsAdded)+len(d.InvitationsChanged)+len(d.InvitationsRemoved)+
+		len(d.EventRSVPsToPush) == 0
+}
+
+// InviteDiff is an invite that would be added, changed, written or removed
+type InviteDiff struct {
+	InviteCode string         `json:"invite_code"`
+	Name       string         `json:"name"`
+	SheetRow   *int64         `json:"sheet_row,omitempty"`
+	Changes    []*FieldChange `json:"changes,omitempty"`
+	Note       string         `json:"note,omitempty"` // Why sync won't apply it, or how
+}
+
+// RSVPPush is an RSVP answer that would be written to the guest list
+type RSVPPush struct {
+	InviteCode      string `json:"invite_code"`
+	Name            string `json:"name"`
+	SheetRow        *int64 `json:"sheet_row,omitempty"`
+	ConfirmedAdults int64  `json:"confirmed_adults"`
+	ConfirmedKids   int64  `json:"confirmed_kids"`
+	ResponseAt      string `json:"response_at"` // ISO8601 UTC
+	Note            string `json:"note,omitempty"`
+}
+
+// ScheduleDiff is a schedule event that would be added, changed or removed
+type ScheduleDiff struct {
+	StartTime string         `json:"start_time"`
+	Name      string         `json:"name"` // Spanish name
+	Changes   []*FieldChange `json:"changes,omitempty"`
+}
+
+// EventDiff is an event besides the wedding that would be added, changed
+// or removed
+type EventDiff struct {
+	EventKey string         `json:"event_key"`
+	Name     string         `json:"name"` // Spanish name
+	Changes  []*FieldChange `json:"changes,omitempty"`
+	Note     string         `json:"note,omitempty"`
+}
+
+// InvitationDiff is an invite's invitation to an event that would be added,
+// changed or removed
+type InvitationDiff struct {
+	EventKey   string         `json:"event_key"`
+	InviteCode string         `json:"invite_code"`
+	SheetRow   *int64         `json:"sheet_row,omitempty"` // Row in the event's tab
+	Changes    []*FieldChange `json:"changes,omitempty"`
+	Note       string         `json:"note,omitempty"`
+}
+
+// EventRSVPPush is an event answer that would be written to the event's tab
+type EventRSVPPush struct {
+	EventKey        string `json:"event_key"`
+	InviteCode      string `json:"invite_code"`
+	SheetRow        *int64 `json:"sheet_row,omitempty"`
+	ConfirmedAdults int64  `json:"confirmed_adults"`
+	ConfirmedKids   int64  `json:"confirmed_kids"`
+	ResponseAt      string `json:"response_at"` // ISO8601 UTC
+	Note            string `json:"note,omitempty"`
+}
+
+// FieldChange is a single value that would change
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Diff computes what SyncOnce would do without writing to the database or
+// the guest list. Code assignment is included when a generator is set.
+func (s *Syncer) Diff(ctx context.Context) (*SyncDiff, error) {
+	if !s.source.IsConfigured() {
+		return nil, errors.New("guest list source not configured")
+	}
+
+	diff := &SyncDiff{
+		InvitesAdded:       []*InviteDiff{},
+		InvitesChanged:     []*InviteDiff{},
+		InvitesRemoved:     []*InviteDiff{},
+		InvitesToWrite:     []*InviteDiff{},
+		RSVPsToPush:        []*RSVPPush{},
+		RowsToDelete:       []*InviteDiff{},
+		ScheduleAdded:      []*ScheduleDiff{},
+		ScheduleChanged:    []*ScheduleDiff{},
+		ScheduleRemoved:    []*ScheduleDiff{},
+		EventsAdded:        []*EventDiff{},
+		EventsChanged:      []*EventDiff{},
+		EventsRemoved:      []*EventDiff{},
+		InvitationsAdded:   []*InvitationDiff{},
+		InvitationsChanged: []*InvitationDiff{},
+		InvitationsRemoved: []*InvitationDiff{},
+		EventRSVPsToPush:   []*EventRSVPPush{},
+	}
+
+	rows, err := s.source.ReadGuestRows(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read invites")
+	}
+
+	if s.codes != nil {
+		codes, err := s.assignCodes(ctx, s.codes, rows, true)
+		if err != nil {
+			return nil, err
+		}
+		diff.CodesAssigned = codes
+	}
+	rows = codedRows(rows)
+
+	invites, err := s.store.ListInvites(ctx, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list invites")
+	}
+	deletions, err := s.store.ListInviteDeletions(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list invite deletions")
+	}
+	plan := planInvites(rows, invites, deletions, s.removed)
+	diffInvites(diff, plan, invites)
+
+	if err := s.diffToSheet(ctx, diff); err != nil {
+		return nil, errors.Wrap(err, "failed to diff pending sheet writes")
+	}
+	if err := s.diffSchedule(ctx, diff); err != nil {
+		return nil, errors.Wrap(err, "failed to diff schedule")
+	}
+	if err := s.diffEvents(ctx, diff, plan.limits(invites)); err != nil {
+		return nil, errors.Wrap(err, "failed to diff events")
+	}
+	if err := s.diffEventRSVPs(ctx, diff); err != nil {
+		return nil, errors.Wrap(err, "failed to diff pending event answers")
+	}
+
+	return diff, nil
+}
+
+// diffInvites lists what applying plan to invites changes, see SyncFromSheet
+func diffInvites(diff *SyncDiff, plan *invitePlan, invites []*store.Invite) {
+	existing := make(map[string]*store.Invite, len(invites))
+	for _, invite := range invites {
+		existing[invite.InviteCode] = invite
+	}
+
+	for _, row := range plan.upserts {
+		old, ok := existing[row.InviteCode]
+		if !ok {
+			diff.InvitesAdded = append(diff.InvitesAdded, &InviteDiff{
+				InviteCode: row.InviteCode,
+				Name:       row.Name,
+				SheetRow:   row.SheetRow,
+			})
+			continue
+		}
+
+		changes := inviteChanges(old, row)
+		if len(changes) == 0 {
+			continue
+		}
+
+		// Disabled invites are re-enabled regardless, see EnableInvite
+		var note string
+		switch {
+		case upsertApplies(old):
+		case old.LocalChangedAt != nil:
+			note = "not applied, admin edit not written to the sheet yet"
+		default:
+			note = "not applied, RSVP not written to the sheet yet"
+		}
+		if note != "" && old.Disabled() {
+			note = "re-enabled, other changes " + note
+		}
+
+		diff.InvitesChanged = append(diff.InvitesChanged, &InviteDiff{
+			InviteCode: row.InviteCode,
+			Name:       row.Name,
+			SheetRow:   row.SheetRow,
+			Changes:    changes,
+			Note:       note,
+		})
+	}
+
+	// See removeInvites
+	note := map[string]string{
+		RemovedInvitesDisable: "disable, answers are kept",
+		RemovedInvitesKeep:    "keep",
+		RemovedInvitesDelete:  "delete with its guests",
+	}[plan.policy]
+	if plan.refused {
+		note = fmt.Sprintf("not deleted, refusing to delete %d of %d invites at once", len(plan.removed), len(invites))
+	}
+	for _, invite := range plan.removed {
+		diff.InvitesRemoved = append(diff.InvitesRemoved, &InviteDiff{
+			InviteCode: invite.InviteCode,
+			Name:       invite.Name,
+			SheetRow:   invite.SheetRow,
+			Note:       note,
+		})
+	}
+}
+
+// inviteChanges lists the fields UpsertInvite would change
+func inviteChanges(old *store.Invite, row *store.UpsertInviteParams) []*FieldChange {
+	var changes []*FieldChange
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, &FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	add("name", old.Name, row.Name)
+	add("max_adults", strconv.FormatInt(old.MaxAdults, 10), strconv.FormatInt(row.MaxAdults, 10))
+	add("max_kids", strconv.FormatInt(old.MaxKids, 10), strconv.FormatInt(row.MaxKids, 10))
+	add("confirmed_adults", strconv.FormatInt(old.ConfirmedAdults, 10), strconv.FormatInt(row.ConfirmedAdults, 10))
+	add("sheet_row", formatRow(old.SheetRow), formatRow(row.SheetRow))
+	add("rsvp_deadline", formatDiffTime(old.RsvpDeadline), formatDiffTime(row.RsvpDeadline))
+	add("disabled_at", formatDiffTime(old.DisabledAt), "") // Back in the sheet
+	return changes
+}
+
+// diffToSheet lists the admin changes and RSVPs SyncToSheet would write
+func (s *Syncer) diffToSheet(ctx context.Context, diff *SyncDiff) error {
+	edits, err := s.store.GetPendingInviteEdits(ctx)
+	if err != nil {
+		return err
+	}
+	for _, invite := range edits {
+		note := "append a new row"
+		if invite.SheetRow != nil {
+			note = "update the name and limits"
+		}
+		diff.InvitesToWrite = append(diff.InvitesToWrite, &InviteDiff{
+			InviteCode: invite.InviteCode,
+			Name:       invite.Name,
+			SheetRow:   invite.SheetRow,
+			Note:       note,
+		})
+	}
+
+	invites, err := s.store.GetPendingSyncInvites(ctx)
+	if err != nil {
+		return err
+	}
+	for _, invite := range invites {
+		push := &RSVPPush{
+			InviteCode:      invite.InviteCode,
+			Name:            invite.Name,
+			SheetRow:        invite.SheetRow,
+			ConfirmedAdults: invite.ConfirmedAdults,
+			ConfirmedKids:   invite.ConfirmedKids,
+			ResponseAt:      formatDiffTime(invite.ResponseAt),
+		}
+		if invite.SheetRow == nil && invite.LocalChangedAt == nil {
+			push.Note = "skipped, no sheet row"
+		}
+		diff.RSVPsToPush = append(diff.RSVPsToPush, push)
+	}
+
+	deletions, err := s.store.ListInviteDeletions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, deletion := range deletions {
+		diff.RowsToDelete = append(diff.RowsToDelete, &InviteDiff{
+			InviteCode: deletion.InviteCode,
+			SheetRow:   &deletion.SheetRow,
+		})
+	}
+
+	return nil
+}
+
+// diffSchedule compares the sheet's schedule with the database, matching
+// events like matchSchedule
+func (s *Syncer) diffSchedule(ctx context.Context, diff *SyncDiff) error {
+	rows, err := s.source.ReadSchedule(ctx)
+	if err != nil {
+		return err
+	}
+	if rows == nil {
+		return nil // Source has no schedule, sync leaves it alone
+	}
+
+	events, err := s.store.GetScheduleEvents(ctx)
+	if err != nil {
+		return err
+	}
+
+	matched := make([]bool, len(events))
+	for r, i := range matchSchedule(rows, events) {
+		row := rows[r]
+		if i == -1 {
+			diff.ScheduleAdded = append(diff.ScheduleAdded, &ScheduleDiff{StartTime: row.StartTime, Name: row.EventNameES})
+			continue
+		}
+		matched[i] = true
+		if changes := scheduleChanges(events[i], row); len(changes) > 0 {
+			diff.ScheduleChanged = append(diff.ScheduleChanged, &ScheduleDiff{
+				StartTime: row.StartTime,
+				Name:      row.EventNameES,
+				Changes:   changes,
+			})
+		}
+	}
+	for i, event := range events {
+		if !matched[i] {
+			diff.ScheduleRemoved = append(diff.ScheduleRemoved, &ScheduleDiff{StartTime: event.StartTime, Name: event.EventNameEs})
+		}
+	}
+
+	return nil
+}
+
+// matchSchedule returns the index of the stored event each sheet row is,
+// or -1 for new rows. Events have no ID in the sheet, so they're matched by
+// start time and Spanish name, then by either one to tell a moved or
+// renamed event from a new one.
+func matchSchedule(rows []*ScheduleEventRow, events []*store.ScheduleEvent) []int {
+	match := make([]int, len(rows))
+	for r := range match {
+		match[r] = -1
+	}
+	matched := make([]bool, len(events))
+	pass := func(same func(*ScheduleEventRow, *store.ScheduleEvent) bool) {
+		for r, row := range rows {
+			if match[r] != -1 {
+				continue
+			}
+			for i, event := range events {
+				if !matched[i] && same(row, event) {
+					match[r], matched[i] = i, true
+					break
+				}
+			}
+		}
+	}
+
+	pass(func(row *ScheduleEventRow, event *store.ScheduleEvent) bool {
+		return row.StartTime == event.StartTime && row.EventNameES == event.EventNameEs
+	})
+	pass(func(row *ScheduleEventRow, event *store.ScheduleEvent) bool {
+		return row.EventNameES == event.EventNameEs
+	})
+	pass(func(row *ScheduleEventRow, event *store.ScheduleEvent) bool {
+		return row.StartTime == event.StartTime
+	})
+	return match
+}
+
+// scheduleChanges lists the fields that differ between a stored event and a sheet row
+func scheduleChanges(event *store.ScheduleEvent, row *ScheduleEventRow) []*FieldChange {
+	var changes []*FieldChange
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, &FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	var oldEnd, newEnd string
+	if event.EndTime != nil {
+		oldEnd = *event.EndTime
+	}
+	if row.EndTime != nil {
+		newEnd = *row.EndTime
+	}
+
+	add("start_time", event.StartTime, row.StartTime)
+	add("end_time", oldEnd, newEnd)
+	add("timezone", event.Timezone, row.Timezone)
+	add("event_name_es", event.EventNameEs, row.EventNameES)
+	add("event_name_en", event.EventNameEn, row.EventNameEN)
+	add("event_name_ca", event.EventNameCa, row.EventNameCA)
+	add("location", event.Location, row.Location)
+	add("description_es", event.DescriptionEs, row.DescriptionES)
+	add("description_en", event.DescriptionEn, row.DescriptionEN)
+	add("description_ca", event.DescriptionCa, row.DescriptionCA)
+	return changes
+}
+
+// diffEvents compares the events and their invitations with the database,
+// see syncEventsFromSheet. Invites are known if they're in limits, the ones
+// they have once the guest list is applied, see invitePlan.limits.
+func (s *Syncer) diffEvents(ctx context.Context, diff *SyncDiff, limits map[string][2]int64) error {
+	events, err := s.source.ReadEvents(ctx)
+	if err != nil {
+		return err
+	}
+	if events == nil {
+		return nil // Source has no events, sync leaves them alone
+	}
+
+	existing, err := s.store.ListEvents(ctx)
+	if err != nil {
+		return err
+	}
+
+	// An empty Events tab removes nothing
+	if len(events) == 0 {
+		for _, event := range existing {
+			diff.EventsRemoved = append(diff.EventsRemoved, &EventDiff{
+				EventKey: event.EventKey,
+				Name:     event.NameEs,
+				Note:     "not removed, no events found in the sheet",
+			})
+		}
+		return nil
+	}
+
+	stored := make(map[string]*store.Event, len(existing))
+	for _, event := range existing {
+		stored[event.EventKey] = event
+	}
+
+	inSheet := make(map[string]bool, len(events))
+	for i, event := range events {
+		inSheet[event.Key] = true
+
+		if old, ok := stored[event.Key]; !ok {
+			diff.EventsAdded = append(diff.EventsAdded, &EventDiff{EventKey: event.Key, Name: event.NameES})
+		} else if changes := eventChanges(old, event, int64(i)); len(changes) > 0 {
+			diff.EventsChanged = append(diff.EventsChanged, &EventDiff{EventKey: event.Key, Name: event.NameES, Changes: changes})
+		}
+
+		if err := s.diffInvitations(ctx, diff, event, limits); err != nil {
+			return errors.Wrapf(err, "event %s", event.Key)
+		}
+	}
+
+	// Same as DeleteInviteEventsByEvent, answers not written to the event's
+	// tab keep it until they are
+	for _, event := range existing {
+		if inSheet[event.EventKey] {
+			continue
+		}
+		invitations, err := s.store.ListInviteEventsByEvent(ctx, event.EventKey)
+		if err != nil {
+			return err
+		}
+		removed := &EventDiff{EventKey: event.EventKey, Name: event.NameEs, Note: "delete with its invitations"}
+		if pending := countPendingAnswers(invitations); pending > 0 {
+			removed.Note = fmt.Sprintf("kept until %d pending answer(s) are written to its tab", pending)
+		}
+		diff.EventsRemoved = append(diff.EventsRemoved, removed)
+	}
+
+	return nil
+}
+
+// diffInvitations compares an event's tab with its invitations in the
+// database, see syncInvitations
+func (s *Syncer) diffInvitations(ctx context.Context, diff *SyncDiff, event *EventRow, limits map[string][2]int64) error {
+	current, err := s.store.ListInviteEventsByEvent(ctx, event.Key)
+	if err != nil {
+		return err
+	}
+	byCode := make(map[string]*store.InviteEvent, len(current))
+	for _, invitation := range current {
+		byCode[invitation.InviteCode] = invitation
+	}
+
+	plan := planInvitations(event, current, limits)
+	for _, row := range plan.unknown {
+		diff.InvitationsAdded = append(diff.InvitationsAdded, &InvitationDiff{
+			EventKey:   event.Key,
+			InviteCode: row.InviteCode,
+			SheetRow:   &row.SheetRow,
+			Note:       "skipped, unknown invite",
+		})
+	}
+
+	for _, params := range plan.upserts {
+		old, ok := byCode[params.InviteCode]
+		if !ok {
+			diff.InvitationsAdded = append(diff.InvitationsAdded, &InvitationDiff{
+				EventKey:   event.Key,
+				InviteCode: params.InviteCode,
+				SheetRow:   params.SheetRow,
+			})
+			continue
+		}
+
+		changes := invitationChanges(old, params)
+		if len(changes) == 0 {
+			continue
+		}
+		// Same condition as the WHERE clause of UpsertInviteEvent
+		var note string
+		if answerPending(old) {
+			note = "not applied, answer not written to the tab yet"
+		}
+		diff.InvitationsChanged = append(diff.InvitationsChanged, &InvitationDiff{
+			EventKey:   event.Key,
+			InviteCode: params.InviteCode,
+			SheetRow:   params.SheetRow,
+			Changes:    changes,
+			Note:       note,
+		})
+	}
+
+	for _, invitation := range plan.removed {
+		removed := &InvitationDiff{
+			EventKey:   event.Key,
+			InviteCode: invitation.InviteCode,
+			SheetRow:   invitation.SheetRow,
+		}
+		switch {
+		case plan.keep:
+			removed.Note = fmt.Sprintf("not removed, no invitations found in tab %s", event.Tab)
+		case answerPending(invitation):
+			removed.Note = "kept until its answer is written to the tab"
+		}
+		diff.InvitationsRemoved = append(diff.InvitationsRemoved, removed)
+	}
+
+	return nil
+}
+
+// diffEventRSVPs lists the event answers syncEventRSVPs would write
+func (s *Syncer) diffEventRSVPs(ctx context.Context, diff *SyncDiff) error {
+	pending, err := s.store.GetPendingInviteEventSyncs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, invitation := range pending {
+		diff.EventRSVPsToPush = append(diff.EventRSVPsToPush, &EventRSVPPush{
+			EventKey:        invitation.EventKey,
+			InviteCode:      invitation.InviteCode,
+			SheetRow:        invitation.SheetRow,
+			ConfirmedAdults: invitation.ConfirmedAdults,
+			ConfirmedKids:   invitation.ConfirmedKids,
+			ResponseAt:      formatDiffTime(invitation.ResponseAt),
+		})
+	}
+	return nil
+}
+
+// eventChanges lists the fields UpsertEvent would change
+func eventChanges(old *store.Event, event *EventRow, position int64) []*FieldChange {
+	var changes []*FieldChange
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, &FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	add("position", strconv.FormatInt(old.Position, 10), strconv.FormatInt(position, 10))
+	add("name_es", old.NameEs, event.NameES)
+	add("name_en", old.NameEn, event.NameEN)
+	add("name_ca", old.NameCa, event.NameCA)
+	add("start_time", old.StartTime, event.StartTime)
+	add("timezone", old.Timezone, event.Timezone)
+	add("location", old.Location, event.Location)
+	add("sheet_tab", old.SheetTab, event.Tab)
+	return changes
+}
+
+// invitationChanges lists the fields UpsertInviteEvent would change, see
+// planInvitations
+func invitationChanges(old *store.InviteEvent, params *store.UpsertInviteEventParams) []*FieldChange {
+	var changes []*FieldChange
+	add := func(field string, oldValue, newValue int64) {
+		if oldValue != newValue {
+			changes = append(changes, &FieldChange{Field: field, Old: strconv.FormatInt(oldValue, 10), New: strconv.FormatInt(newValue, 10)})
+		}
+	}
+
+	add("max_adults", old.MaxAdults, params.MaxAdults)
+	add("max_kids", old.MaxKids, params.MaxKids)
+	add("confirmed_adults", old.ConfirmedAdults, params.ConfirmedAdults)
+	add("confirmed_kids", old.ConfirmedKids, params.ConfirmedKids)
+	if formatRow(old.SheetRow) != formatRow(params.SheetRow) {
+		changes = append(changes, &FieldChange{Field: "sheet_row", Old: formatRow(old.SheetRow), New: formatRow(params.SheetRow)})
+	}
+	return changes
+}
+
+// answerPending reports whether an invitation's answer isn't written to the
+// event's tab yet
+func answerPending(invitation *store.InviteEvent) bool {
+	return invitation.ResponseAt != nil && invitation.ResponseAt.After(invitation.UpdatedAt)
+}
+
+// countPendingAnswers counts the invitations with an answer not written yet
+func countPendingAnswers(invitations []*store.InviteEvent) int {
+	pending := 0
+	for _, invitation := range invitations {
+		if answerPending(invitation) {
+			pending++
+		}
+	}
+	return pending
+}
+
+// formatRow formats an optional sheet row number
+func formatRow(row *int64) string {
+	if row == nil {
+		return ""
+	}
+	return fmt.Sprint(*row)
+}
+
+// formatDiffTime formats an optional time in UTC to the second, as the database stores it
+func formatDiffTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Truncate(time.Second).Format(time.RFC3339)
+}
@@ -0,0 +1,284 @@
+package sheets
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/store"
+)
+
+const diffGuestList = `
+invites:
+  - invite_code: ANA1
+    name: Ana
+    max_adults: 2
+  - invite_code: BOB1
+    name: Bob
+  - invite_code: CAT1
+    name: Cat
+  - invite_code: DAN1
+    name: Dan
+events:
+  - key: dinner
+    name: {es: Cena}
+    start_time: "2026-12-18"
+    invites:
+      - invite_code: ANA1
+      - invite_code: BOB1
+        max_adults: 3
+      - invite_code: CAT1
+      - invite_code: DAN1
+`
+
+// The guest list after diffGuestList, with a change of every kind
+const diffGuestListChanged = `
+invites:
+  - invite_code: ANA1
+    name: Ana
+    max_adults: 4
+  - invite_code: BOB1
+    name: Bob Jr
+    max_kids: 2
+  - invite_code: CAT1
+    name: Cat
+  - invite_code: EVE1
+    name: Eve
+events:
+  - key: dinner
+    name: {es: Cena}
+    start_time: "2026-12-18"
+    invites:
+      - invite_code: ANA1
+      - invite_code: BOB1
+      - invite_code: DAN1
+      - invite_code: EVE1
+        confirmed_adults: 1
+      - invite_code: ZOE1
+`
+
+// TestDiffMatchesSync checks that a dry run reports what the sync cycle then
+// does: every change it lists without a note is applied, the ones with one
+// aren't, and nothing else changes
+func TestDiffMatchesSync(t *testing.T) {
+	s, path := newTestSyncer(t, diffGuestList)
+	ctx := context.Background()
+	if err := s.SyncOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// An admin edit and an event answer not written to the guest list yet
+	later := time.Now().UTC().Add(time.Hour)
+	if _, err := s.store.DB.ExecContext(ctx, `UPDATE invites SET local_changed_at = ? WHERE invite_code = 'ANA1'`, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.store.DB.ExecContext(ctx, `UPDATE invite_events SET response_at = ? WHERE invite_code = 'CAT1'`, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(diffGuestListChanged), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := s.Diff(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invitesBefore := snapshotInvites(t, s)
+	invitationsBefore := snapshotInvitations(t, s, "dinner")
+
+	if err := s.SyncOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	invitesAfter := snapshotInvites(t, s)
+	invitationsAfter := snapshotInvitations(t, s, "dinner")
+
+	// Invites
+	listed := map[string]bool{}
+	for _, added := range diff.InvitesAdded {
+		listed[added.InviteCode] = true
+		if invitesAfter[added.InviteCode] == nil {
+			t.Errorf("invite %s listed as added isn't in the database", added.InviteCode)
+		}
+	}
+	for _, changed := range diff.InvitesChanged {
+		listed[changed.InviteCode] = true
+		checkChanges(t, "invite "+changed.InviteCode, changed.Changes, changed.Note, invitesAfter[changed.InviteCode])
+	}
+	for _, removed := range diff.InvitesRemoved {
+		listed[removed.InviteCode] = true
+		if got := invitesAfter[removed.InviteCode]["disabled"]; got != "true" {
+			t.Errorf("invite %s listed as disabled has disabled = %s", removed.InviteCode, got)
+		}
+	}
+	checkUnlisted(t, "invite", listed, invitesBefore, invitesAfter)
+
+	// The event's invitations
+	listed = map[string]bool{}
+	for _, added := range diff.InvitationsAdded {
+		listed[added.InviteCode] = added.Note == ""
+		if exists := invitationsAfter[added.InviteCode] != nil; exists != (added.Note == "") {
+			t.Errorf("invitation %s listed as added with note %q, in the database: %v", added.InviteCode, added.Note, exists)
+		}
+	}
+	for _, changed := range diff.InvitationsChanged {
+		listed[changed.InviteCode] = true
+		checkChanges(t, "invitation "+changed.InviteCode, changed.Changes, changed.Note, invitationsAfter[changed.InviteCode])
+	}
+	for _, removed := range diff.InvitationsRemoved {
+		listed[removed.InviteCode] = true
+		if exists := invitationsAfter[removed.InviteCode] != nil; exists != (removed.Note != "") {
+			t.Errorf("invitation %s listed as removed with note %q, in the database: %v", removed.InviteCode, removed.Note, exists)
+		}
+	}
+	checkUnlisted(t, "invitation", listed, invitationsBefore, invitationsAfter)
+
+	// The fixture covers what the plan decides
+	if len(diff.InvitesChanged) != 2 || len(diff.InvitesRemoved) != 1 || len(diff.InvitationsRemoved) != 1 {
+		t.Errorf("diff has %d changed and %d removed invites, %d removed invitations, want 2, 1 and 1",
+			len(diff.InvitesChanged), len(diff.InvitesRemoved), len(diff.InvitationsRemoved))
+	}
+}
+
+// checkChanges checks that changes were applied to fields, or weren't if
+// the diff has a note saying so
+func checkChanges(t *testing.T, what string, changes []*FieldChange, note string, fields map[string]string) {
+	t.Helper()
+	if fields == nil {
+		t.Errorf("%s listed as changed isn't in the database", what)
+		return
+	}
+	for _, change := range changes {
+		want := change.New
+		if note != "" {
+			want = change.Old
+		}
+		if got := fields[change.Field]; got != want {
+			t.Errorf("%s: %s = %q, want %q (note %q)", what, change.Field, got, want, note)
+		}
+	}
+}
+
+// checkUnlisted checks that nothing the diff doesn't list changed
+func checkUnlisted(t *testing.T, what string, listed map[string]bool, before, after map[string]map[string]string) {
+	t.Helper()
+	for code, fields := range after {
+		if listed[code] {
+			continue
+		}
+		old := before[code]
+		if old == nil {
+			t.Errorf("%s %s added but not listed", what, code)
+			continue
+		}
+		for field, value := range fields {
+			if old[field] != value {
+				t.Errorf("%s %s: %s changed from %q to %q but not listed", what, code, field, old[field], value)
+			}
+		}
+	}
+	for code := range before {
+		if after[code] == nil && !listed[code] {
+			t.Errorf("%s %s removed but not listed", what, code)
+		}
+	}
+}
+
+// snapshotInvites returns the fields the diff lists of every invite, keyed
+// by invite code
+func snapshotInvites(t *testing.T, s *Syncer) map[string]map[string]string {
+	t.Helper()
+	invites, err := s.store.ListInvites(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := make(map[string]map[string]string, len(invites))
+	for _, invite := range invites {
+		fields[invite.InviteCode] = map[string]string{
+			"name":             invite.Name,
+			"max_adults":       strconv.FormatInt(invite.MaxAdults, 10),
+			"max_kids":         strconv.FormatInt(invite.MaxKids, 10),
+			"confirmed_adults": strconv.FormatInt(invite.ConfirmedAdults, 10),
+			"sheet_row":        formatRow(invite.SheetRow),
+			"disabled":         strconv.FormatBool(invite.Disabled()),
+		}
+	}
+	return fields
+}
+
+// snapshotInvitations returns the fields the diff lists of an event's
+// invitations, keyed by invite code
+func snapshotInvitations(t *testing.T, s *Syncer, eventKey string) map[string]map[string]string {
+	t.Helper()
+	invitations, err := s.store.ListInviteEventsByEvent(context.Background(), eventKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := make(map[string]map[string]string, len(invitations))
+	for _, invitation := range invitations {
+		fields[invitation.InviteCode] = map[string]string{
+			"max_adults":       strconv.FormatInt(invitation.MaxAdults, 10),
+			"max_kids":         strconv.FormatInt(invitation.MaxKids, 10),
+			"confirmed_adults": strconv.FormatInt(invitation.ConfirmedAdults, 10),
+			"confirmed_kids":   strconv.FormatInt(invitation.ConfirmedKids, 10),
+			"sheet_row":        formatRow(invitation.SheetRow),
+		}
+	}
+	return fields
+}
+
+func TestPlanInvites(t *testing.T) {
+	row := func(code string) *store.UpsertInviteParams {
+		return &store.UpsertInviteParams{InviteCode: code, MaxAdults: 2}
+	}
+	sheetRow := int64(2)
+	invite := func(code string) *store.Invite {
+		return &store.Invite{InviteCode: code, MaxAdults: 1, SheetRow: &sheetRow}
+	}
+
+	var invites []*store.Invite
+	for i := range 20 {
+		invites = append(invites, invite("INV"+strconv.Itoa(i)))
+	}
+	edited := invite("EDIT")
+	edited.LocalChangedAt = &time.Time{}
+	invites = append(invites, edited)
+
+	// Every invite but INV0 and EDIT dropped from the guest list
+	rows := []*store.UpsertInviteParams{row("INV0"), row("EDIT"), row("GONE"), row("NEW1")}
+	deletions := []*store.InviteDeletion{{InviteCode: "GONE"}}
+
+	plan := planInvites(rows, invites, deletions, RemovedInvitesDelete)
+	if len(plan.upserts) != 3 {
+		t.Errorf("%d upserts, want 3 without the deleted invite", len(plan.upserts))
+	}
+	if len(plan.removed) != 19 || !plan.refused {
+		t.Errorf("%d removed, refused %v, want 19 and refused", len(plan.removed), plan.refused)
+	}
+
+	// Refused deletions keep their limits, skipped upserts keep the stored ones
+	limits := plan.limits(invites)
+	for code, want := range map[string][2]int64{"INV0": {2, 0}, "INV1": {1, 0}, "EDIT": {1, 0}, "NEW1": {2, 0}} {
+		if got, ok := limits[code]; !ok || got != want {
+			t.Errorf("limits[%s] = %v, %v, want %v", code, got, ok, want)
+		}
+	}
+
+	plan = planInvites(rows, invites, deletions, RemovedInvitesDisable)
+	if plan.refused {
+		t.Errorf("disabling refused")
+	}
+
+	plan = planInvites(rows[:2], invites[:4], nil, RemovedInvitesDelete)
+	if len(plan.removed) != 3 || plan.refused {
+		t.Errorf("%d removed, refused %v, want 3 and not refused", len(plan.removed), plan.refused)
+	}
+	if _, ok := plan.limits(invites[:4])["INV1"]; ok {
+		t.Errorf("deleted invite still has limits")
+	}
+
+	if plan := planInvites(nil, invites, nil, RemovedInvitesDelete); len(plan.upserts)+len(plan.removed) != 0 {
+		t.Errorf("empty guest list planned %d upserts and %d removals", len(plan.upserts), len(plan.removed))
+	}
+}
@@ -0,0 +1,347 @@
+package sheets
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/store"
+	"github.com/pkg/errors"
+)
+
+// eventsTab is the tab listing the events besides the wedding
+const eventsTab = "Events"
+
+// EventRow is an event besides the wedding, e.g. Friday drinks, with the
+// invites invited to it
+type EventRow struct {
+	Key         string // Short ID used by the API, e.g. "drinks"
+	NameES      string
+	NameEN      string
+	NameCA      string
+	StartTime   string // RFC 3339, a date "2027-04-24", or empty
+	Timezone    string // IANA timezone of StartTime
+	Location    string
+	Tab         string // Tab (or file section) listing the invitations
+	Invitations []*EventInvitationRow
+}
+
+// EventInvitationRow is an invite invited to an event, read from the event's tab
+type EventInvitationRow struct {
+	InviteCode      string
+	MaxAdults       *int64 // Nil to use the invite's own limit
+	MaxKids         *int64 // Nil to use the invite's own limit
+	ConfirmedAdults int64
+	ConfirmedKids   int64
+	SheetRow        int64
+}
+
+// eventDateFormat is the format of an event start without a time
+const eventDateFormat = "2006-01-02"
+
+// newEventRow builds an event from its raw start and timezone. Both are
+// optional: an invalid one is logged and ignored rather than dropping the
+// event, which would drop everyone's answers for it too.
+func newEventRow(key string, name fileI18n, start, timezone, location, tab string, cal *Calendar) *EventRow {
+	loc := cal.Location
+	if timezone != "" {
+		var err error
+		if loc, err = loadLocation(timezone); err != nil {
+			log.Printf("Events: ignoring timezone of event %s: %v", key, err)
+			loc = cal.Location
+		}
+	}
+
+	if start != "" {
+		if day, err := time.ParseInLocation(eventDateFormat, start, loc); err == nil {
+			start = day.Format(eventDateFormat)
+		} else if t, err := parseEventTime(start, loc); err == nil {
+			start = t.Format(time.RFC3339)
+		} else {
+			log.Printf("Events: ignoring start of event %s: %v", key, err)
+			start = ""
+		}
+	}
+
+	if tab == "" {
+		tab = key
+	}
+
+	return &EventRow{
+		Key:       key,
+		NameES:    name.ES,
+		NameEN:    name.EN,
+		NameCA:    name.CA,
+		StartTime: start,
+		Timezone:  loc.String(),
+		Location:  location,
+		Tab:       tab,
+	}
+}
+
+// parseEventRows converts Events tab rows (header excluded) into events,
+// skipping rows without a key or name and repeated keys
+func parseEventRows(values [][]interface{}, cols ColumnMap, cal *Calendar) []*EventRow {
+	events := []*EventRow{}
+	seen := make(map[string]bool, len(values))
+	for i, row := range values {
+		cell := func(key string) string {
+			return strings.TrimSpace(toString(cols.Cell(row, key)))
+		}
+
+		key := cell(ColEventKey)
+		name := fileI18n{ES: cell(ColEventNameES), EN: cell(ColEventNameEN), CA: cell(ColEventNameCA)}
+		if key == "" || name.ES == "" {
+			continue
+		}
+		if seen[key] {
+			log.Printf("Events: skipping row %d, event %s is already listed", i+2, key)
+			continue
+		}
+		seen[key] = true
+
+		events = append(events, newEventRow(key, name, cell(ColEventStart), cell(ColEventTimezone), cell(ColEventLocation), cell(ColEventTab), cal))
+	}
+	return events
+}
+
+// parseInvitationRows converts an event's tab (header included) into
+// invitations, skipping rows without an invite code
+func parseInvitationRows(values [][]interface{}, cols ColumnMap) []*EventInvitationRow {
+	limit := func(row []interface{}, key string) *int64 {
+		if strings.TrimSpace(toString(cols.Cell(row, key))) == "" {
+			return nil
+		}
+		n := toInt(cols.Cell(row, key))
+		return &n
+	}
+
+	invitations := []*EventInvitationRow{}
+	for i := 1; i < len(values); i++ {
+		row := values[i]
+		code := strings.TrimSpace(toString(cols.Cell(row, ColInviteCode)))
+		if code == "" {
+			continue
+		}
+		invitations = append(invitations, &EventInvitationRow{
+			InviteCode:      code,
+			MaxAdults:       limit(row, ColAdults),
+			MaxKids:         limit(row, ColKids),
+			ConfirmedAdults: toInt(cols.Cell(row, ColAdultsConfirmed)),
+			ConfirmedKids:   toInt(cols.Cell(row, ColKidsConfirmed)),
+			SheetRow:        int64(i + 1),
+		})
+	}
+	return invitations
+}
+
+// eventRSVPValues returns the cell values of an event answer keyed by column
+func eventRSVPValues(data *store.InviteEvent) map[string]interface{} {
+	responseAt := time.Now().UTC()
+	if data.ResponseAt != nil {
+		responseAt = *data.ResponseAt
+	}
+
+	return map[string]interface{}{
+		ColAdultsConfirmed: data.ConfirmedAdults,
+		ColKidsConfirmed:   data.ConfirmedKids,
+		ColResponseAt:      responseAt,
+	}
+}
+
+// syncEventsFromSheet reads the events and their invitations and replaces
+// them in the database, returning the number of events read. Answers not
+// yet written to an event's tab are kept, like in SyncFromSheet.
+func (s *Syncer) syncEventsFromSheet(ctx context.Context) (int64, error) {
+	events, err := s.source.ReadEvents(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if events == nil {
+		log.Println("Events sync skipped (source has no events)")
+		return 0, nil
+	}
+
+	// An empty Events tab is more likely a bad read than every event being
+	// called off, don't remove them all and their answers with it
+	if len(events) == 0 {
+		log.Println("No events found in sheet, skipping...")
+		return 0, nil
+	}
+
+	// Start transaction
+	tx, err := s.store.DB.Begin()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	q := s.store.WithTx(tx)
+
+	invites, err := q.ListInvites(ctx, "")
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list invites")
+	}
+	limits := inviteLimits(invites)
+
+	inSheet := make(map[string]bool, len(events))
+	for i, event := range events {
+		inSheet[event.Key] = true
+
+		params := &store.UpsertEventParams{
+			EventKey:  event.Key,
+			Position:  int64(i),
+			NameEs:    event.NameES,
+			NameEn:    event.NameEN,
+			NameCa:    event.NameCA,
+			StartTime: event.StartTime,
+			Timezone:  event.Timezone,
+			Location:  event.Location,
+			SheetTab:  event.Tab,
+		}
+		if err := q.UpsertEvent(ctx, params); err != nil {
+			return 0, errors.Wrapf(err, "failed to upsert event %s", event.Key)
+		}
+
+		if err := syncInvitations(ctx, q, event, limits); err != nil {
+			return 0, errors.Wrapf(err, "event %s", event.Key)
+		}
+	}
+
+	// Events removed from the sheet go away with everyone's answers,
+	// foreign keys aren't enforced. Answers not yet written to the event's
+	// tab keep it around until they are.
+	existing, err := q.ListEvents(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list events")
+	}
+	for _, event := range existing {
+		if inSheet[event.EventKey] {
+			continue
+		}
+		if _, err := q.DeleteInviteEventsByEvent(ctx, event.EventKey); err != nil {
+			return 0, errors.Wrapf(err, "failed to delete invitations of event %s", event.EventKey)
+		}
+		pending, err := q.ListInviteEventsByEvent(ctx, event.EventKey)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to list invitations of event %s", event.EventKey)
+		}
+		if len(pending) > 0 {
+			log.Printf("Keeping event %s removed from the sheet until %d pending answer(s) are written to its tab", event.EventKey, len(pending))
+			continue
+		}
+		if err := q.DeleteEvent(ctx, event.EventKey); err != nil {
+			return 0, errors.Wrapf(err, "failed to delete event %s", event.EventKey)
+		}
+		log.Printf("Deleted event %s removed from the sheet", event.EventKey)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	log.Printf("Synced %d events from sheet to database", len(events))
+	return int64(len(events)), nil
+}
+
+// syncInvitations replaces an event's invitations with the ones in its tab,
+// see planInvitations. Invitations with an answer not yet written to the tab
+// are kept until it is, and an empty tab removes none.
+func syncInvitations(ctx context.Context, q *store.Queries, event *EventRow, limits map[string][2]int64) error {
+	current, err := q.ListInviteEventsByEvent(ctx, event.Key)
+	if err != nil {
+		return errors.Wrap(err, "failed to list invitations")
+	}
+	plan := planInvitations(event, current, limits)
+
+	for _, row := range plan.unknown {
+		log.Printf("Event %s: skipping unknown invite %s in row %d", event.Key, row.InviteCode, row.SheetRow)
+	}
+	for _, row := range plan.duplicates {
+		log.Printf("Event %s: skipping row %d, invite %s is already listed", event.Key, row.SheetRow, row.InviteCode)
+	}
+	for _, params := range plan.upserts {
+		if err := q.UpsertInviteEvent(ctx, params); err != nil {
+			return errors.Wrapf(err, "failed to upsert invitation of %s", params.InviteCode)
+		}
+	}
+
+	if plan.keep {
+		if len(plan.removed) > 0 {
+			log.Printf("Event %s: no invitations found in tab %s, keeping the %d in the database", event.Key, event.Tab, len(plan.removed))
+		}
+		return nil
+	}
+
+	for _, invitation := range plan.removed {
+		deleted, err := q.DeleteInviteEvent(ctx, &store.DeleteInviteEventParams{InviteCode: invitation.InviteCode, EventKey: event.Key})
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete invitation of %s", invitation.InviteCode)
+		}
+		if deleted == 0 {
+			log.Printf("Event %s: keeping invitation of %s removed from the tab until its answer is written", event.Key, invitation.InviteCode)
+		}
+	}
+	return nil
+}
+
+// syncEventRSVPs writes pending event answers back to each event's tab and
+// returns how many were written and how many are left pending
+func (s *Syncer) syncEventRSVPs(ctx context.Context) (synced, failed int64, err error) {
+	pending, err := s.store.GetPendingInviteEventSyncs(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(pending) == 0 {
+		return 0, 0, nil
+	}
+
+	events, err := s.store.ListEvents(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	log.Printf("Syncing %d event RSVP responses to sheet", len(pending))
+
+	// Pending answers are ordered by event, write each event's at once
+	byEvent := make(map[string][]*store.InviteEvent)
+	for _, invitation := range pending {
+		byEvent[invitation.EventKey] = append(byEvent[invitation.EventKey], invitation)
+	}
+
+	for _, event := range events {
+		invitations := byEvent[event.EventKey]
+		if len(invitations) == 0 {
+			continue
+		}
+
+		errs := s.source.WriteEventRSVPs(ctx, event, invitations)
+		for i, invitation := range invitations {
+			if errs[i] != nil {
+				log.Printf("Failed to write RSVP for invite %s to event %s: %v", invitation.InviteCode, event.EventKey, errs[i])
+				continue
+			}
+			params := &store.MarkInviteEventSyncedParams{
+				InviteCode: invitation.InviteCode,
+				EventKey:   event.EventKey,
+				ResponseAt: invitation.ResponseAt,
+			}
+			marked, err := s.store.MarkInviteEventSynced(ctx, params)
+			if err != nil {
+				log.Printf("Failed to mark invite %s as synced for event %s: %v", invitation.InviteCode, event.EventKey, err)
+				continue
+			}
+			if marked == 0 {
+				log.Printf("Invite %s answered event %s again while it was written, writing it next sync", invitation.InviteCode, event.EventKey)
+				continue
+			}
+			synced++
+		}
+	}
+
+	log.Printf("Successfully synced %d of %d event RSVPs to sheet", synced, len(pending))
+	return synced, int64(len(pending)) - synced, nil
+}
@@ -0,0 +1,777 @@
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/store"
+	"gopkg.in/yaml.v3"
+)
+
+// FileSource is a Source backed by a local guest list file.
+// CSV files use the same header row as the Guests sheet; YAML files use the
+// guestListFile layout below and may include the schedule and events as well.
+type FileSource struct {
+	path          string
+	schedulePath  string // Optional CSV with the Schedule sheet columns (CSV guest lists only)
+	columnAliases map[string][]string
+	calendar      *Calendar // Year and timezone of schedule dates and deadlines
+
+	mu sync.Mutex // Serializes read-modify-write cycles on the file
+}
+
+// guestListFile is the YAML guest list layout
+type guestListFile struct {
+	Invites  []*fileInvite       `yaml:"invites"`
+	Schedule []*fileScheduleItem `yaml:"schedule,omitempty"`
+	Events   []*fileEvent        `yaml:"events,omitempty"`
+}
+
+// fileInvite is a single invite in a YAML guest list
+type fileInvite struct {
+	InviteCode      string     `yaml:"invite_code"`
+	Name            string     `yaml:"name"`
+	MaxAdults       *int64     `yaml:"max_adults,omitempty"` // 1 if not set
+	MaxKids         int64      `yaml:"max_kids"`
+	ConfirmedAdults int64      `yaml:"confirmed_adults"`
+	ConfirmedKids   int64      `yaml:"confirmed_kids"`
+	DietaryInfo     string     `yaml:"dietary_info,omitempty"`
+	MessageForUs    string     `yaml:"message_for_us,omitempty"`
+	SongRequest     string     `yaml:"song_request,omitempty"`
+	ResponseAt      *time.Time `yaml:"response_at,omitempty"`
+	RSVPDeadline    string     `yaml:"rsvp_deadline,omitempty"` // See ParseDeadline
+}
+
+// fileScheduleItem is a single public event in a YAML guest list
+type fileScheduleItem struct {
+	StartTime   string   `yaml:"start_time"` // ISO8601: "2026-12-19T16:00:00-06:00", or "2026-12-19T16:00" in Timezone
+	EndTime     string   `yaml:"end_time,omitempty"`
+	Timezone    string   `yaml:"timezone,omitempty"` // IANA name, the wedding's timezone if empty
+	Name        fileI18n `yaml:"name"`
+	Location    string   `yaml:"location,omitempty"`
+	Description fileI18n `yaml:"description,omitempty"`
+}
+
+// fileEvent is an event besides the wedding in a YAML guest list, with the
+// invites invited to it
+type fileEvent struct {
+	Key       string             `yaml:"key"`
+	Name      fileI18n           `yaml:"name"`
+	StartTime string             `yaml:"start_time,omitempty"` // A date, or a time like in the schedule
+	Timezone  string             `yaml:"timezone,omitempty"`
+	Location  string             `yaml:"location,omitempty"`
+	Invites   []*fileEventInvite `yaml:"invites"`
+}
+
+// fileEventInvite is an invite's invitation to an event in a YAML guest list
+type fileEventInvite struct {
+	InviteCode      string `yaml:"invite_code"`
+	MaxAdults       *int64 `yaml:"max_adults,omitempty"` // The invite's own limit if not set
+	MaxKids         *int64 `yaml:"max_kids,omitempty"`
+	ConfirmedAdults int64  `yaml:"confirmed_adults"`
+	ConfirmedKids   int64  `yaml:"confirmed_kids"`
+}
+
+// fileI18n holds text in all supported languages
+type fileI18n struct {
+	ES string `yaml:"es"`
+	EN string `yaml:"en,omitempty"`
+	CA string `yaml:"ca,omitempty"`
+}
+
+// NewFileSource creates a source for a local .csv, .yaml or .yml guest list.
+// Dates in the files are read with cal.
+func NewFileSource(path, schedulePath string, columnAliases map[string][]string, cal *Calendar) (*FileSource, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+	case ".yaml", ".yml":
+		if schedulePath != "" {
+			return nil, fmt.Errorf("schedule file is only supported with CSV guest lists, add a schedule section to %s instead", path)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported guest list file %s, expected .csv, .yaml or .yml", path)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open guest list: %w", err)
+	}
+
+	log.Printf("Using local guest list: %s", path)
+
+	return &FileSource{
+		path:          path,
+		schedulePath:  schedulePath,
+		columnAliases: columnAliases,
+		calendar:      cal,
+	}, nil
+}
+
+// IsConfigured returns whether the source is configured
+func (f *FileSource) IsConfigured() bool {
+	return f != nil && f.path != ""
+}
+
+// isYAML reports whether the guest list is a YAML file
+func (f *FileSource) isYAML() bool {
+	ext := strings.ToLower(filepath.Ext(f.path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// ReadInvites reads all invites from the guest list file
+func (f *FileSource) ReadInvites(ctx context.Context) ([]*store.UpsertInviteParams, error) {
+	rows, err := f.ReadGuestRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return codedRows(rows), nil
+}
+
+// ReadGuestRows reads every named invite of the guest list file, with or
+// without an invite code
+func (f *FileSource) ReadGuestRows(ctx context.Context) ([]*store.UpsertInviteParams, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var rows []*store.UpsertInviteParams
+	if f.isYAML() {
+		list, err := f.readYAML()
+		if err != nil {
+			return nil, err
+		}
+		for i, inv := range list.Invites {
+			if inv == nil || inv.Name == "" {
+				continue
+			}
+			rowNum := int64(i + 1) // Position in the invites list
+			maxAdults := int64(1)
+			if inv.MaxAdults != nil {
+				maxAdults = *inv.MaxAdults
+			}
+			row := &store.UpsertInviteParams{
+				InviteCode:      inv.InviteCode,
+				Name:            inv.Name,
+				MaxAdults:       maxAdults,
+				MaxKids:         inv.MaxKids,
+				ConfirmedAdults: inv.ConfirmedAdults,
+				SheetRow:        &rowNum,
+			}
+			if inv.RSVPDeadline != "" {
+				deadline, err := f.calendar.ParseDeadline(inv.RSVPDeadline)
+				if err != nil {
+					log.Printf("Invite %d: ignoring RSVP deadline: %v", rowNum, err)
+				} else {
+					row.RsvpDeadline = &deadline
+				}
+			}
+			rows = append(rows, row)
+		}
+	} else {
+		values, cols, err := f.readCSV()
+		if err != nil {
+			return nil, err
+		}
+		rows = parseGuestRows(values[1:], cols, f.calendar)
+	}
+
+	log.Printf("Read %d invites from guest list %s", len(codedRows(rows)), f.path)
+	return rows, nil
+}
+
+// WriteRSVPs writes RSVP response data back to the guest list file, saving
+// it once for all invites. Invites are located by invite code, so reordering
+// the file is safe.
+func (f *FileSource) WriteRSVPs(ctx context.Context, invites []*store.Invite) []error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	errs := make([]error, len(invites))
+	fail := func(err error) []error {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = err
+			}
+		}
+		return errs
+	}
+
+	if f.isYAML() {
+		list, doc, err := f.readYAMLTree()
+		if err != nil {
+			return fail(err)
+		}
+		root, err := yamlRoot(doc)
+		if err != nil {
+			return fail(err)
+		}
+		for i, data := range invites {
+			idx := slices.IndexFunc(list.Invites, func(inv *fileInvite) bool { return inv != nil && inv.InviteCode == data.InviteCode })
+			if idx == -1 {
+				errs[i] = fmt.Errorf("invite %s not found in %s", data.InviteCode, f.path)
+				continue
+			}
+			errs[i] = setYAMLFields(root, "invites", idx, []yamlField{
+				{"confirmed_adults", data.ConfirmedAdults},
+				{"confirmed_kids", data.ConfirmedKids},
+				{"dietary_info", data.DietaryInfo},
+				{"message_for_us", data.MessageForUs},
+				{"song_request", data.SongRequest},
+				{"response_at", data.ResponseAt},
+			})
+		}
+		if err := f.writeYAML(doc); err != nil {
+			return fail(err)
+		}
+		return errs
+	}
+
+	values, cols, err := f.readCSV()
+	if err != nil {
+		return fail(err)
+	}
+	_, last := cols.Span(rsvpColumns)
+	for i, data := range invites {
+		row := findCSVInvite(values, cols, data.InviteCode)
+		if row == -1 {
+			errs[i] = fmt.Errorf("invite %s not found in %s", data.InviteCode, f.path)
+			continue
+		}
+		for len(values[row]) <= last {
+			values[row] = append(values[row], "")
+		}
+		for key, value := range rsvpValues(data) {
+			values[row][cols[key]] = csvValue(value)
+		}
+	}
+	if err := f.writeCSV(values); err != nil {
+		return fail(err)
+	}
+	return errs
+}
+
+// AppendInvite adds an invite at the end of the guest list file
+func (f *FileSource) AppendInvite(ctx context.Context, data *store.Invite) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.isYAML() {
+		_, doc, err := f.readYAMLTree()
+		if err != nil {
+			return 0, err
+		}
+		root, err := yamlRoot(doc)
+		if err != nil {
+			return 0, err
+		}
+		items, err := yamlItems(root, "invites")
+		if err != nil {
+			return 0, err
+		}
+		var item yaml.Node
+		if err := item.Encode(&fileInvite{
+			InviteCode: data.InviteCode,
+			Name:       data.Name,
+			MaxAdults:  &data.MaxAdults,
+			MaxKids:    data.MaxKids,
+		}); err != nil {
+			return 0, fmt.Errorf("failed to encode invite: %w", err)
+		}
+		items.Content = append(items.Content, &item)
+		if err := f.writeYAML(doc); err != nil {
+			return 0, err
+		}
+		return int64(len(items.Content)), nil
+	}
+
+	values, cols, err := f.readCSV()
+	if err != nil {
+		return 0, err
+	}
+	row := make([]interface{}, len(values[0]))
+	for i := range row {
+		row[i] = ""
+	}
+	for key, value := range inviteValues(data) {
+		row[cols[key]] = csvValue(value)
+	}
+	values = append(values, row)
+	if err := f.writeCSV(values); err != nil {
+		return 0, err
+	}
+	return int64(len(values)), nil // Line number, same as parseGuestRows
+}
+
+// WriteInvite writes an invite's name and limits back to the guest list file
+func (f *FileSource) WriteInvite(ctx context.Context, data *store.Invite) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.isYAML() {
+		list, doc, err := f.readYAMLTree()
+		if err != nil {
+			return err
+		}
+		root, err := yamlRoot(doc)
+		if err != nil {
+			return err
+		}
+		idx := slices.IndexFunc(list.Invites, func(inv *fileInvite) bool { return inv != nil && inv.InviteCode == data.InviteCode })
+		if idx == -1 {
+			return fmt.Errorf("invite %s not found in %s", data.InviteCode, f.path)
+		}
+		if err := setYAMLFields(root, "invites", idx, []yamlField{
+			{"name", data.Name},
+			{"max_adults", data.MaxAdults},
+			{"max_kids", data.MaxKids},
+		}); err != nil {
+			return err
+		}
+		return f.writeYAML(doc)
+	}
+
+	values, cols, err := f.readCSV()
+	if err != nil {
+		return err
+	}
+	i := findCSVInvite(values, cols, data.InviteCode)
+	if i == -1 {
+		return fmt.Errorf("invite %s not found in %s", data.InviteCode, f.path)
+	}
+	_, last := cols.Span(inviteColumns)
+	for len(values[i]) <= last {
+		values[i] = append(values[i], "")
+	}
+	for key, value := range inviteValues(data) {
+		if key != ColInviteCode {
+			values[i][cols[key]] = csvValue(value)
+		}
+	}
+	return f.writeCSV(values)
+}
+
+// DeleteInvite removes an invite from the guest list file.
+// Invites are located by invite code, sheetRow is only used by the sheet.
+func (f *FileSource) DeleteInvite(ctx context.Context, inviteCode string, sheetRow int64) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.isYAML() {
+		list, doc, err := f.readYAMLTree()
+		if err != nil {
+			return 0, err
+		}
+		root, err := yamlRoot(doc)
+		if err != nil {
+			return 0, err
+		}
+		i := slices.IndexFunc(list.Invites, func(inv *fileInvite) bool { return inv != nil && inv.InviteCode == inviteCode })
+		if i == -1 {
+			return 0, ErrInviteNotFound
+		}
+		items := yamlValue(root, "invites")
+		items.Content = slices.Delete(items.Content, i, i+1)
+		if err := f.writeYAML(doc); err != nil {
+			return 0, err
+		}
+		return int64(i + 1), nil
+	}
+
+	values, cols, err := f.readCSV()
+	if err != nil {
+		return 0, err
+	}
+	i := findCSVInvite(values, cols, inviteCode)
+	if i == -1 {
+		return 0, ErrInviteNotFound
+	}
+	values = append(values[:i], values[i+1:]...)
+	if err := f.writeCSV(values); err != nil {
+		return 0, err
+	}
+	return int64(i + 1), nil
+}
+
+// WriteInviteCodes writes generated invite codes to the guest list file
+func (f *FileSource) WriteInviteCodes(ctx context.Context, codes []*CodeAssignment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.isYAML() {
+		list, doc, err := f.readYAMLTree()
+		if err != nil {
+			return err
+		}
+		root, err := yamlRoot(doc)
+		if err != nil {
+			return err
+		}
+		for _, code := range codes {
+			i := int(code.Row - 1)
+			if i < 0 || i >= len(list.Invites) || list.Invites[i] == nil || list.Invites[i].Name != code.Name || list.Invites[i].InviteCode != "" {
+				return fmt.Errorf("invite %d (%s) changed since it was read, run again", code.Row, code.Name)
+			}
+			if err := setYAMLFields(root, "invites", i, []yamlField{{"invite_code", code.InviteCode}}); err != nil {
+				return err
+			}
+		}
+		return f.writeYAML(doc)
+	}
+
+	values, cols, err := f.readCSV()
+	if err != nil {
+		return err
+	}
+	if err := checkUncodedRows(values, cols, codes); err != nil {
+		return err
+	}
+	for _, code := range codes {
+		row := values[code.Row-1]
+		for len(row) <= cols[ColInviteCode] {
+			row = append(row, "")
+		}
+		row[cols[ColInviteCode]] = code.InviteCode
+		values[code.Row-1] = row
+	}
+	return f.writeCSV(values)
+}
+
+// ReadSchedule reads public schedule events from the YAML guest list or the schedule CSV
+func (f *FileSource) ReadSchedule(ctx context.Context) ([]*ScheduleEventRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var events []*ScheduleEventRow
+	if f.isYAML() {
+		list, err := f.readYAML()
+		if err != nil {
+			return nil, err
+		}
+		if list.Schedule == nil {
+			return nil, nil // No schedule section, leave DB as is
+		}
+		events = make([]*ScheduleEventRow, 0, len(list.Schedule))
+		for _, item := range list.Schedule {
+			loc := f.calendar.Location
+			if item.Timezone != "" {
+				if loc, err = loadLocation(item.Timezone); err != nil {
+					log.Printf("Schedule: Skipping event '%s' - %v", item.Name.ES, err)
+					continue
+				}
+			}
+			start, err := parseEventTime(item.StartTime, loc)
+			if err != nil {
+				log.Printf("Schedule: Skipping event '%s' - invalid start time: %v", item.Name.ES, err)
+				continue
+			}
+			var end *string
+			if item.EndTime != "" {
+				if t, err := parseEventTime(item.EndTime, loc); err == nil {
+					end = toNullString(t.Format(time.RFC3339))
+				} else {
+					log.Printf("Schedule: Ignoring end time of event '%s': %v", item.Name.ES, err)
+				}
+			}
+			events = append(events, &ScheduleEventRow{
+				StartTime:     start.Format(time.RFC3339),
+				EndTime:       end,
+				EventNameES:   item.Name.ES,
+				EventNameEN:   item.Name.EN,
+				EventNameCA:   item.Name.CA,
+				Location:      item.Location,
+				DescriptionES: item.Description.ES,
+				DescriptionEN: item.Description.EN,
+				DescriptionCA: item.Description.CA,
+				Timezone:      loc.String(),
+			})
+		}
+	} else {
+		if f.schedulePath == "" {
+			return nil, nil // No schedule configured, leave DB as is
+		}
+		values, err := readCSVFile(f.schedulePath)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) > 0 {
+			values = values[1:] // Skip header row, same as the sheet range A2:K
+		}
+		events = parseScheduleRows(values, f.calendar)
+		if events == nil {
+			events = []*ScheduleEventRow{}
+		}
+	}
+
+	log.Printf("Read %d public schedule events from local files", len(events))
+	return events, nil
+}
+
+// ReadEvents reads the events section of the YAML guest list. CSV guest
+// lists have no events.
+func (f *FileSource) ReadEvents(ctx context.Context) ([]*EventRow, error) {
+	if !f.isYAML() {
+		return nil, nil // No events, leave DB as is
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, err := f.readYAML()
+	if err != nil {
+		return nil, err
+	}
+	if list.Events == nil {
+		return nil, nil // No events section, leave DB as is
+	}
+
+	events := make([]*EventRow, 0, len(list.Events))
+	seen := make(map[string]bool, len(list.Events))
+	for _, item := range list.Events {
+		if item.Key == "" || item.Name.ES == "" {
+			continue
+		}
+		if seen[item.Key] {
+			log.Printf("Events: skipping event %s, it is already listed", item.Key)
+			continue
+		}
+		seen[item.Key] = true
+
+		event := newEventRow(item.Key, item.Name, item.StartTime, item.Timezone, item.Location, "", f.calendar)
+		event.Invitations = make([]*EventInvitationRow, 0, len(item.Invites))
+		for i, inv := range item.Invites {
+			if inv.InviteCode == "" {
+				continue
+			}
+			event.Invitations = append(event.Invitations, &EventInvitationRow{
+				InviteCode:      inv.InviteCode,
+				MaxAdults:       inv.MaxAdults,
+				MaxKids:         inv.MaxKids,
+				ConfirmedAdults: inv.ConfirmedAdults,
+				ConfirmedKids:   inv.ConfirmedKids,
+				SheetRow:        int64(i + 1), // Position in the event's invites list
+			})
+		}
+		events = append(events, event)
+	}
+
+	log.Printf("Read %d events from guest list %s", len(events), f.path)
+	return events, nil
+}
+
+// WriteEventRSVPs writes answers for one event back to the YAML guest list,
+// saving it once for all invitations. Invitations are located by invite code.
+func (f *FileSource) WriteEventRSVPs(ctx context.Context, event *store.Event, invitations []*store.InviteEvent) []error {
+	errs := make([]error, len(invitations))
+	fail := func(err error) []error {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = err
+			}
+		}
+		return errs
+	}
+
+	if !f.isYAML() {
+		return fail(fmt.Errorf("guest list %s has no events", f.path))
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list, doc, err := f.readYAMLTree()
+	if err != nil {
+		return fail(err)
+	}
+	root, err := yamlRoot(doc)
+	if err != nil {
+		return fail(err)
+	}
+	idx := slices.IndexFunc(list.Events, func(item *fileEvent) bool { return item != nil && item.Key == event.EventKey })
+	if idx == -1 {
+		return fail(fmt.Errorf("event %s not found in %s", event.EventKey, f.path))
+	}
+	item := list.Events[idx]
+	node, err := yamlItem(root, "events", idx)
+	if err != nil {
+		return fail(err)
+	}
+
+	for i, data := range invitations {
+		idx := slices.IndexFunc(item.Invites, func(inv *fileEventInvite) bool { return inv != nil && inv.InviteCode == data.InviteCode })
+		if idx == -1 {
+			errs[i] = fmt.Errorf("invite %s not found in event %s of %s", data.InviteCode, event.EventKey, f.path)
+			continue
+		}
+		errs[i] = setYAMLFields(node, "invites", idx, []yamlField{
+			{"confirmed_adults", data.ConfirmedAdults},
+			{"confirmed_kids", data.ConfirmedKids},
+		})
+	}
+	if err := f.writeYAML(doc); err != nil {
+		return fail(err)
+	}
+	return errs
+}
+
+// eventTimeFormats are the YAML schedule time formats without a UTC offset
+var eventTimeFormats = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"}
+
+// parseEventTime parses a YAML schedule time. RFC 3339 times are converted to
+// loc so they carry the event's offset, times without one are read in loc.
+func parseEventTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.In(loc), nil
+	}
+	for _, layout := range eventTimeFormats {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339 or YYYY-MM-DDTHH:MM", s)
+}
+
+// readYAML loads the YAML guest list
+func (f *FileSource) readYAML() (*guestListFile, error) {
+	list, _, err := f.readYAMLTree()
+	return list, err
+}
+
+// readYAMLTree loads the YAML guest list along with its node tree, which
+// writes edit, see yamlRoot
+func (f *FileSource) readYAMLTree() (*guestListFile, *yaml.Node, error) {
+	raw, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read guest list: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse guest list %s: %w", f.path, err)
+	}
+	var list guestListFile
+	if err := doc.Decode(&list); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse guest list %s: %w", f.path, err)
+	}
+	return &list, &doc, nil
+}
+
+// writeYAML saves the YAML guest list's node tree
+func (f *FileSource) writeYAML(doc *yaml.Node) error {
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(yamlIndent(doc))
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode guest list: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode guest list: %w", err)
+	}
+	return writeFileAtomic(f.path, b.Bytes())
+}
+
+// readCSV loads the CSV guest list and resolves its header row
+func (f *FileSource) readCSV() ([][]interface{}, ColumnMap, error) {
+	values, err := readCSVFile(f.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(values) == 0 {
+		return nil, nil, fmt.Errorf("guest list %s is empty, expected a header row", f.path)
+	}
+	cols, err := ResolveGuestColumns(values[0], f.columnAliases)
+	if err != nil {
+		return nil, nil, fmt.Errorf("guest list %s: %w", f.path, err)
+	}
+	return values, cols, nil
+}
+
+// writeCSV saves the CSV guest list
+func (f *FileSource) writeCSV(values [][]interface{}) error {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	for _, row := range values {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = toString(cell)
+		}
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("failed to encode guest list: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to encode guest list: %w", err)
+	}
+	return writeFileAtomic(f.path, []byte(b.String()))
+}
+
+// findCSVInvite returns the index of an invite's row in the CSV guest list, or -1
+func findCSVInvite(values [][]interface{}, cols ColumnMap, inviteCode string) int {
+	for i := 1; i < len(values); i++ {
+		if strings.TrimSpace(toString(cols.Cell(values[i], ColInviteCode))) == inviteCode {
+			return i
+		}
+	}
+	return -1
+}
+
+// readCSVFile reads a CSV file into sheet-like rows
+func readCSVFile(path string) ([][]interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1 // Rows may be shorter than the header, like in Sheets
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	values := make([][]interface{}, len(records))
+	for i, record := range records {
+		row := make([]interface{}, len(record))
+		for j, cell := range record {
+			row[j] = cell
+		}
+		values[i] = row
+	}
+	return values, nil
+}
+
+// csvValue formats a cell value for a CSV file
+func csvValue(v interface{}) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return v
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it into place
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write guest list: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write guest list: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace guest list: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package sheets
+
+import (
+	"github.com/casassg/wedding/backend/internal/store"
+)
+
+// The sync cycle and Diff decide what to do with the guest list through the
+// plans below, worked out from what was read before anything is written. The
+// cycle applies them and Diff reports them, so a dry run shows what the real
+// run does.
+
+// invitePlan is what SyncFromSheet does with the invites read from the guest list
+type invitePlan struct {
+	upserts []*store.UpsertInviteParams // Every row but the ones deleted through the admin API
+	removed []*store.Invite             // Missing from the guest list and handled by policy
+	policy  string                      // One of the RemovedInvites* constants
+	refused bool                        // Deleting removed is more than the policy allows, see tooManyDeleted
+}
+
+// planInvites plans applying rows to the invites in the database. Invites
+// deleted through the admin API stay in the sheet until the next
+// SyncToSheet, so their rows aren't brought back in the meantime.
+func planInvites(rows []*store.UpsertInviteParams, invites []*store.Invite, deletions []*store.InviteDeletion, policy string) *invitePlan {
+	plan := &invitePlan{policy: policy}
+
+	// An empty guest list is more likely a bad read than everyone being
+	// taken off it, see applyInvites
+	if len(rows) == 0 {
+		return plan
+	}
+
+	deleted := make(map[string]bool, len(deletions))
+	for _, deletion := range deletions {
+		deleted[deletion.InviteCode] = true
+	}
+
+	inSheet := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		inSheet[row.InviteCode] = true
+		if !deleted[row.InviteCode] {
+			plan.upserts = append(plan.upserts, row)
+		}
+	}
+
+	for _, invite := range invites {
+		if !inSheet[invite.InviteCode] && isRemoved(invite, policy) {
+			plan.removed = append(plan.removed, invite)
+		}
+	}
+	plan.refused = policy == RemovedInvitesDelete && tooManyDeleted(len(plan.removed), len(invites))
+	return plan
+}
+
+// limits returns the limits each invite has once the plan is applied, which
+// event invitations fall back to. Rows UpsertInvite skips keep the stored
+// limits, and deleted invites have none.
+func (p *invitePlan) limits(invites []*store.Invite) map[string][2]int64 {
+	existing := make(map[string]*store.Invite, len(invites))
+	limits := make(map[string][2]int64, len(invites)+len(p.upserts))
+	for _, invite := range invites {
+		existing[invite.InviteCode] = invite
+		limits[invite.InviteCode] = [2]int64{invite.MaxAdults, invite.MaxKids}
+	}
+	for _, row := range p.upserts {
+		if old := existing[row.InviteCode]; old == nil || upsertApplies(old) {
+			limits[row.InviteCode] = [2]int64{row.MaxAdults, row.MaxKids}
+		}
+	}
+	if p.policy == RemovedInvitesDelete && !p.refused {
+		for _, invite := range p.removed {
+			delete(limits, invite.InviteCode)
+		}
+	}
+	return limits
+}
+
+// upsertApplies reports whether UpsertInvite updates an existing invite,
+// the same conditions as its WHERE clause: no admin edit and no RSVP
+// waiting to be written to the sheet
+func upsertApplies(old *store.Invite) bool {
+	return old.LocalChangedAt == nil && (old.ResponseAt == nil || !old.ResponseAt.After(old.UpdatedAt))
+}
+
+// inviteLimits returns the limits of invites, keyed by invite code
+func inviteLimits(invites []*store.Invite) map[string][2]int64 {
+	limits := make(map[string][2]int64, len(invites))
+	for _, invite := range invites {
+		limits[invite.InviteCode] = [2]int64{invite.MaxAdults, invite.MaxKids}
+	}
+	return limits
+}
+
+// invitationPlan is what syncInvitations does with an event's tab
+type invitationPlan struct {
+	upserts    []*store.UpsertInviteEventParams
+	unknown    []*EventInvitationRow // Invites not in the database, skipped
+	duplicates []*EventInvitationRow // Invites already listed in an earlier row, skipped
+	removed    []*store.InviteEvent  // Not in the tab anymore, see DeleteInviteEvent
+	keep       bool                  // The tab is empty, which removes none of them
+}
+
+// planInvitations plans replacing an event's invitations with the ones in
+// its tab. Limits left empty in the tab are the invite's own, from limits.
+func planInvitations(event *EventRow, current []*store.InviteEvent, limits map[string][2]int64) *invitationPlan {
+	plan := &invitationPlan{keep: len(event.Invitations) == 0}
+
+	invited := make(map[string]bool, len(event.Invitations))
+	for _, row := range event.Invitations {
+		limit, known := limits[row.InviteCode]
+		if !known {
+			plan.unknown = append(plan.unknown, row)
+			continue
+		}
+		if invited[row.InviteCode] {
+			plan.duplicates = append(plan.duplicates, row)
+			continue
+		}
+		invited[row.InviteCode] = true
+
+		params := &store.UpsertInviteEventParams{
+			InviteCode:      row.InviteCode,
+			EventKey:        event.Key,
+			MaxAdults:       limit[0],
+			MaxKids:         limit[1],
+			ConfirmedAdults: row.ConfirmedAdults,
+			ConfirmedKids:   row.ConfirmedKids,
+			SheetRow:        &row.SheetRow,
+		}
+		if row.MaxAdults != nil {
+			params.MaxAdults = *row.MaxAdults
+		}
+		if row.MaxKids != nil {
+			params.MaxKids = *row.MaxKids
+		}
+		plan.upserts = append(plan.upserts, params)
+	}
+
+	for _, invitation := range current {
+		if !invited[invitation.InviteCode] {
+			plan.removed = append(plan.removed, invitation)
+		}
+	}
+	return plan
+}
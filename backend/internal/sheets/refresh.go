@@ -0,0 +1,176 @@
+package sheets
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Defaults of SetRefreshLimits
+const (
+	defaultRefreshInterval = 30 * time.Second
+	defaultMissedCodeTTL   = 5 * time.Minute
+)
+
+// errSyncRunning is returned by refreshInvites when a sync cycle holds the
+// lock, which would add a new code anyway
+var errSyncRunning = errors.New("sync cycle running")
+
+// maxMissedCodes caps the codes remembered as missing, so random codes
+// can't grow it without bound
+const maxMissedCodes = 10000
+
+// inviteRefresher limits the guest list reads done for unknown invite codes
+type inviteRefresher struct {
+	mu        sync.Mutex
+	interval  time.Duration        // Minimum time between reads
+	missedTTL time.Duration        // How long a missed code is answered from memory
+	last      time.Time            // Start of the last read
+	codes     map[string]bool      // Codes in the guest list at the last successful read
+	inflight  *inviteRefresh       // Read in progress, nil if none
+	missed    map[string]time.Time // Codes not in the last reads, until when they're remembered
+}
+
+// inviteRefresh is a single guest list read shared by concurrent callers
+type inviteRefresh struct {
+	done  chan struct{}
+	codes map[string]bool // Codes in the guest list, set when done
+	err   error
+}
+
+// SetRefreshLimits sets how often RefreshInvite may read the guest list and
+// how long codes still missing after a read are answered from memory
+func (s *Syncer) SetRefreshLimits(interval, missedTTL time.Duration) {
+	s.refresh.mu.Lock()
+	defer s.refresh.mu.Unlock()
+	s.refresh.interval = interval
+	s.refresh.missedTTL = missedTTL
+}
+
+// RefreshInvite re-reads the invites of the guest list, and nothing else,
+// for a code that isn't in the database, and reports whether the guest list
+// has it. Concurrent callers share one read and reads are at most once per
+// refresh interval; within it, and for codes that recently missed, it
+// answers false without reading. It also answers false without reading
+// while a sync cycle runs, rather than waiting for its Sheets calls.
+func (s *Syncer) RefreshInvite(ctx context.Context, inviteCode string) (bool, error) {
+	if !s.source.IsConfigured() {
+		return false, nil
+	}
+
+	r := &s.refresh
+	now := time.Now()
+
+	r.mu.Lock()
+	if until, ok := r.missed[inviteCode]; ok && now.Before(until) {
+		r.mu.Unlock()
+		return false, nil
+	}
+	call := r.inflight
+	if call == nil {
+		if !r.last.IsZero() && now.Sub(r.last) < r.interval {
+			missing := r.codes != nil && !r.codes[inviteCode]
+			r.mu.Unlock()
+			if missing {
+				r.remember(inviteCode, now)
+			}
+			return false, nil
+		}
+		call = &inviteRefresh{done: make(chan struct{})}
+		r.inflight = call
+		last := r.last
+		r.last = now
+		r.mu.Unlock()
+
+		// Detached from the request so the callers waiting on it aren't
+		// failed by the first one going away
+		call.codes, call.err = s.refreshInvites(context.WithoutCancel(ctx))
+
+		r.mu.Lock()
+		r.inflight = nil
+		switch {
+		case call.err == nil:
+			r.codes = call.codes
+		case errors.Is(call.err, errSyncRunning):
+			r.last = last // Didn't read, so the next lookup may
+		}
+		r.mu.Unlock()
+		close(call.done)
+	} else {
+		r.mu.Unlock()
+	}
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+	if errors.Is(call.err, errSyncRunning) {
+		return false, nil
+	}
+	if call.err != nil {
+		return false, call.err
+	}
+
+	found := call.codes[inviteCode]
+	if !found {
+		r.remember(inviteCode, time.Now())
+	}
+	return found, nil
+}
+
+// refreshInvites reads the invites from the guest list into the database
+// and returns the codes it holds. It only adds and updates invites: removing
+// the ones missing from the guest list is left to the sync cycle. Returns
+// errSyncRunning without reading if one is running, since it can take as
+// long as its Sheets calls and their retries.
+func (s *Syncer) refreshInvites(ctx context.Context) (map[string]bool, error) {
+	if !s.mu.TryLock() {
+		return nil, errSyncRunning
+	}
+	defer s.mu.Unlock()
+
+	log.Println("Refreshing invites from guest list")
+
+	rows, err := s.source.ReadInvites(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.applyInvites(ctx, rows, false); err != nil {
+		return nil, err
+	}
+
+	codes := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		codes[row.InviteCode] = true
+	}
+	return codes, nil
+}
+
+// remember records a code missing from the guest list, dropping expired
+// codes (or all of them when full) to make room
+func (r *inviteRefresher) remember(inviteCode string, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.missedTTL <= 0 {
+		return
+	}
+	if r.missed == nil {
+		r.missed = make(map[string]time.Time)
+	}
+	if len(r.missed) >= maxMissedCodes {
+		for code, until := range r.missed {
+			if !now.Before(until) {
+				delete(r.missed, code)
+			}
+		}
+		if len(r.missed) >= maxMissedCodes {
+			clear(r.missed)
+		}
+	}
+	r.missed[inviteCode] = now.Add(r.missedTTL)
+}
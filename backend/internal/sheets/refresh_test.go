@@ -0,0 +1,117 @@
+package sheets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/casassg/wedding/backend/internal/store"
+	"github.com/casassg/wedding/backend/migrations"
+)
+
+// newTestSyncer returns a syncer for a YAML guest list with the given
+// contents and a migrated database, both in a temporary directory
+func newTestSyncer(t *testing.T, guestList string) (*Syncer, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "guests.yaml")
+	if err := os.WriteFile(path, []byte(guestList), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cal, err := NewCalendar(2026, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := NewFileSource(path, "", nil, cal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := store.Open(filepath.Join(dir, "wedding.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.DB.Close() })
+	if _, err := db.Migrate(context.Background(), migrations.FS); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewSyncer(db, source), path
+}
+
+func TestRefreshInviteDoesNotWaitForSync(t *testing.T) {
+	s, _ := newTestSyncer(t, `
+invites:
+  - invite_code: ANA1
+    name: Ana
+`)
+	ctx := context.Background()
+
+	// A sync cycle holds the lock: answer right away without reading
+	s.mu.Lock()
+	done := make(chan struct{})
+	var found bool
+	var err error
+	go func() {
+		found, err = s.RefreshInvite(ctx, "ANA1")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		s.mu.Unlock()
+		t.Fatal("RefreshInvite waited for the sync cycle")
+	}
+	s.mu.Unlock()
+	if err != nil || found {
+		t.Fatalf("RefreshInvite during sync = %v, %v, want false, nil", found, err)
+	}
+
+	// Skipping the read doesn't use up the refresh interval or remember the
+	// code as missing
+	found, err = s.RefreshInvite(ctx, "ANA1")
+	if err != nil || !found {
+		t.Fatalf("RefreshInvite after sync = %v, %v, want true, nil", found, err)
+	}
+	if _, err := s.store.GetInviteByInviteCode(ctx, "ANA1"); err != nil {
+		t.Errorf("refreshed invite not in the database: %v", err)
+	}
+}
+
+func TestRefreshInviteRemembersMissedCodes(t *testing.T) {
+	s, path := newTestSyncer(t, `
+invites:
+  - invite_code: ANA1
+    name: Ana
+`)
+	ctx := context.Background()
+
+	if found, err := s.RefreshInvite(ctx, "BOB1"); err != nil || found {
+		t.Fatalf("RefreshInvite(BOB1) = %v, %v, want false, nil", found, err)
+	}
+
+	// Added to the guest list, but still answered from memory until the
+	// missed code expires
+	if err := os.WriteFile(path, []byte(`
+invites:
+  - invite_code: ANA1
+    name: Ana
+  - invite_code: BOB1
+    name: Bob
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s.SetRefreshLimits(0, time.Hour)
+	if found, err := s.RefreshInvite(ctx, "BOB1"); err != nil || found {
+		t.Fatalf("RefreshInvite(BOB1) within the missed code TTL = %v, %v, want false, nil", found, err)
+	}
+
+	s.SetRefreshLimits(0, 0)
+	s.refresh.missed = nil
+	if found, err := s.RefreshInvite(ctx, "BOB1"); err != nil || !found {
+		t.Fatalf("RefreshInvite(BOB1) after the TTL = %v, %v, want true, nil", found, err)
+	}
+}
@@ -0,0 +1,75 @@
+package sheets
+
+import (
+	"context"
+	"errors"
+
+	"github.com/casassg/wedding/backend/internal/store"
+)
+
+// Source is a guest list backend the Syncer reads invites, the schedule and
+// events from and writes RSVP responses back to. Google Sheets (Client) is the
+// default; FileSource keeps everything in a local CSV or YAML file.
+type Source interface {
+	// IsConfigured reports whether the source is usable. Sync is disabled otherwise.
+	IsConfigured() bool
+
+	// ReadInvites returns every invite that has both a name and an invite code
+	ReadInvites(ctx context.Context) ([]*store.UpsertInviteParams, error)
+
+	// ReadGuestRows returns every row that has a name, like ReadInvites but
+	// including the rows that have no invite code yet
+	ReadGuestRows(ctx context.Context) ([]*store.UpsertInviteParams, error)
+
+	// WriteRSVPs writes the RSVP answers of invites back to their rows in as
+	// few requests as possible. Rows are found by invite code, so answers never
+	// land on another guest's row if rows moved since the last read. Returns
+	// one error per invite, nil if written, wrapping ErrInviteNotFound if the
+	// invite has no row anymore.
+	WriteRSVPs(ctx context.Context, invites []*store.Invite) []error
+
+	// AppendInvite adds a row for an invite created through the admin API
+	// and returns its row number
+	AppendInvite(ctx context.Context, data *store.Invite) (int64, error)
+
+	// WriteInvite writes an invite's name and limits back to its row, found by
+	// invite code like in WriteRSVPs
+	WriteInvite(ctx context.Context, data *store.Invite) error
+
+	// DeleteInvite removes an invite's row and returns the row number it was
+	// removed from, which may differ from sheetRow if rows moved since the
+	// last read. Returns ErrInviteNotFound if the invite has no row anymore.
+	DeleteInvite(ctx context.Context, inviteCode string, sheetRow int64) (int64, error)
+
+	// WriteInviteCodes writes generated codes to their rows in a single batch.
+	// Fails without writing anything if any row changed since it was read.
+	WriteInviteCodes(ctx context.Context, codes []*CodeAssignment) error
+
+	// ReadSchedule returns the public schedule events, with times in their
+	// own timezone or the source's Calendar.
+	// A nil slice means the source has no schedule and the DB should be left as is.
+	ReadSchedule(ctx context.Context) ([]*ScheduleEventRow, error)
+
+	// ReadEvents returns the events besides the wedding and who is invited to
+	// each, in the source's order.
+	// A nil slice means the source has no events and the DB should be left as is.
+	ReadEvents(ctx context.Context) ([]*EventRow, error)
+
+	// WriteEventRSVPs writes the answers for one event back to its own tab,
+	// finding rows by invite code like WriteRSVPs. Returns one error per
+	// invitation, nil if written.
+	WriteEventRSVPs(ctx context.Context, event *store.Event, invitations []*store.InviteEvent) []error
+}
+
+// ErrInviteNotFound is returned when an invite's row can't be found in the source
+var ErrInviteNotFound = errors.New("invite not found in guest list")
+
+// ErrDuplicateInviteCode is returned by the Google Sheets client when an
+// invite's code is in more than one row, so writing to either could land on
+// another guest's row
+var ErrDuplicateInviteCode = errors.New("invite code in more than one row of the guest list")
+
+var (
+	_ Source = (*Client)(nil)
+	_ Source = (*FileSource)(nil)
+)
@@ -2,40 +2,82 @@ package sheets
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/casassg/wedding/backend/internal/store"
 	"github.com/pkg/errors"
 )
 
-// Default wedding year for schedule parsing
-const defaultWeddingYear = 2026
+// What to do with invites whose code is no longer in the guest list
+const (
+	RemovedInvitesDisable = "disable" // Keep the invite and its answers, but reject it
+	RemovedInvitesKeep    = "keep"    // Leave the invite as it is
+	RemovedInvitesDelete  = "delete"  // Delete the invite and its guests
+)
+
+// Deleting invites removed from the guest list is refused when a single
+// sync would delete more than maxDeleteShare of them, since a cleared code
+// column or a partial read looks the same as guests taken off the sheet.
+// Up to minDeleteGuard deletions are always allowed.
+const (
+	maxDeleteShare = 0.25
+	minDeleteGuard = 5
+)
 
-// Syncer handles bidirectional sync between Google Sheets and the database
+// triggerDebounce is how long a triggered sync waits for more triggers, so a
+// burst of RSVPs is written to the sheet in a single cycle
+const triggerDebounce = 2 * time.Second
+
+// Syncer handles bidirectional sync between the guest list source and the database
 type Syncer struct {
-	store        *store.Store
-	sheetsClient *Client
-	listener     chan struct{}
+	mu       sync.Mutex // Serializes sync cycles and invite refreshes
+	store    *store.Store
+	source   Source
+	listener chan struct{}  // Holds at most one pending trigger, see TriggerSync
+	codes    *CodeGenerator // Assigns missing invite codes on sync when set
+	removed  string         // One of the RemovedInvites* constants
+	refresh  inviteRefresher
 }
 
-// NewSyncer creates a new syncer
-func NewSyncer(s *store.Store, client *Client) *Syncer {
+// NewSyncer creates a new syncer. Invites removed from the guest list are
+// disabled, see SetRemovedInvites.
+func NewSyncer(s *store.Store, source Source) *Syncer {
 	return &Syncer{
-		store:        s,
-		sheetsClient: client,
-		listener:     make(chan struct{}),
+		store:    s,
+		source:   source,
+		listener: make(chan struct{}, 1),
+		removed:  RemovedInvitesDisable,
+		refresh: inviteRefresher{
+			interval:  defaultRefreshInterval,
+			missedTTL: defaultMissedCodeTTL,
+		},
+	}
+}
+
+// SetRemovedInvites sets what sync does with invites whose code is no longer
+// in the guest list, one of the RemovedInvites* constants
+func (s *Syncer) SetRemovedInvites(policy string) error {
+	switch policy {
+	case RemovedInvitesDisable, RemovedInvitesKeep, RemovedInvitesDelete:
+		s.removed = policy
+		return nil
+	default:
+		return fmt.Errorf("unknown removed invites policy %q, expected disable, keep or delete", policy)
 	}
 }
 
 // Start begins the background sync loop
 func (s *Syncer) Start(ctx context.Context, interval time.Duration) {
-	if !s.sheetsClient.IsConfigured() {
-		log.Println("Google Sheets sync disabled (credentials not configured)")
+	if !s.source.IsConfigured() {
+		log.Println("Guest list sync disabled (source not configured)")
 		return
 	}
 
-	log.Printf("Starting Google Sheets sync every %s", interval)
+	log.Printf("Starting guest list sync every %s", interval)
 
 	// Start ticker
 	ticker := time.NewTicker(interval)
@@ -49,163 +91,523 @@ func (s *Syncer) Start(ctx context.Context, interval time.Duration) {
 			}
 		case <-s.listener:
 			log.Println("Received manual sync request")
+			if !s.debounce(ctx) {
+				log.Println("Stopping guest list sync")
+				return
+			}
 			if err := s.SyncOnce(ctx); err != nil {
 				log.Printf("Error during manual sync: %v", err)
 			}
 		case <-ctx.Done():
-			log.Println("Stopping Google Sheets sync")
+			log.Println("Stopping guest list sync")
 			return
 		}
 	}
 }
 
-// TriggerSync signals the syncer to perform an immediate sync
+// Enabled reports whether a guest list source is configured to sync with
+func (s *Syncer) Enabled() bool {
+	return s.source.IsConfigured()
+}
+
+// TriggerSync asks the sync loop for an immediate sync without blocking.
+// Triggers while one is already pending are merged into it, and a trigger
+// during a running cycle starts one more afterwards. When sync is disabled
+// the trigger just stays pending.
 func (s *Syncer) TriggerSync() {
-	s.listener <- struct{}{}
+	select {
+	case s.listener <- struct{}{}:
+	default: // Already pending
+	}
 }
 
-// SyncOnce performs a single sync cycle (used for manual sync command)
+// debounce waits triggerDebounce after a trigger so the ones arriving
+// meanwhile join the same cycle. Returns false if ctx is done first.
+func (s *Syncer) debounce(ctx context.Context) bool {
+	timer := time.NewTimer(triggerDebounce)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return false
+	}
+
+	// The cycle about to start covers them
+	select {
+	case <-s.listener:
+	default:
+	}
+	return true
+}
+
+// SyncOnce performs a single sync cycle and records it in the sync history
 func (s *Syncer) SyncOnce(ctx context.Context) error {
-	if !s.sheetsClient.IsConfigured() {
-		return errors.New("Google Sheets credentials not configured")
+	if !s.source.IsConfigured() {
+		return errors.New("guest list source not configured")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run := &store.InsertSyncRunParams{StartedAt: time.Now().UTC()}
+	err := s.syncCycle(ctx, run)
+
+	run.FinishedAt = time.Now().UTC()
+	run.DurationMs = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	// Record the run even if the request that triggered it went away
+	if err := s.store.RecordSyncRun(context.WithoutCancel(ctx), run); err != nil {
+		log.Printf("Failed to record sync run: %v", err)
+	}
+
+	return err
+}
+
+// syncCycle syncs both directions and counts what moved into run
+func (s *Syncer) syncCycle(ctx context.Context, run *store.InsertSyncRunParams) error {
+	// Read the guest list once for code assignment and the invites
+	rows, err := s.source.ReadGuestRows(ctx)
+	if err != nil {
+		return errors.Wrap(err, "sync from sheet failed")
+	}
+
+	// Give new rows an invite code first so they are synced in this cycle.
+	// A failure doesn't hold up the rest of the cycle, but still fails the run.
+	var codesErr error
+	if s.codes != nil {
+		if _, err := s.assignCodes(ctx, s.codes, rows, false); err != nil {
+			log.Printf("Failed to assign invite codes: %v", err)
+			codesErr = errors.Wrap(err, "assign invite codes failed")
+		}
 	}
 
 	// Sync invites from sheet to DB (master data)
-	if err := s.SyncFromSheet(ctx); err != nil {
+	if run.InvitesRead, err = s.applyInvites(ctx, codedRows(rows), true); err != nil {
 		return errors.Wrap(err, "sync from sheet failed")
 	}
 
 	// Sync RSVPs from DB to sheet (responses)
-	if err := s.SyncToSheet(ctx); err != nil {
+	if run.RsvpsPushed, run.RsvpsFailed, err = s.syncToSheet(ctx); err != nil {
 		return errors.Wrap(err, "sync to sheet failed")
 	}
 
 	// Sync schedule from sheet to DB (one-way, sheet is source of truth)
-	if err := s.SyncScheduleFromSheet(ctx); err != nil {
+	if run.ScheduleEvents, err = s.syncScheduleFromSheet(ctx); err != nil {
 		return errors.Wrap(err, "sync schedule from sheet failed")
 	}
 
+	// Sync events and who is invited from sheet to DB, then their answers back
+	if _, err := s.syncEventsFromSheet(ctx); err != nil {
+		return errors.Wrap(err, "sync events from sheet failed")
+	}
+	pushed, failed, err := s.syncEventRSVPs(ctx)
+	run.RsvpsPushed += pushed
+	run.RsvpsFailed += failed
+	if err != nil {
+		return errors.Wrap(err, "sync event RSVPs to sheet failed")
+	}
+
+	if codesErr != nil {
+		return codesErr
+	}
 	log.Println("Sync cycle completed")
 	return nil
 }
 
 // SyncFromSheet reads the sheet and updates the database
 func (s *Syncer) SyncFromSheet(ctx context.Context) error {
-	rows, err := s.sheetsClient.ReadSheet(ctx)
+	_, err := s.syncFromSheet(ctx)
+	return err
+}
+
+// syncFromSheet is SyncFromSheet, returning the number of invites read
+func (s *Syncer) syncFromSheet(ctx context.Context) (int64, error) {
+	rows, err := s.source.ReadInvites(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
+	return s.applyInvites(ctx, rows, true)
+}
 
+// applyInvites updates the database with the invites read from the sheet
+// and returns how many were read. Invites missing from them are handled by
+// the removed invites policy if removeMissing is set.
+func (s *Syncer) applyInvites(ctx context.Context, rows []*store.UpsertInviteParams, removeMissing bool) (int64, error) {
 	if len(rows) == 0 {
 		log.Println("No invites found in sheet, skipping...")
-		return nil
+		return 0, nil
 	}
 
 	// Start transaction
 	tx, err := s.store.DB.Begin()
 	if err != nil {
-		return errors.Wrap(err, "failed to begin transaction")
+		return 0, errors.Wrap(err, "failed to begin transaction")
 	}
 	defer tx.Rollback()
 
 	q := s.store.WithTx(tx)
 
+	invites, err := q.ListInvites(ctx, "")
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list invites")
+	}
+	deletions, err := q.ListInviteDeletions(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list invite deletions")
+	}
+	plan := planInvites(rows, invites, deletions, s.removed)
+
 	// Upsert each row into the database
-	for _, row := range rows {
-		if err := q.UpsertInvite(ctx, row); err != nil {
+	for _, row := range plan.upserts {
+		if err := upsertInvite(ctx, q, row); err != nil {
 			log.Printf("Failed to upsert invite %s: %v", row.InviteCode, err)
 			continue
 		}
 	}
 
+	if removeMissing {
+		if err := s.removeInvites(ctx, q, plan); err != nil {
+			return 0, errors.Wrap(err, "failed to handle removed invites")
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
-		return errors.Wrap(err, "failed to commit transaction")
+		return 0, errors.Wrap(err, "failed to commit transaction")
 	}
 
 	log.Printf("Synced %d invites from sheet to database", len(rows))
 
+	return int64(len(rows)), nil
+}
+
+// removeInvites disables, keeps or deletes the invites whose code is no
+// longer in the guest list, see planInvites. Invites created through the
+// admin API that haven't been written to the sheet yet have no row and are
+// left alone.
+func (s *Syncer) removeInvites(ctx context.Context, q *store.Queries, plan *invitePlan) error {
+	removed := plan.removed
+	if len(removed) == 0 {
+		return nil
+	}
+
+	if plan.refused {
+		log.Printf("Refusing to delete %d invites missing from the sheet at once, check the guest list or delete them through the admin API",
+			len(removed))
+		return nil
+	}
+
+	var err error
+	for _, invite := range removed {
+		switch plan.policy {
+		case RemovedInvitesDisable:
+			err = q.DisableInvite(ctx, invite.InviteCode)
+		case RemovedInvitesDelete:
+			// Guests and invitations first, foreign keys aren't enforced.
+			// The RSVP history stays.
+			if err = q.DeleteGuestsByInviteCode(ctx, invite.InviteCode); err == nil {
+				err = q.DeleteInviteEventsByInviteCode(ctx, invite.InviteCode)
+			}
+			if err == nil {
+				err = q.DeleteInvite(ctx, invite.InviteCode)
+			}
+		}
+		if err != nil {
+			return errors.Wrapf(err, "invite %s", invite.InviteCode)
+		}
+	}
+
+	switch plan.policy {
+	case RemovedInvitesDisable:
+		log.Printf("Disabled %d invite(s) removed from the sheet", len(removed))
+	case RemovedInvitesDelete:
+		log.Printf("Deleted %d invite(s) removed from the sheet", len(removed))
+	default:
+		log.Printf("Keeping %d invite(s) that are no longer in the sheet", len(removed))
+	}
 	return nil
 }
 
-// SyncToSheet writes pending RSVP responses back to the sheet
+// tooManyDeleted reports whether deleting removed of total invites at once
+// is more than the delete policy allows, see maxDeleteShare
+func tooManyDeleted(removed, total int) bool {
+	return removed > minDeleteGuard && float64(removed) > maxDeleteShare*float64(total)
+}
+
+// isRemoved reports whether an invite missing from the guest list should be
+// handled by policy: it had a row, and isn't already disabled unless it's
+// now to be deleted
+func isRemoved(invite *store.Invite, policy string) bool {
+	if invite.Disabled() {
+		return policy == RemovedInvitesDelete
+	}
+	return invite.SheetRow != nil
+}
+
+// upsertInvite applies a sheet row to the database. RSVP answers edited by
+// hand in the sheet are recorded in the audit log like any other change.
+func upsertInvite(ctx context.Context, q *store.Queries, row *store.UpsertInviteParams) error {
+	old, err := q.GetInviteByInviteCode(ctx, row.InviteCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		old = nil // New invite, nothing to compare against
+	} else if err != nil {
+		return errors.Wrap(err, "failed to load invite")
+	}
+
+	// Back in the sheet, even if UpsertInvite skips it for a pending RSVP
+	if old != nil && old.Disabled() {
+		if err := q.EnableInvite(ctx, &store.EnableInviteParams{SheetRow: row.SheetRow, InviteCode: row.InviteCode}); err != nil {
+			return errors.Wrap(err, "failed to enable invite")
+		}
+	}
+
+	if err := q.UpsertInvite(ctx, row); err != nil {
+		return err
+	}
+
+	if old == nil {
+		return nil
+	}
+
+	current, err := q.GetInviteByInviteCode(ctx, row.InviteCode)
+	if err != nil {
+		return errors.Wrap(err, "failed to reload invite")
+	}
+
+	if !store.RSVPChanged(old, current) {
+		return nil
+	}
+
+	event := store.NewRSVPEvent(old, current, store.RSVPChange{Source: store.RSVPSourceSheet})
+	return q.InsertRSVPEvent(ctx, event)
+}
+
+// SyncToSheet writes pending admin changes and RSVP responses back to the sheet
 func (s *Syncer) SyncToSheet(ctx context.Context) error {
-	// Get invites that need syncing
-	invites, err := s.store.GetPendingSyncInvites(ctx)
+	_, _, err := s.syncToSheet(ctx)
+	return err
+}
+
+// syncToSheet is SyncToSheet, returning how many RSVPs were written and
+// how many are still pending
+func (s *Syncer) syncToSheet(ctx context.Context) (pushed, failed int64, err error) {
+	// Edits first so invites created through the admin API get a row
+	// before their RSVP is written
+	if err := s.syncInviteEdits(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	if pushed, failed, err = s.syncRSVPs(ctx); err != nil {
+		return pushed, failed, err
+	}
+
+	// Deletions last since removing rows shifts the ones below
+	return pushed, failed, s.syncInviteDeletions(ctx)
+}
+
+// syncInviteEdits appends invites created through the admin API and writes
+// admin edits to existing rows
+func (s *Syncer) syncInviteEdits(ctx context.Context) error {
+	invites, err := s.store.GetPendingInviteEdits(ctx)
 	if err != nil {
 		return err
 	}
 
 	if len(invites) == 0 {
-		log.Println("No pending RSVPs to sync to sheet")
 		return nil
 	}
 
-	log.Printf("Syncing %d RSVP responses to sheet", len(invites))
+	log.Printf("Syncing %d invite edits to sheet", len(invites))
 
-	tx, err := s.store.DB.Begin()
+	for _, invite := range invites {
+		if invite.SheetRow == nil {
+			rowNum, err := s.source.AppendInvite(ctx, invite)
+			if err != nil {
+				log.Printf("Failed to append invite %s: %v", invite.InviteCode, err)
+				continue
+			}
+			invite.SheetRow = &rowNum
+		} else if err := s.source.WriteInvite(ctx, invite); err != nil {
+			log.Printf("Failed to write invite %s: %v", invite.InviteCode, err)
+			continue
+		}
+
+		params := &store.MarkInviteEditSyncedParams{
+			SheetRow:       invite.SheetRow,
+			LocalChangedAt: invite.LocalChangedAt,
+			InviteCode:     invite.InviteCode,
+		}
+		if err := s.store.MarkInviteEditSynced(ctx, params); err != nil {
+			log.Printf("Failed to mark invite %s edit as synced: %v", invite.InviteCode, err)
+		}
+	}
+
+	return nil
+}
+
+// syncInviteDeletions removes the rows of invites deleted through the admin API
+func (s *Syncer) syncInviteDeletions(ctx context.Context) error {
+	deletions, err := s.store.ListInviteDeletions(ctx)
 	if err != nil {
-		return errors.Wrap(err, "failed to begin transaction")
+		return err
 	}
-	defer tx.Rollback()
 
-	q := s.store.WithTx(tx)
+	if len(deletions) == 0 {
+		return nil
+	}
+
+	log.Printf("Removing %d deleted invites from sheet", len(deletions))
+
+	// Bottom rows first, see ListInviteDeletions
+	for _, deletion := range deletions {
+		rowNum, err := s.source.DeleteInvite(ctx, deletion.InviteCode, deletion.SheetRow)
+		if errors.Is(err, ErrInviteNotFound) {
+			// Already removed by hand, the next read picks up the new rows
+			log.Printf("Invite %s already removed from sheet", deletion.InviteCode)
+			if err := s.store.DeleteInviteDeletion(ctx, deletion.InviteCode); err != nil {
+				log.Printf("Failed to clear deletion of invite %s: %v", deletion.InviteCode, err)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to remove invite %s from sheet: %v", deletion.InviteCode, err)
+			continue
+		}
 
-	// Write each invite to the sheet
+		deletion.SheetRow = rowNum
+		if err := s.store.CompleteInviteDeletion(ctx, deletion); err != nil {
+			log.Printf("Failed to clear deletion of invite %s: %v", deletion.InviteCode, err)
+		}
+	}
+
+	return nil
+}
+
+// syncRSVPs writes pending RSVP responses back to the sheet and returns how
+// many were written and how many are left pending
+func (s *Syncer) syncRSVPs(ctx context.Context) (synced, failed int64, err error) {
+	// Get invites that need syncing
+	invites, err := s.store.GetPendingSyncInvites(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(invites) == 0 {
+		log.Println("No pending RSVPs to sync to sheet")
+		return 0, 0, nil
+	}
+
+	log.Printf("Syncing %d RSVP responses to sheet", len(invites))
+
+	pending := make([]*store.Invite, 0, len(invites))
 	for _, invite := range invites {
 		if invite.SheetRow == nil {
 			log.Printf("Skipping invite %s: no sheet row number", invite.InviteCode)
 			continue
 		}
+		pending = append(pending, invite)
+	}
+
+	// Write all rows at once, then mark only the ones that were written
+	errs := s.source.WriteRSVPs(ctx, pending)
+
+	tx, err := s.store.DB.Begin()
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
 
-		if err := s.sheetsClient.WriteRSVP(ctx, invite); err != nil {
-			log.Printf("Failed to write RSVP for invite %s: %v", invite.InviteCode, err)
+	q := s.store.WithTx(tx)
+
+	for i, invite := range pending {
+		if errs[i] != nil {
+			log.Printf("Failed to write RSVP for invite %s: %v", invite.InviteCode, errs[i])
 			continue
 		}
 
-		// Mark as synced in database
-		if err := q.MarkInviteSynced(ctx, invite.InviteCode); err != nil {
+		// Mark as synced in database, only if the answer written is still the latest
+		marked, err := q.MarkInviteSynced(ctx, &store.MarkInviteSyncedParams{InviteCode: invite.InviteCode, ResponseAt: invite.ResponseAt})
+		if err != nil {
 			log.Printf("Failed to mark invite %s as synced: %v", invite.InviteCode, err)
+			continue
 		}
+		if marked == 0 {
+			log.Printf("Invite %s answered again while its RSVP was written, writing it next sync", invite.InviteCode)
+			continue
+		}
+		synced++
 	}
 
 	if err := tx.Commit(); err != nil {
-		return errors.Wrap(err, "failed to commit transaction")
+		return 0, 0, errors.Wrap(err, "failed to commit transaction")
 	}
 
-	log.Printf("Successfully synced %d RSVPs to sheet", len(invites))
-	return nil
+	log.Printf("Successfully synced %d of %d RSVPs to sheet", synced, len(invites))
+	return synced, int64(len(invites)) - synced, nil
 }
 
 // SyncScheduleFromSheet reads the schedule sheet and replaces all events in DB
 // This is a one-way sync: Google Sheets is the source of truth for schedule
 // Only public events are returned from ReadScheduleSheet, so we store everything we receive.
 func (s *Syncer) SyncScheduleFromSheet(ctx context.Context) error {
-	events, err := s.sheetsClient.ReadScheduleSheet(ctx, defaultWeddingYear)
+	_, err := s.syncScheduleFromSheet(ctx)
+	return err
+}
+
+// syncScheduleFromSheet is SyncScheduleFromSheet, returning the number of events read
+func (s *Syncer) syncScheduleFromSheet(ctx context.Context) (int64, error) {
+	events, err := s.source.ReadSchedule(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if events == nil {
-		log.Println("Schedule sync skipped (client not configured)")
-		return nil
+		log.Println("Schedule sync skipped (source has no schedule)")
+		return 0, nil
 	}
 
 	// Start transaction
 	tx, err := s.store.DB.Begin()
 	if err != nil {
-		return errors.Wrap(err, "failed to begin transaction")
+		return 0, errors.Wrap(err, "failed to begin transaction")
 	}
 	defer tx.Rollback()
 
 	q := s.store.WithTx(tx)
 
+	// Events keep their calendar UID while they're moved or renamed
+	existing, err := q.GetScheduleEvents(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list existing schedule events")
+	}
+	matches := matchSchedule(events, existing)
+
 	// Delete all existing schedule events (full replace strategy)
 	if err := q.DeleteAllScheduleEvents(ctx); err != nil {
-		return errors.Wrap(err, "failed to delete existing schedule events")
+		return 0, errors.Wrap(err, "failed to delete existing schedule events")
 	}
 
 	// Insert all events from sheet
-	for _, event := range events {
+	uids := make(map[string]bool, len(events))
+	for r, event := range events {
+		uid := ""
+		if i := matches[r]; i != -1 {
+			// Events synced before UIDs were stored get the one they were served with
+			uid = existing[i].Uid
+			if uid == "" {
+				uid = store.ScheduleEventUID(existing[i].StartTime, existing[i].EventNameEs)
+			}
+		} else {
+			uid = store.ScheduleEventUID(event.StartTime, event.EventNameES)
+		}
+		for n, base := 1, uid; uids[uid]; n++ {
+			uid = fmt.Sprintf("%s-%d", base, n)
+		}
+		uids[uid] = true
+
 		params := &store.InsertScheduleEventParams{
 			StartTime:     event.StartTime,
 			EndTime:       event.EndTime,
@@ -216,6 +618,8 @@ func (s *Syncer) SyncScheduleFromSheet(ctx context.Context) error {
 			DescriptionEs: event.DescriptionES,
 			DescriptionEn: event.DescriptionEN,
 			DescriptionCa: event.DescriptionCA,
+			Timezone:      event.Timezone,
+			Uid:           uid,
 		}
 
 		if err := q.InsertScheduleEvent(ctx, params); err != nil {
@@ -225,11 +629,11 @@ func (s *Syncer) SyncScheduleFromSheet(ctx context.Context) error {
 	}
 
 	if err := tx.Commit(); err != nil {
-		return errors.Wrap(err, "failed to commit transaction")
+		return 0, errors.Wrap(err, "failed to commit transaction")
 	}
 
 	log.Printf("Synced %d schedule events from sheet to database", len(events))
-	return nil
+	return int64(len(events)), nil
 }
 
 // toNullString converts a string to sql.NullString equivalent (empty string for NULL)
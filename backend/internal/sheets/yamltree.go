@@ -0,0 +1,139 @@
+package sheets
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Writes to a YAML guest list edit its node tree rather than re-encoding
+// guestListFile, so they keep the file's comments, key order and any keys
+// this package doesn't know. Items of the decoded lists are at the same
+// index as their nodes.
+
+// yamlRoot returns the top-level mapping of a YAML document, adding one to
+// an empty document
+func yamlRoot(doc *yaml.Node) (*yaml.Node, error) {
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if doc.Kind != yaml.DocumentNode {
+		return nil, fmt.Errorf("expected a YAML document")
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping at the top of the guest list")
+	}
+	return root, nil
+}
+
+// defaultYAMLIndent is the indentation of files without nested blocks to tell
+// theirs from
+const defaultYAMLIndent = 4
+
+// yamlIndent returns the indentation the document uses, from the first
+// nested block of its top-level mapping
+func yamlIndent(doc *yaml.Node) int {
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return defaultYAMLIndent
+	}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if value.Style&yaml.FlowStyle != 0 || len(value.Content) == 0 {
+			continue
+		}
+		if indent := value.Column - key.Column; (value.Kind == yaml.SequenceNode || value.Kind == yaml.MappingNode) && indent >= 2 {
+			return indent
+		}
+	}
+	return defaultYAMLIndent
+}
+
+// yamlValue returns the value of key in a mapping, nil if it has none
+func yamlValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlItems returns the sequence under key in a mapping, adding an empty one
+// if the key is missing or empty
+func yamlItems(m *yaml.Node, key string) (*yaml.Node, error) {
+	items := yamlValue(m, key)
+	switch {
+	case items == nil:
+		items = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, items)
+	case items.Kind == yaml.ScalarNode && items.Tag == "!!null":
+		items.Kind, items.Tag, items.Value = yaml.SequenceNode, "!!seq", ""
+	case items.Kind != yaml.SequenceNode:
+		return nil, fmt.Errorf("expected a list under %s", key)
+	}
+	return items, nil
+}
+
+// yamlItem returns the i-th item of the sequence under key, which must be
+// a mapping
+func yamlItem(m *yaml.Node, key string, i int) (*yaml.Node, error) {
+	items := yamlValue(m, key)
+	if items == nil || items.Kind != yaml.SequenceNode || i < 0 || i >= len(items.Content) {
+		return nil, fmt.Errorf("%s item %d not found", key, i+1)
+	}
+	if item := items.Content[i]; item.Kind == yaml.MappingNode {
+		return item, nil
+	}
+	return nil, fmt.Errorf("%s item %d is not a mapping", key, i+1)
+}
+
+// setYAML sets key in a mapping to value, keeping the comments of the value
+// it replaces. Like omitempty, a zero value isn't added to a mapping
+// without the key.
+func setYAML(m *yaml.Node, key string, value interface{}) error {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+
+	if old := yamlValue(m, key); old != nil {
+		node.HeadComment, node.LineComment, node.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		*old = node
+		return nil
+	}
+
+	if v := reflect.ValueOf(value); !v.IsValid() || v.IsZero() {
+		return nil
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &node)
+	return nil
+}
+
+// yamlField is a key and the value setYAMLFields sets it to
+type yamlField struct {
+	Key   string
+	Value interface{}
+}
+
+// setYAMLFields sets fields of the i-th item of the sequence under key
+func setYAMLFields(m *yaml.Node, key string, i int, fields []yamlField) error {
+	item, err := yamlItem(m, key, i)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if err := setYAML(item, field.Key, field.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
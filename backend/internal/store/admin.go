@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInviteExists is returned when creating an invite whose code is taken
+	ErrInviteExists = errors.New("invite code already exists")
+
+	// ErrInviteDeletionPending is returned when creating an invite whose code
+	// belongs to a deleted invite that is still in the sheet
+	ErrInviteDeletionPending = errors.New("invite code was deleted and is still pending removal from the sheet")
+
+	// ErrInviteLimitsTooLow is returned when new limits are below the confirmed counts
+	ErrInviteLimitsTooLow = errors.New("limits are below the already confirmed counts")
+)
+
+// AddInvite creates an invite from the admin API. It is appended to the
+// sheet on the next sync.
+func (s *Store) AddInvite(ctx context.Context, params *CreateInviteParams) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := s.WithTx(tx)
+
+	if _, err := q.GetInviteByInviteCode(ctx, params.InviteCode); err == nil {
+		return ErrInviteExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to load invite: %w", err)
+	}
+
+	if _, err := q.GetInviteDeletion(ctx, params.InviteCode); err == nil {
+		return ErrInviteDeletionPending
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to load invite deletion: %w", err)
+	}
+
+	if err := q.CreateInvite(ctx, params); err != nil {
+		return fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// EditInvite updates an invite's name and limits from the admin API.
+// The new values are written to the sheet on the next sync.
+func (s *Store) EditInvite(ctx context.Context, params *UpdateInviteDetailsParams) error {
+	updated, err := s.UpdateInviteDetails(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to update invite: %w", err)
+	}
+	if updated == 0 {
+		return ErrInviteLimitsTooLow
+	}
+	return nil
+}
+
+// RemoveInvite deletes an invite, its guests and its event invitations. If the invite has a sheet
+// row, its removal from the sheet is queued for the next sync. The RSVP
+// audit log is kept.
+func (s *Store) RemoveInvite(ctx context.Context, inviteCode string) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := s.WithTx(tx)
+
+	invite, err := q.GetInviteByInviteCode(ctx, inviteCode)
+	if err != nil {
+		return fmt.Errorf("failed to load invite: %w", err)
+	}
+
+	if err := q.DeleteGuestsByInviteCode(ctx, inviteCode); err != nil {
+		return fmt.Errorf("failed to delete guests: %w", err)
+	}
+	if err := q.DeleteInviteEventsByInviteCode(ctx, inviteCode); err != nil {
+		return fmt.Errorf("failed to delete event invitations: %w", err)
+	}
+	if err := q.DeleteInvite(ctx, inviteCode); err != nil {
+		return fmt.Errorf("failed to delete invite: %w", err)
+	}
+
+	// Invites created through the admin API and not yet synced have no row
+	if invite.SheetRow != nil {
+		params := &InsertInviteDeletionParams{InviteCode: inviteCode, SheetRow: *invite.SheetRow}
+		if err := q.InsertInviteDeletion(ctx, params); err != nil {
+			return fmt.Errorf("failed to queue sheet row removal: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CompleteInviteDeletion clears a queued deletion once its sheet row is gone
+// and moves every row below it up by one, like the sheet does.
+func (s *Store) CompleteInviteDeletion(ctx context.Context, deletion *InviteDeletion) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := s.WithTx(tx)
+
+	if err := q.DeleteInviteDeletion(ctx, deletion.InviteCode); err != nil {
+		return fmt.Errorf("failed to clear invite deletion: %w", err)
+	}
+	if err := q.ShiftInviteRows(ctx, deletion.SheetRow); err != nil {
+		return fmt.Errorf("failed to shift invite rows: %w", err)
+	}
+	if err := q.ShiftInviteDeletionRows(ctx, deletion.SheetRow); err != nil {
+		return fmt.Errorf("failed to shift invite deletion rows: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Disabled reports whether the invite's row was removed from the sheet
+func (i *Invite) Disabled() bool {
+	return i.DisabledAt != nil
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern, see EscapeLike
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escapes % and _ so a search matches them literally in queries
+// that use ESCAPE '\', like ListInvites
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -24,53 +24,273 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.createInviteStmt, err = db.PrepareContext(ctx, CreateInvite); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateInvite: %w", err)
+	}
 	if q.deleteAllScheduleEventsStmt, err = db.PrepareContext(ctx, DeleteAllScheduleEvents); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteAllScheduleEvents: %w", err)
 	}
+	if q.deleteEventStmt, err = db.PrepareContext(ctx, DeleteEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteEvent: %w", err)
+	}
+	if q.deleteGuestsByInviteCodeStmt, err = db.PrepareContext(ctx, DeleteGuestsByInviteCode); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteGuestsByInviteCode: %w", err)
+	}
 	if q.deleteInviteStmt, err = db.PrepareContext(ctx, DeleteInvite); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteInvite: %w", err)
 	}
+	if q.deleteInviteDeletionStmt, err = db.PrepareContext(ctx, DeleteInviteDeletion); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteInviteDeletion: %w", err)
+	}
+	if q.deleteInviteEventStmt, err = db.PrepareContext(ctx, DeleteInviteEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteInviteEvent: %w", err)
+	}
+	if q.deleteInviteEventsByEventStmt, err = db.PrepareContext(ctx, DeleteInviteEventsByEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteInviteEventsByEvent: %w", err)
+	}
+	if q.deleteInviteEventsByInviteCodeStmt, err = db.PrepareContext(ctx, DeleteInviteEventsByInviteCode); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteInviteEventsByInviteCode: %w", err)
+	}
+	if q.deleteOldSyncRunsStmt, err = db.PrepareContext(ctx, DeleteOldSyncRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteOldSyncRuns: %w", err)
+	}
+	if q.disableInviteStmt, err = db.PrepareContext(ctx, DisableInvite); err != nil {
+		return nil, fmt.Errorf("error preparing query DisableInvite: %w", err)
+	}
+	if q.enableInviteStmt, err = db.PrepareContext(ctx, EnableInvite); err != nil {
+		return nil, fmt.Errorf("error preparing query EnableInvite: %w", err)
+	}
+	if q.enqueueEmailStmt, err = db.PrepareContext(ctx, EnqueueEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query EnqueueEmail: %w", err)
+	}
 	if q.getInviteByInviteCodeStmt, err = db.PrepareContext(ctx, GetInviteByInviteCode); err != nil {
 		return nil, fmt.Errorf("error preparing query GetInviteByInviteCode: %w", err)
 	}
+	if q.getInviteDeletionStmt, err = db.PrepareContext(ctx, GetInviteDeletion); err != nil {
+		return nil, fmt.Errorf("error preparing query GetInviteDeletion: %w", err)
+	}
+	if q.getInviteStatsStmt, err = db.PrepareContext(ctx, GetInviteStats); err != nil {
+		return nil, fmt.Errorf("error preparing query GetInviteStats: %w", err)
+	}
+	if q.getLastDigestAtStmt, err = db.PrepareContext(ctx, GetLastDigestAt); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLastDigestAt: %w", err)
+	}
+	if q.getLastSuccessfulSyncRunStmt, err = db.PrepareContext(ctx, GetLastSuccessfulSyncRun); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLastSuccessfulSyncRun: %w", err)
+	}
+	if q.getPendingInviteEditsStmt, err = db.PrepareContext(ctx, GetPendingInviteEdits); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPendingInviteEdits: %w", err)
+	}
+	if q.getPendingInviteEventSyncsStmt, err = db.PrepareContext(ctx, GetPendingInviteEventSyncs); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPendingInviteEventSyncs: %w", err)
+	}
 	if q.getPendingSyncInvitesStmt, err = db.PrepareContext(ctx, GetPendingSyncInvites); err != nil {
 		return nil, fmt.Errorf("error preparing query GetPendingSyncInvites: %w", err)
 	}
 	if q.getScheduleEventsStmt, err = db.PrepareContext(ctx, GetScheduleEvents); err != nil {
 		return nil, fmt.Errorf("error preparing query GetScheduleEvents: %w", err)
 	}
+	if q.insertGuestStmt, err = db.PrepareContext(ctx, InsertGuest); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertGuest: %w", err)
+	}
+	if q.insertInviteDeletionStmt, err = db.PrepareContext(ctx, InsertInviteDeletion); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertInviteDeletion: %w", err)
+	}
+	if q.insertRSVPEventStmt, err = db.PrepareContext(ctx, InsertRSVPEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertRSVPEvent: %w", err)
+	}
 	if q.insertScheduleEventStmt, err = db.PrepareContext(ctx, InsertScheduleEvent); err != nil {
 		return nil, fmt.Errorf("error preparing query InsertScheduleEvent: %w", err)
 	}
+	if q.insertSyncRunStmt, err = db.PrepareContext(ctx, InsertSyncRun); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertSyncRun: %w", err)
+	}
+	if q.listDietaryInfoCountsStmt, err = db.PrepareContext(ctx, ListDietaryInfoCounts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDietaryInfoCounts: %w", err)
+	}
+	if q.listDueEmailsStmt, err = db.PrepareContext(ctx, ListDueEmails); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDueEmails: %w", err)
+	}
+	if q.listEventsStmt, err = db.PrepareContext(ctx, ListEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query ListEvents: %w", err)
+	}
+	if q.listGuestsByInviteCodeStmt, err = db.PrepareContext(ctx, ListGuestsByInviteCode); err != nil {
+		return nil, fmt.Errorf("error preparing query ListGuestsByInviteCode: %w", err)
+	}
+	if q.listInviteDeletionsStmt, err = db.PrepareContext(ctx, ListInviteDeletions); err != nil {
+		return nil, fmt.Errorf("error preparing query ListInviteDeletions: %w", err)
+	}
+	if q.listInviteEventsByEventStmt, err = db.PrepareContext(ctx, ListInviteEventsByEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query ListInviteEventsByEvent: %w", err)
+	}
+	if q.listInviteEventsByInviteCodeStmt, err = db.PrepareContext(ctx, ListInviteEventsByInviteCode); err != nil {
+		return nil, fmt.Errorf("error preparing query ListInviteEventsByInviteCode: %w", err)
+	}
+	if q.listInvitesStmt, err = db.PrepareContext(ctx, ListInvites); err != nil {
+		return nil, fmt.Errorf("error preparing query ListInvites: %w", err)
+	}
+	if q.listMealChoiceCountsStmt, err = db.PrepareContext(ctx, ListMealChoiceCounts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMealChoiceCounts: %w", err)
+	}
+	if q.listRSVPEventsByInviteCodeStmt, err = db.PrepareContext(ctx, ListRSVPEventsByInviteCode); err != nil {
+		return nil, fmt.Errorf("error preparing query ListRSVPEventsByInviteCode: %w", err)
+	}
+	if q.listRSVPEventsSinceStmt, err = db.PrepareContext(ctx, ListRSVPEventsSince); err != nil {
+		return nil, fmt.Errorf("error preparing query ListRSVPEventsSince: %w", err)
+	}
+	if q.listResponsesByLastAnswerDayStmt, err = db.PrepareContext(ctx, ListResponsesByLastAnswerDay); err != nil {
+		return nil, fmt.Errorf("error preparing query ListResponsesByLastAnswerDay: %w", err)
+	}
+	if q.listSyncRunsStmt, err = db.PrepareContext(ctx, ListSyncRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSyncRuns: %w", err)
+	}
+	if q.markEmailFailedStmt, err = db.PrepareContext(ctx, MarkEmailFailed); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkEmailFailed: %w", err)
+	}
+	if q.markEmailSentStmt, err = db.PrepareContext(ctx, MarkEmailSent); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkEmailSent: %w", err)
+	}
+	if q.markInviteEditSyncedStmt, err = db.PrepareContext(ctx, MarkInviteEditSynced); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkInviteEditSynced: %w", err)
+	}
+	if q.markInviteEventSyncedStmt, err = db.PrepareContext(ctx, MarkInviteEventSynced); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkInviteEventSynced: %w", err)
+	}
 	if q.markInviteSyncedStmt, err = db.PrepareContext(ctx, MarkInviteSynced); err != nil {
 		return nil, fmt.Errorf("error preparing query MarkInviteSynced: %w", err)
 	}
+	if q.shiftInviteDeletionRowsStmt, err = db.PrepareContext(ctx, ShiftInviteDeletionRows); err != nil {
+		return nil, fmt.Errorf("error preparing query ShiftInviteDeletionRows: %w", err)
+	}
+	if q.shiftInviteRowsStmt, err = db.PrepareContext(ctx, ShiftInviteRows); err != nil {
+		return nil, fmt.Errorf("error preparing query ShiftInviteRows: %w", err)
+	}
+	if q.updateInviteContactStmt, err = db.PrepareContext(ctx, UpdateInviteContact); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateInviteContact: %w", err)
+	}
+	if q.updateInviteDetailsStmt, err = db.PrepareContext(ctx, UpdateInviteDetails); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateInviteDetails: %w", err)
+	}
+	if q.updateInviteEventRSVPStmt, err = db.PrepareContext(ctx, UpdateInviteEventRSVP); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateInviteEventRSVP: %w", err)
+	}
 	if q.updateRSVPStmt, err = db.PrepareContext(ctx, UpdateRSVP); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateRSVP: %w", err)
 	}
+	if q.upsertEventStmt, err = db.PrepareContext(ctx, UpsertEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertEvent: %w", err)
+	}
 	if q.upsertInviteStmt, err = db.PrepareContext(ctx, UpsertInvite); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertInvite: %w", err)
 	}
+	if q.upsertInviteEventStmt, err = db.PrepareContext(ctx, UpsertInviteEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertInviteEvent: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.createInviteStmt != nil {
+		if cerr := q.createInviteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createInviteStmt: %w", cerr)
+		}
+	}
 	if q.deleteAllScheduleEventsStmt != nil {
 		if cerr := q.deleteAllScheduleEventsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteAllScheduleEventsStmt: %w", cerr)
 		}
 	}
+	if q.deleteEventStmt != nil {
+		if cerr := q.deleteEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteEventStmt: %w", cerr)
+		}
+	}
+	if q.deleteGuestsByInviteCodeStmt != nil {
+		if cerr := q.deleteGuestsByInviteCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteGuestsByInviteCodeStmt: %w", cerr)
+		}
+	}
 	if q.deleteInviteStmt != nil {
 		if cerr := q.deleteInviteStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteInviteStmt: %w", cerr)
 		}
 	}
+	if q.deleteInviteDeletionStmt != nil {
+		if cerr := q.deleteInviteDeletionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteInviteDeletionStmt: %w", cerr)
+		}
+	}
+	if q.deleteInviteEventStmt != nil {
+		if cerr := q.deleteInviteEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteInviteEventStmt: %w", cerr)
+		}
+	}
+	if q.deleteInviteEventsByEventStmt != nil {
+		if cerr := q.deleteInviteEventsByEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteInviteEventsByEventStmt: %w", cerr)
+		}
+	}
+	if q.deleteInviteEventsByInviteCodeStmt != nil {
+		if cerr := q.deleteInviteEventsByInviteCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteInviteEventsByInviteCodeStmt: %w", cerr)
+		}
+	}
+	if q.deleteOldSyncRunsStmt != nil {
+		if cerr := q.deleteOldSyncRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteOldSyncRunsStmt: %w", cerr)
+		}
+	}
+	if q.disableInviteStmt != nil {
+		if cerr := q.disableInviteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing disableInviteStmt: %w", cerr)
+		}
+	}
+	if q.enableInviteStmt != nil {
+		if cerr := q.enableInviteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing enableInviteStmt: %w", cerr)
+		}
+	}
+	if q.enqueueEmailStmt != nil {
+		if cerr := q.enqueueEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing enqueueEmailStmt: %w", cerr)
+		}
+	}
 	if q.getInviteByInviteCodeStmt != nil {
 		if cerr := q.getInviteByInviteCodeStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getInviteByInviteCodeStmt: %w", cerr)
 		}
 	}
+	if q.getInviteDeletionStmt != nil {
+		if cerr := q.getInviteDeletionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getInviteDeletionStmt: %w", cerr)
+		}
+	}
+	if q.getInviteStatsStmt != nil {
+		if cerr := q.getInviteStatsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getInviteStatsStmt: %w", cerr)
+		}
+	}
+	if q.getLastDigestAtStmt != nil {
+		if cerr := q.getLastDigestAtStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLastDigestAtStmt: %w", cerr)
+		}
+	}
+	if q.getLastSuccessfulSyncRunStmt != nil {
+		if cerr := q.getLastSuccessfulSyncRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLastSuccessfulSyncRunStmt: %w", cerr)
+		}
+	}
+	if q.getPendingInviteEditsStmt != nil {
+		if cerr := q.getPendingInviteEditsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPendingInviteEditsStmt: %w", cerr)
+		}
+	}
+	if q.getPendingInviteEventSyncsStmt != nil {
+		if cerr := q.getPendingInviteEventSyncsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPendingInviteEventSyncsStmt: %w", cerr)
+		}
+	}
 	if q.getPendingSyncInvitesStmt != nil {
 		if cerr := q.getPendingSyncInvitesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getPendingSyncInvitesStmt: %w", cerr)
@@ -81,26 +301,166 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getScheduleEventsStmt: %w", cerr)
 		}
 	}
+	if q.insertGuestStmt != nil {
+		if cerr := q.insertGuestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertGuestStmt: %w", cerr)
+		}
+	}
+	if q.insertInviteDeletionStmt != nil {
+		if cerr := q.insertInviteDeletionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertInviteDeletionStmt: %w", cerr)
+		}
+	}
+	if q.insertRSVPEventStmt != nil {
+		if cerr := q.insertRSVPEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertRSVPEventStmt: %w", cerr)
+		}
+	}
 	if q.insertScheduleEventStmt != nil {
 		if cerr := q.insertScheduleEventStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing insertScheduleEventStmt: %w", cerr)
 		}
 	}
+	if q.insertSyncRunStmt != nil {
+		if cerr := q.insertSyncRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertSyncRunStmt: %w", cerr)
+		}
+	}
+	if q.listDietaryInfoCountsStmt != nil {
+		if cerr := q.listDietaryInfoCountsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDietaryInfoCountsStmt: %w", cerr)
+		}
+	}
+	if q.listDueEmailsStmt != nil {
+		if cerr := q.listDueEmailsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDueEmailsStmt: %w", cerr)
+		}
+	}
+	if q.listEventsStmt != nil {
+		if cerr := q.listEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listEventsStmt: %w", cerr)
+		}
+	}
+	if q.listGuestsByInviteCodeStmt != nil {
+		if cerr := q.listGuestsByInviteCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listGuestsByInviteCodeStmt: %w", cerr)
+		}
+	}
+	if q.listInviteDeletionsStmt != nil {
+		if cerr := q.listInviteDeletionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listInviteDeletionsStmt: %w", cerr)
+		}
+	}
+	if q.listInviteEventsByEventStmt != nil {
+		if cerr := q.listInviteEventsByEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listInviteEventsByEventStmt: %w", cerr)
+		}
+	}
+	if q.listInviteEventsByInviteCodeStmt != nil {
+		if cerr := q.listInviteEventsByInviteCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listInviteEventsByInviteCodeStmt: %w", cerr)
+		}
+	}
+	if q.listInvitesStmt != nil {
+		if cerr := q.listInvitesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listInvitesStmt: %w", cerr)
+		}
+	}
+	if q.listMealChoiceCountsStmt != nil {
+		if cerr := q.listMealChoiceCountsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMealChoiceCountsStmt: %w", cerr)
+		}
+	}
+	if q.listRSVPEventsByInviteCodeStmt != nil {
+		if cerr := q.listRSVPEventsByInviteCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listRSVPEventsByInviteCodeStmt: %w", cerr)
+		}
+	}
+	if q.listRSVPEventsSinceStmt != nil {
+		if cerr := q.listRSVPEventsSinceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listRSVPEventsSinceStmt: %w", cerr)
+		}
+	}
+	if q.listResponsesByLastAnswerDayStmt != nil {
+		if cerr := q.listResponsesByLastAnswerDayStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listResponsesByLastAnswerDayStmt: %w", cerr)
+		}
+	}
+	if q.listSyncRunsStmt != nil {
+		if cerr := q.listSyncRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSyncRunsStmt: %w", cerr)
+		}
+	}
+	if q.markEmailFailedStmt != nil {
+		if cerr := q.markEmailFailedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markEmailFailedStmt: %w", cerr)
+		}
+	}
+	if q.markEmailSentStmt != nil {
+		if cerr := q.markEmailSentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markEmailSentStmt: %w", cerr)
+		}
+	}
+	if q.markInviteEditSyncedStmt != nil {
+		if cerr := q.markInviteEditSyncedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markInviteEditSyncedStmt: %w", cerr)
+		}
+	}
+	if q.markInviteEventSyncedStmt != nil {
+		if cerr := q.markInviteEventSyncedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markInviteEventSyncedStmt: %w", cerr)
+		}
+	}
 	if q.markInviteSyncedStmt != nil {
 		if cerr := q.markInviteSyncedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing markInviteSyncedStmt: %w", cerr)
 		}
 	}
+	if q.shiftInviteDeletionRowsStmt != nil {
+		if cerr := q.shiftInviteDeletionRowsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing shiftInviteDeletionRowsStmt: %w", cerr)
+		}
+	}
+	if q.shiftInviteRowsStmt != nil {
+		if cerr := q.shiftInviteRowsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing shiftInviteRowsStmt: %w", cerr)
+		}
+	}
+	if q.updateInviteContactStmt != nil {
+		if cerr := q.updateInviteContactStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateInviteContactStmt: %w", cerr)
+		}
+	}
+	if q.updateInviteDetailsStmt != nil {
+		if cerr := q.updateInviteDetailsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateInviteDetailsStmt: %w", cerr)
+		}
+	}
+	if q.updateInviteEventRSVPStmt != nil {
+		if cerr := q.updateInviteEventRSVPStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateInviteEventRSVPStmt: %w", cerr)
+		}
+	}
 	if q.updateRSVPStmt != nil {
 		if cerr := q.updateRSVPStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateRSVPStmt: %w", cerr)
 		}
 	}
+	if q.upsertEventStmt != nil {
+		if cerr := q.upsertEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertEventStmt: %w", cerr)
+		}
+	}
 	if q.upsertInviteStmt != nil {
 		if cerr := q.upsertInviteStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing upsertInviteStmt: %w", cerr)
 		}
 	}
+	if q.upsertInviteEventStmt != nil {
+		if cerr := q.upsertInviteEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertInviteEventStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -138,31 +498,121 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                          DBTX
-	tx                          *sql.Tx
-	deleteAllScheduleEventsStmt *sql.Stmt
-	deleteInviteStmt            *sql.Stmt
-	getInviteByInviteCodeStmt   *sql.Stmt
-	getPendingSyncInvitesStmt   *sql.Stmt
-	getScheduleEventsStmt       *sql.Stmt
-	insertScheduleEventStmt     *sql.Stmt
-	markInviteSyncedStmt        *sql.Stmt
-	updateRSVPStmt              *sql.Stmt
-	upsertInviteStmt            *sql.Stmt
+	db                                 DBTX
+	tx                                 *sql.Tx
+	createInviteStmt                   *sql.Stmt
+	deleteAllScheduleEventsStmt        *sql.Stmt
+	deleteEventStmt                    *sql.Stmt
+	deleteGuestsByInviteCodeStmt       *sql.Stmt
+	deleteInviteStmt                   *sql.Stmt
+	deleteInviteDeletionStmt           *sql.Stmt
+	deleteInviteEventStmt              *sql.Stmt
+	deleteInviteEventsByEventStmt      *sql.Stmt
+	deleteInviteEventsByInviteCodeStmt *sql.Stmt
+	deleteOldSyncRunsStmt              *sql.Stmt
+	disableInviteStmt                  *sql.Stmt
+	enableInviteStmt                   *sql.Stmt
+	enqueueEmailStmt                   *sql.Stmt
+	getInviteByInviteCodeStmt          *sql.Stmt
+	getInviteDeletionStmt              *sql.Stmt
+	getInviteStatsStmt                 *sql.Stmt
+	getLastDigestAtStmt                *sql.Stmt
+	getLastSuccessfulSyncRunStmt       *sql.Stmt
+	getPendingInviteEditsStmt          *sql.Stmt
+	getPendingInviteEventSyncsStmt     *sql.Stmt
+	getPendingSyncInvitesStmt          *sql.Stmt
+	getScheduleEventsStmt              *sql.Stmt
+	insertGuestStmt                    *sql.Stmt
+	insertInviteDeletionStmt           *sql.Stmt
+	insertRSVPEventStmt                *sql.Stmt
+	insertScheduleEventStmt            *sql.Stmt
+	insertSyncRunStmt                  *sql.Stmt
+	listDietaryInfoCountsStmt          *sql.Stmt
+	listDueEmailsStmt                  *sql.Stmt
+	listEventsStmt                     *sql.Stmt
+	listGuestsByInviteCodeStmt         *sql.Stmt
+	listInviteDeletionsStmt            *sql.Stmt
+	listInviteEventsByEventStmt        *sql.Stmt
+	listInviteEventsByInviteCodeStmt   *sql.Stmt
+	listInvitesStmt                    *sql.Stmt
+	listMealChoiceCountsStmt           *sql.Stmt
+	listRSVPEventsByInviteCodeStmt     *sql.Stmt
+	listRSVPEventsSinceStmt            *sql.Stmt
+	listResponsesByLastAnswerDayStmt   *sql.Stmt
+	listSyncRunsStmt                   *sql.Stmt
+	markEmailFailedStmt                *sql.Stmt
+	markEmailSentStmt                  *sql.Stmt
+	markInviteEditSyncedStmt           *sql.Stmt
+	markInviteEventSyncedStmt          *sql.Stmt
+	markInviteSyncedStmt               *sql.Stmt
+	shiftInviteDeletionRowsStmt        *sql.Stmt
+	shiftInviteRowsStmt                *sql.Stmt
+	updateInviteContactStmt            *sql.Stmt
+	updateInviteDetailsStmt            *sql.Stmt
+	updateInviteEventRSVPStmt          *sql.Stmt
+	updateRSVPStmt                     *sql.Stmt
+	upsertEventStmt                    *sql.Stmt
+	upsertInviteStmt                   *sql.Stmt
+	upsertInviteEventStmt              *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                          tx,
-		tx:                          tx,
-		deleteAllScheduleEventsStmt: q.deleteAllScheduleEventsStmt,
-		deleteInviteStmt:            q.deleteInviteStmt,
-		getInviteByInviteCodeStmt:   q.getInviteByInviteCodeStmt,
-		getPendingSyncInvitesStmt:   q.getPendingSyncInvitesStmt,
-		getScheduleEventsStmt:       q.getScheduleEventsStmt,
-		insertScheduleEventStmt:     q.insertScheduleEventStmt,
-		markInviteSyncedStmt:        q.markInviteSyncedStmt,
-		updateRSVPStmt:              q.updateRSVPStmt,
-		upsertInviteStmt:            q.upsertInviteStmt,
+		db:                                 tx,
+		tx:                                 tx,
+		createInviteStmt:                   q.createInviteStmt,
+		deleteAllScheduleEventsStmt:        q.deleteAllScheduleEventsStmt,
+		deleteEventStmt:                    q.deleteEventStmt,
+		deleteGuestsByInviteCodeStmt:       q.deleteGuestsByInviteCodeStmt,
+		deleteInviteStmt:                   q.deleteInviteStmt,
+		deleteInviteDeletionStmt:           q.deleteInviteDeletionStmt,
+		deleteInviteEventStmt:              q.deleteInviteEventStmt,
+		deleteInviteEventsByEventStmt:      q.deleteInviteEventsByEventStmt,
+		deleteInviteEventsByInviteCodeStmt: q.deleteInviteEventsByInviteCodeStmt,
+		deleteOldSyncRunsStmt:              q.deleteOldSyncRunsStmt,
+		disableInviteStmt:                  q.disableInviteStmt,
+		enableInviteStmt:                   q.enableInviteStmt,
+		enqueueEmailStmt:                   q.enqueueEmailStmt,
+		getInviteByInviteCodeStmt:          q.getInviteByInviteCodeStmt,
+		getInviteDeletionStmt:              q.getInviteDeletionStmt,
+		getInviteStatsStmt:                 q.getInviteStatsStmt,
+		getLastDigestAtStmt:                q.getLastDigestAtStmt,
+		getLastSuccessfulSyncRunStmt:       q.getLastSuccessfulSyncRunStmt,
+		getPendingInviteEditsStmt:          q.getPendingInviteEditsStmt,
+		getPendingInviteEventSyncsStmt:     q.getPendingInviteEventSyncsStmt,
+		getPendingSyncInvitesStmt:          q.getPendingSyncInvitesStmt,
+		getScheduleEventsStmt:              q.getScheduleEventsStmt,
+		insertGuestStmt:                    q.insertGuestStmt,
+		insertInviteDeletionStmt:           q.insertInviteDeletionStmt,
+		insertRSVPEventStmt:                q.insertRSVPEventStmt,
+		insertScheduleEventStmt:            q.insertScheduleEventStmt,
+		insertSyncRunStmt:                  q.insertSyncRunStmt,
+		listDietaryInfoCountsStmt:          q.listDietaryInfoCountsStmt,
+		listDueEmailsStmt:                  q.listDueEmailsStmt,
+		listEventsStmt:                     q.listEventsStmt,
+		listGuestsByInviteCodeStmt:         q.listGuestsByInviteCodeStmt,
+		listInviteDeletionsStmt:            q.listInviteDeletionsStmt,
+		listInviteEventsByEventStmt:        q.listInviteEventsByEventStmt,
+		listInviteEventsByInviteCodeStmt:   q.listInviteEventsByInviteCodeStmt,
+		listInvitesStmt:                    q.listInvitesStmt,
+		listMealChoiceCountsStmt:           q.listMealChoiceCountsStmt,
+		listRSVPEventsByInviteCodeStmt:     q.listRSVPEventsByInviteCodeStmt,
+		listRSVPEventsSinceStmt:            q.listRSVPEventsSinceStmt,
+		listResponsesByLastAnswerDayStmt:   q.listResponsesByLastAnswerDayStmt,
+		listSyncRunsStmt:                   q.listSyncRunsStmt,
+		markEmailFailedStmt:                q.markEmailFailedStmt,
+		markEmailSentStmt:                  q.markEmailSentStmt,
+		markInviteEditSyncedStmt:           q.markInviteEditSyncedStmt,
+		markInviteEventSyncedStmt:          q.markInviteEventSyncedStmt,
+		markInviteSyncedStmt:               q.markInviteSyncedStmt,
+		shiftInviteDeletionRowsStmt:        q.shiftInviteDeletionRowsStmt,
+		shiftInviteRowsStmt:                q.shiftInviteRowsStmt,
+		updateInviteContactStmt:            q.updateInviteContactStmt,
+		updateInviteDetailsStmt:            q.updateInviteDetailsStmt,
+		updateInviteEventRSVPStmt:          q.updateInviteEventRSVPStmt,
+		updateRSVPStmt:                     q.updateRSVPStmt,
+		upsertEventStmt:                    q.upsertEventStmt,
+		upsertInviteStmt:                   q.upsertInviteStmt,
+		upsertInviteEventStmt:              q.upsertInviteEventStmt,
 	}
 }
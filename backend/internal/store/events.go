@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// InvitedEvent is an event an invite is invited to, with the invite's limits
+// and answer for it
+type InvitedEvent struct {
+	Event      *Event
+	Invitation *InviteEvent
+}
+
+// ListInvitedEvents returns the events an invite is invited to, in the order
+// of the Events tab
+func (s *Store) ListInvitedEvents(ctx context.Context, inviteCode string) ([]*InvitedEvent, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := s.WithTx(tx)
+
+	invitations, err := q.ListInviteEventsByInviteCode(ctx, inviteCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event invitations: %w", err)
+	}
+	invited := make([]*InvitedEvent, 0, len(invitations))
+	if len(invitations) == 0 {
+		return invited, nil
+	}
+
+	events, err := q.ListEvents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	byKey := make(map[string]*Event, len(events))
+	for _, event := range events {
+		byKey[event.EventKey] = event
+	}
+	for _, invitation := range invitations {
+		invited = append(invited, &InvitedEvent{Event: byKey[invitation.EventKey], Invitation: invitation})
+	}
+
+	return invited, nil
+}
+
+// Invitations returns the invitations of invited events
+func Invitations(invited []*InvitedEvent) []*InviteEvent {
+	invitations := make([]*InviteEvent, len(invited))
+	for i, event := range invited {
+		invitations[i] = event.Invitation
+	}
+	return invitations
+}
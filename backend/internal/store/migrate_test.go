@@ -0,0 +1,171 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/casassg/wedding/backend/migrations"
+)
+
+// openTestStore opens an empty database in a temporary directory
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "wedding.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.DB.Close() })
+	return s
+}
+
+// schema returns the SQL of every table and index, keyed by type and name
+func schema(t *testing.T, s *Store) map[string]string {
+	t.Helper()
+	rows, err := s.DB.Query("SELECT type, name, coalesce(sql, '') FROM sqlite_master WHERE name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	objects := map[string]string{}
+	for rows.Next() {
+		var kind, name, sql string
+		if err := rows.Scan(&kind, &name, &sql); err != nil {
+			t.Fatal(err)
+		}
+		objects[kind+" "+name] = sql
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return objects
+}
+
+// checkSchema fails the test if got and want don't have the same objects
+func checkSchema(t *testing.T, got, want map[string]string) {
+	t.Helper()
+	for name, sql := range want {
+		if got[name] != sql {
+			t.Errorf("%s = %q, want %q", name, got[name], sql)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("unexpected %s", name)
+		}
+	}
+}
+
+func TestMigrateUpAndDown(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	all, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range all {
+		if m.Down == "" {
+			t.Errorf("migration %04d_%s has no down migration", m.Version, m.Name)
+		}
+	}
+
+	applied, err := s.Migrate(ctx, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied != len(all) {
+		t.Fatalf("Migrate() applied %d migrations, want %d", applied, len(all))
+	}
+	if applied, err := s.Migrate(ctx, migrations.FS); err != nil || applied != 0 {
+		t.Fatalf("second Migrate() = %d, %v, want 0, nil", applied, err)
+	}
+	latest := schema(t, s)
+
+	// Rolling back any number of migrations and applying them again gives
+	// the same schema, so every down migration undoes its up migration
+	for steps := 1; steps <= len(all); steps++ {
+		if rolled, err := s.MigrateDown(ctx, migrations.FS, steps); err != nil || rolled != steps {
+			t.Fatalf("MigrateDown(%d) = %d, %v", steps, rolled, err)
+		}
+		if applied, err := s.Migrate(ctx, migrations.FS); err != nil || applied != steps {
+			t.Fatalf("Migrate() after rolling back %d = %d, %v", steps, applied, err)
+		}
+		checkSchema(t, schema(t, s), latest)
+	}
+
+	// All the way down leaves only the tracking table
+	if _, err := s.MigrateDown(ctx, migrations.FS, len(all)); err != nil {
+		t.Fatal(err)
+	}
+	if left := schema(t, s); len(left) != 1 || left["table schema_migrations"] == "" {
+		t.Errorf("schema after rolling back everything = %v, want only schema_migrations", left)
+	}
+
+	statuses, err := s.MigrationStatus(ctx, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			t.Errorf("migration %04d_%s still applied", status.Version, status.Name)
+		}
+	}
+}
+
+// TestMigrateAdoptsDDLDatabase checks that a database created by piping the
+// old migrations/ddl.sql into sqlite3 is brought up to date with its data
+func TestMigrateAdoptsDDLDatabase(t *testing.T) {
+	ctx := context.Background()
+
+	fresh := openTestStore(t)
+	if _, err := fresh.Migrate(ctx, migrations.FS); err != nil {
+		t.Fatal(err)
+	}
+
+	ddl, err := os.ReadFile(filepath.Join("testdata", "ddl.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := openTestStore(t)
+	if _, err := s.DB.ExecContext(ctx, string(ddl)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DB.ExecContext(ctx, `INSERT INTO invites (invite_code, name, max_adults, confirmed_adults, response_at, sheet_row)
+		VALUES ('ANA1', 'Ana', 2, 2, '2026-05-01 10:00:00', 2)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DB.ExecContext(ctx, `INSERT INTO schedule_events (start_time, event_name_es) VALUES ('2026-12-19T16:00:00-06:00', 'Ceremonia')`); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := s.MigrationStatus(ctx, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, err := s.Migrate(ctx, migrations.FS)
+	if err != nil {
+		t.Fatalf("Migrate() on a ddl.sql database: %v", err)
+	}
+	if applied != len(statuses) {
+		t.Errorf("Migrate() applied %d migrations, want %d", applied, len(statuses))
+	}
+	checkSchema(t, schema(t, s), schema(t, fresh))
+
+	invite, err := s.GetInviteByInviteCode(ctx, "ANA1")
+	if err != nil {
+		t.Fatalf("invite from the ddl.sql database: %v", err)
+	}
+	if invite.Name != "Ana" || invite.MaxAdults != 2 || invite.ConfirmedAdults != 2 || invite.ResponseAt == nil {
+		t.Errorf("invite from the ddl.sql database = %+v", invite)
+	}
+	events, err := s.GetScheduleEvents(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].EventNameEs != "Ceremonia" {
+		t.Errorf("schedule from the ddl.sql database = %+v", events)
+	}
+}
@@ -8,6 +8,43 @@ import (
 	"time"
 )
 
+type EmailOutbox struct {
+	ID            int64      `json:"id"`
+	Kind          string     `json:"kind"`
+	InviteCode    string     `json:"invite_code"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body"`
+	Attempts      int64      `json:"attempts"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type Event struct {
+	EventKey  string    `json:"event_key"`
+	Position  int64     `json:"position"`
+	NameEs    string    `json:"name_es"`
+	NameEn    string    `json:"name_en"`
+	NameCa    string    `json:"name_ca"`
+	StartTime string    `json:"start_time"`
+	Timezone  string    `json:"timezone"`
+	Location  string    `json:"location"`
+	SheetTab  string    `json:"sheet_tab"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Guest struct {
+	ID         int64     `json:"id"`
+	InviteCode string    `json:"invite_code"`
+	Name       string    `json:"name"`
+	IsKid      bool      `json:"is_kid"`
+	MealChoice string    `json:"meal_choice"`
+	Allergies  string    `json:"allergies"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 type Invite struct {
 	InviteCode      string     `json:"invite_code"`
 	Name            string     `json:"name"`
@@ -22,6 +59,23 @@ type Invite struct {
 	SheetRow        *int64     `json:"sheet_row"`
 	CreatedAt       time.Time  `json:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at"`
+	LocalChangedAt  *time.Time `json:"local_changed_at"`
+	Email           string     `json:"email"`
+	Lang            string     `json:"lang"`
+	RsvpDeadline    *time.Time `json:"rsvp_deadline"`
+	DisabledAt      *time.Time `json:"disabled_at"`
+}
+
+type InviteEvent struct {
+	InviteCode      string     `json:"invite_code"`
+	EventKey        string     `json:"event_key"`
+	MaxAdults       int64      `json:"max_adults"`
+	MaxKids         int64      `json:"max_kids"`
+	ConfirmedAdults int64      `json:"confirmed_adults"`
+	ConfirmedKids   int64      `json:"confirmed_kids"`
+	ResponseAt      *time.Time `json:"response_at"`
+	SheetRow        *int64     `json:"sheet_row"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 type ScheduleEvent struct {
@@ -36,4 +90,44 @@ type ScheduleEvent struct {
 	DescriptionEn string    `json:"description_en"`
 	DescriptionCa string    `json:"description_ca"`
 	UpdatedAt     time.Time `json:"updated_at"`
+	Timezone      string    `json:"timezone"`
+	Uid           string    `json:"uid"`
+}
+
+type InviteDeletion struct {
+	InviteCode string    `json:"invite_code"`
+	SheetRow   int64     `json:"sheet_row"`
+	DeletedAt  time.Time `json:"deleted_at"`
+}
+
+type RsvpEvent struct {
+	ID                 int64      `json:"id"`
+	InviteCode         string     `json:"invite_code"`
+	Source             string     `json:"source"`
+	ClientIp           string     `json:"client_ip"`
+	OldConfirmedAdults int64      `json:"old_confirmed_adults"`
+	OldConfirmedKids   int64      `json:"old_confirmed_kids"`
+	OldDietaryInfo     string     `json:"old_dietary_info"`
+	OldMessageForUs    string     `json:"old_message_for_us"`
+	OldSongRequest     string     `json:"old_song_request"`
+	OldResponseAt      *time.Time `json:"old_response_at"`
+	NewConfirmedAdults int64      `json:"new_confirmed_adults"`
+	NewConfirmedKids   int64      `json:"new_confirmed_kids"`
+	NewDietaryInfo     string     `json:"new_dietary_info"`
+	NewMessageForUs    string     `json:"new_message_for_us"`
+	NewSongRequest     string     `json:"new_song_request"`
+	CreatedAt          time.Time  `json:"created_at"`
+	EventKey           string     `json:"event_key"`
+}
+
+type SyncRun struct {
+	ID             int64     `json:"id"`
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	DurationMs     int64     `json:"duration_ms"`
+	InvitesRead    int64     `json:"invites_read"`
+	RsvpsPushed    int64     `json:"rsvps_pushed"`
+	RsvpsFailed    int64     `json:"rsvps_failed"`
+	ScheduleEvents int64     `json:"schedule_events"`
+	Error          string    `json:"error"`
 }
@@ -1,8 +1,9 @@
 -- name: GetInviteByInviteCode :one
 SELECT * FROM invites WHERE invite_code = ?;
 
--- name: UpdateRSVP :exec
+-- name: UpdateRSVP :execrows
 -- Updates RSVP details and forces a sync (synced_at = NULL).
+-- Returns 0 rows affected when the counts exceed the invite's limits.
 UPDATE invites
 SET
     confirmed_adults = :input_confirmed_adults,
@@ -21,9 +22,9 @@ WHERE
 -- Syncs Master Data from Google Sheets -> DB.
 -- Skips updates if invite has unsynced local changes (synced_at IS NULL).
 INSERT INTO invites (
-    invite_code, name, max_adults, max_kids, confirmed_adults, sheet_row, updated_at
+    invite_code, name, max_adults, max_kids, confirmed_adults, sheet_row, rsvp_deadline, updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, datetime('now', 'utc')
+    ?, ?, ?, ?, ?, ?, datetime(?), datetime('now', 'utc')
 )
 ON CONFLICT(invite_code) DO UPDATE SET
     name       = excluded.name,
@@ -31,13 +32,38 @@ ON CONFLICT(invite_code) DO UPDATE SET
     max_kids   = excluded.max_kids,
     sheet_row  = excluded.sheet_row,
     confirmed_adults = excluded.confirmed_adults,
+    rsvp_deadline    = excluded.rsvp_deadline,
+    disabled_at      = NULL,
     updated_at = excluded.updated_at
-WHERE invites.response_at IS NULL OR invites.response_at <= invites.updated_at;
+WHERE (invites.response_at IS NULL OR invites.response_at <= invites.updated_at)
+  AND invites.local_changed_at IS NULL;
     -- Note: The WHERE clause prevents updates when synced_at IS NULL,
     -- protecting local RSVP changes that haven't been pushed to the sheet yet.
 
 
 
+-- name: DisableInvite :exec
+-- Disables an invite whose row was removed from the sheet, keeping its
+-- answers. Its row number and pending admin edit no longer apply.
+UPDATE invites
+SET
+    disabled_at      = datetime('now', 'utc'),
+    sheet_row        = NULL,
+    local_changed_at = NULL
+WHERE invite_code = ?
+  AND disabled_at IS NULL;
+
+-- name: EnableInvite :exec
+-- Re-enables an invite whose row is back in the sheet. Unlike UpsertInvite
+-- it doesn't wait for a pending RSVP, which is only written to the sheet
+-- once the invite is enabled and has a row again.
+UPDATE invites
+SET
+    disabled_at = NULL,
+    sheet_row   = :sheet_row
+WHERE invite_code = :invite_code
+  AND disabled_at IS NOT NULL;
+
 -- name: DeleteInvite :exec
 -- HARD DELETE: This permanently removes the row.
 DELETE FROM invites
@@ -48,14 +74,104 @@ WHERE invite_code = ?;
 SELECT * FROM invites
 WHERE response_at IS NOT NULL
   AND response_at > updated_at
+  AND disabled_at IS NULL
 ORDER BY response_at ASC;
 
--- name: MarkInviteSynced :exec
+-- name: MarkInviteSynced :execrows
+-- Marks the RSVP written to the sheet as synced, unless the guest answered
+-- again since it was read. Returns 0 rows affected in that case.
 UPDATE invites
 SET
     updated_at = datetime('now', 'utc')
+WHERE invite_code = :invite_code
+  AND response_at = datetime(:response_at);
+
+-- =====================
+-- Admin Queries
+-- =====================
+
+-- name: ListInvites :many
+-- Lists invites ordered by name, optionally filtered by a name or code search.
+-- The search is matched literally once escaped with EscapeLike.
+SELECT * FROM invites
+WHERE CAST(:search AS TEXT) = ''
+   OR name LIKE '%' || :search || '%' ESCAPE '\'
+   OR invite_code LIKE :search || '%' ESCAPE '\'
+ORDER BY name ASC;
+
+-- name: CreateInvite :exec
+-- Creates an invite from the admin API, marked as pending push to the sheet.
+INSERT INTO invites (
+    invite_code, name, max_adults, max_kids, local_changed_at
+) VALUES (
+    ?, ?, ?, ?, datetime('now', 'utc')
+);
+
+-- name: UpdateInviteDetails :execrows
+-- Edits an invite's master data from the admin API and marks it for push.
+-- Returns 0 rows affected when the new limits are below the confirmed counts.
+UPDATE invites
+SET
+    name             = :name,
+    max_adults       = :max_adults,
+    max_kids         = :max_kids,
+    local_changed_at = datetime('now', 'utc')
+WHERE invite_code = :invite_code
+  AND confirmed_adults <= :max_adults
+  AND confirmed_kids   <= :max_kids;
+
+-- name: GetPendingInviteEdits :many
+-- Finds invites with admin edits that haven't been pushed to the sheet.
+SELECT * FROM invites
+WHERE local_changed_at IS NOT NULL
+  AND disabled_at IS NULL
+ORDER BY local_changed_at ASC;
+
+-- name: MarkInviteEditSynced :exec
+-- Records the invite's sheet row and clears the pending edit, unless the
+-- invite was edited again since it was read.
+UPDATE invites
+SET
+    sheet_row        = :sheet_row,
+    local_changed_at = CASE
+        WHEN local_changed_at = datetime(:local_changed_at) THEN NULL
+        ELSE local_changed_at
+    END
+WHERE invite_code = :invite_code;
+
+-- name: InsertInviteDeletion :exec
+-- Queues removal of a deleted invite's sheet row.
+INSERT INTO invite_deletions (invite_code, sheet_row)
+VALUES (?, ?)
+ON CONFLICT(invite_code) DO UPDATE SET
+    sheet_row  = excluded.sheet_row,
+    deleted_at = datetime('now', 'utc');
+
+-- name: GetInviteDeletion :one
+SELECT * FROM invite_deletions
 WHERE invite_code = ?;
 
+-- name: ListInviteDeletions :many
+-- Returns pending sheet row removals, bottom rows first so deleting one
+-- doesn't shift the rows of the others.
+SELECT * FROM invite_deletions
+ORDER BY sheet_row DESC;
+
+-- name: DeleteInviteDeletion :exec
+DELETE FROM invite_deletions
+WHERE invite_code = ?;
+
+-- name: ShiftInviteRows :exec
+-- Moves invites below a removed sheet row up by one, matching the sheet.
+UPDATE invites
+SET sheet_row = sheet_row - 1
+WHERE sheet_row > ?;
+
+-- name: ShiftInviteDeletionRows :exec
+UPDATE invite_deletions
+SET sheet_row = sheet_row - 1
+WHERE sheet_row > ?;
+
 -- =====================
 -- Schedule Events Queries
 -- =====================
@@ -77,11 +193,322 @@ INSERT INTO schedule_events (
     event_name_es, event_name_en, event_name_ca,
     location,
     description_es, description_en, description_ca,
-    updated_at
+    timezone, uid, updated_at
 ) VALUES (
     ?, ?,
     ?, ?, ?,
     ?,
     ?, ?, ?,
-    datetime('now', 'utc')
+    ?, ?, datetime('now', 'utc')
 );
+
+-- =====================
+-- Guests Queries
+-- =====================
+
+-- name: ListGuestsByInviteCode :many
+-- Returns the guests of an invite in the order they were entered.
+SELECT * FROM guests
+WHERE invite_code = ?
+ORDER BY id ASC;
+
+-- name: DeleteGuestsByInviteCode :exec
+-- Clears an invite's guests before the list is replaced.
+DELETE FROM guests
+WHERE invite_code = ?;
+
+-- name: InsertGuest :exec
+INSERT INTO guests (
+    invite_code, name, is_kid, meal_choice, allergies
+) VALUES (
+    ?, ?, ?, ?, ?
+);
+
+-- =====================
+-- RSVP Events Queries
+-- =====================
+
+-- name: InsertRSVPEvent :exec
+-- Appends an entry to an invite's RSVP timeline.
+INSERT INTO rsvp_events (
+    invite_code, event_key, source, client_ip,
+    old_confirmed_adults, old_confirmed_kids, old_dietary_info, old_message_for_us, old_song_request, old_response_at,
+    new_confirmed_adults, new_confirmed_kids, new_dietary_info, new_message_for_us, new_song_request
+) VALUES (
+    ?, ?, ?, ?,
+    ?, ?, ?, ?, ?, ?,
+    ?, ?, ?, ?, ?
+);
+
+-- name: ListRSVPEventsByInviteCode :many
+-- Returns an invite's RSVP timeline, oldest first.
+SELECT * FROM rsvp_events
+WHERE invite_code = ?
+ORDER BY id ASC;
+
+-- =====================
+-- Stats Queries
+-- =====================
+
+-- name: GetInviteStats :one
+-- Aggregates invited vs confirmed headcounts and response counts.
+-- A decline is a response with no adults or kids attending.
+SELECT
+    COUNT(*) AS total_invites,
+    CAST(COALESCE(SUM(max_adults), 0) AS INTEGER) AS invited_adults,
+    CAST(COALESCE(SUM(max_kids), 0) AS INTEGER) AS invited_kids,
+    CAST(COALESCE(SUM(confirmed_adults), 0) AS INTEGER) AS confirmed_adults,
+    CAST(COALESCE(SUM(confirmed_kids), 0) AS INTEGER) AS confirmed_kids,
+    COUNT(response_at) AS responded_invites,
+    CAST(COALESCE(SUM(response_at IS NOT NULL AND confirmed_adults + confirmed_kids = 0), 0) AS INTEGER) AS declined_invites
+FROM invites
+WHERE disabled_at IS NULL;
+
+-- name: ListDietaryInfoCounts :many
+-- Groups the dietary info of attending invites, ignoring case and
+-- surrounding whitespace.
+SELECT
+    CAST(LOWER(TRIM(dietary_info)) AS TEXT) AS dietary_info,
+    COUNT(*) AS invites,
+    CAST(SUM(confirmed_adults + confirmed_kids) AS INTEGER) AS guests
+FROM invites
+WHERE response_at IS NOT NULL
+  AND disabled_at IS NULL
+  AND confirmed_adults + confirmed_kids > 0
+  AND TRIM(dietary_info) != ''
+GROUP BY LOWER(TRIM(dietary_info))
+ORDER BY guests DESC, dietary_info ASC;
+
+-- name: ListMealChoiceCounts :many
+-- Counts the meal choices of individual guests (empty if not chosen yet).
+SELECT
+    meal_choice,
+    COUNT(*) AS guests
+FROM guests
+WHERE invite_code IN (SELECT invite_code FROM invites WHERE disabled_at IS NULL)
+GROUP BY meal_choice
+ORDER BY guests DESC, meal_choice ASC;
+
+-- name: ListResponsesByLastAnswerDay :many
+-- Counts each invite once, on the UTC day of its latest answer. An invite
+-- that changes its answer moves to the day of the change, so this is not how
+-- many answers came in each day; rsvp_events has those.
+SELECT
+    CAST(DATE(response_at) AS TEXT) AS day,
+    COUNT(*) AS responses,
+    CAST(SUM(confirmed_adults) AS INTEGER) AS confirmed_adults,
+    CAST(SUM(confirmed_kids) AS INTEGER) AS confirmed_kids,
+    CAST(SUM(confirmed_adults + confirmed_kids = 0) AS INTEGER) AS declines
+FROM invites
+WHERE response_at IS NOT NULL
+  AND disabled_at IS NULL
+GROUP BY DATE(response_at)
+ORDER BY day ASC;
+
+-- =====================
+-- Email Outbox Queries
+-- =====================
+
+-- name: UpdateInviteContact :exec
+-- Stores the email and language a guest left with their RSVP.
+UPDATE invites
+SET
+    email = :email,
+    lang  = :lang
+WHERE invite_code = :invite_code;
+
+-- name: EnqueueEmail :exec
+INSERT INTO email_outbox (
+    kind, invite_code, recipient, subject, body
+) VALUES (
+    ?, ?, ?, ?, ?
+);
+
+-- name: ListDueEmails :many
+-- Returns unsent messages whose next attempt is due, oldest first.
+SELECT * FROM email_outbox
+WHERE sent_at IS NULL
+  AND attempts < :max_attempts
+  AND next_attempt_at <= datetime('now', 'utc')
+ORDER BY id ASC
+LIMIT :limit;
+
+-- name: MarkEmailSent :exec
+UPDATE email_outbox
+SET
+    attempts   = attempts + 1,
+    last_error = '',
+    sent_at    = datetime('now', 'utc')
+WHERE id = ?;
+
+-- name: MarkEmailFailed :exec
+-- Records a failed attempt and schedules the next one after a backoff.
+UPDATE email_outbox
+SET
+    attempts        = attempts + 1,
+    last_error      = :last_error,
+    next_attempt_at = datetime('now', 'utc', '+' || CAST(:backoff_seconds AS INTEGER) || ' seconds')
+WHERE id = :id;
+
+-- name: GetLastDigestAt :one
+SELECT created_at FROM email_outbox
+WHERE kind = 'digest'
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ListRSVPEventsSince :many
+-- Returns every RSVP change after the given time, oldest first, for the digest.
+SELECT * FROM rsvp_events
+WHERE created_at > datetime(:since)
+ORDER BY id ASC;
+
+-- =====================
+-- Sync Runs Queries
+-- =====================
+
+-- name: InsertSyncRun :exec
+INSERT INTO sync_runs (
+    started_at, finished_at, duration_ms,
+    invites_read, rsvps_pushed, rsvps_failed, schedule_events, error
+) VALUES (
+    datetime(:started_at), datetime(:finished_at), :duration_ms,
+    :invites_read, :rsvps_pushed, :rsvps_failed, :schedule_events, :error
+);
+
+-- name: DeleteOldSyncRuns :exec
+-- Keeps only the most recent runs, the sync loop adds one every interval.
+DELETE FROM sync_runs
+WHERE id <= (SELECT MAX(id) FROM sync_runs) - CAST(:keep AS INTEGER);
+
+-- name: ListSyncRuns :many
+-- Returns the most recent sync runs, newest first.
+SELECT * FROM sync_runs
+ORDER BY id DESC
+LIMIT :limit;
+
+-- name: GetLastSuccessfulSyncRun :one
+SELECT * FROM sync_runs
+WHERE error = ''
+ORDER BY id DESC
+LIMIT 1;
+
+-- =====================
+-- Events Queries
+-- =====================
+
+-- name: ListEvents :many
+-- Returns all events in the order of the Events tab.
+SELECT * FROM events
+ORDER BY position ASC, event_key ASC;
+
+-- name: UpsertEvent :exec
+-- Syncs an event from the Events tab -> DB.
+INSERT INTO events (
+    event_key, position, name_es, name_en, name_ca, start_time, timezone, location, sheet_tab, updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now', 'utc')
+)
+ON CONFLICT(event_key) DO UPDATE SET
+    position   = excluded.position,
+    name_es    = excluded.name_es,
+    name_en    = excluded.name_en,
+    name_ca    = excluded.name_ca,
+    start_time = excluded.start_time,
+    timezone   = excluded.timezone,
+    location   = excluded.location,
+    sheet_tab  = excluded.sheet_tab,
+    updated_at = excluded.updated_at;
+
+-- name: DeleteEvent :exec
+DELETE FROM events
+WHERE event_key = ?;
+
+-- name: ListInviteEventsByEvent :many
+-- Returns everyone invited to an event.
+SELECT * FROM invite_events
+WHERE event_key = ?
+ORDER BY sheet_row ASC;
+
+-- name: ListInviteEventsByInviteCode :many
+-- Returns the events an invite is invited to, in the order of the Events tab.
+SELECT
+    invite_events.invite_code, invite_events.event_key,
+    invite_events.max_adults, invite_events.max_kids,
+    invite_events.confirmed_adults, invite_events.confirmed_kids,
+    invite_events.response_at, invite_events.sheet_row, invite_events.updated_at
+FROM invite_events
+JOIN events ON events.event_key = invite_events.event_key
+WHERE invite_events.invite_code = ?
+ORDER BY events.position ASC, events.event_key ASC;
+
+-- name: UpsertInviteEvent :exec
+-- Syncs an invitation from the event's tab -> DB.
+-- Like UpsertInvite, skips invitations with an answer not yet written to the tab.
+INSERT INTO invite_events (
+    invite_code, event_key, max_adults, max_kids, confirmed_adults, confirmed_kids, sheet_row, updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, datetime('now', 'utc')
+)
+ON CONFLICT(invite_code, event_key) DO UPDATE SET
+    max_adults       = excluded.max_adults,
+    max_kids         = excluded.max_kids,
+    confirmed_adults = excluded.confirmed_adults,
+    confirmed_kids   = excluded.confirmed_kids,
+    sheet_row        = excluded.sheet_row,
+    updated_at       = excluded.updated_at
+WHERE invite_events.response_at IS NULL
+   OR invite_events.response_at <= invite_events.updated_at;
+
+-- name: DeleteInviteEvent :execrows
+-- Removes an invitation dropped from the event's tab.
+-- Like UpsertInviteEvent, keeps it while its answer isn't written to the tab yet.
+DELETE FROM invite_events
+WHERE invite_code = ? AND event_key = ?
+  AND (response_at IS NULL OR response_at <= updated_at);
+
+-- name: DeleteInviteEventsByEvent :execrows
+-- Removes the invitations of an event dropped from the Events tab, except
+-- the ones with an answer not yet written to the tab.
+DELETE FROM invite_events
+WHERE event_key = ?
+  AND (response_at IS NULL OR response_at <= updated_at);
+
+-- name: DeleteInviteEventsByInviteCode :exec
+DELETE FROM invite_events
+WHERE invite_code = ?;
+
+-- name: UpdateInviteEventRSVP :execrows
+-- Saves an invite's answer for one event and marks it as needing sync.
+-- Returns 0 rows affected when the invite isn't invited or the counts exceed its limits.
+UPDATE invite_events
+SET
+    confirmed_adults = :confirmed_adults,
+    confirmed_kids   = :confirmed_kids,
+    response_at      = datetime('now', 'utc')
+WHERE invite_code = :invite_code
+  AND event_key   = :event_key
+  AND :confirmed_adults <= max_adults
+  AND :confirmed_kids   <= max_kids;
+
+-- name: GetPendingInviteEventSyncs :many
+-- Finds event answers that changed since they were last written to the sheet.
+SELECT
+    invite_events.invite_code, invite_events.event_key,
+    invite_events.max_adults, invite_events.max_kids,
+    invite_events.confirmed_adults, invite_events.confirmed_kids,
+    invite_events.response_at, invite_events.sheet_row, invite_events.updated_at
+FROM invite_events
+JOIN invites ON invites.invite_code = invite_events.invite_code
+WHERE invite_events.response_at IS NOT NULL
+  AND invite_events.response_at > invite_events.updated_at
+  AND invites.disabled_at IS NULL
+ORDER BY invite_events.event_key ASC, invite_events.response_at ASC;
+
+-- name: MarkInviteEventSynced :execrows
+-- Like MarkInviteSynced, for an event answer written to the event's tab.
+UPDATE invite_events
+SET
+    updated_at = datetime('now', 'utc')
+WHERE invite_code = :invite_code
+  AND event_key   = :event_key
+  AND response_at = datetime(:response_at);
@@ -7,8 +7,41 @@ package store
 
 import (
 	"context"
+	"time"
 )
 
+const CreateInvite = `-- name: CreateInvite :exec
+INSERT INTO invites (
+    invite_code, name, max_adults, max_kids, local_changed_at
+) VALUES (
+    ?, ?, ?, ?, datetime('now', 'utc')
+)
+`
+
+type CreateInviteParams struct {
+	InviteCode string `json:"invite_code"`
+	Name       string `json:"name"`
+	MaxAdults  int64  `json:"max_adults"`
+	MaxKids    int64  `json:"max_kids"`
+}
+
+// Creates an invite from the admin API, marked as pending push to the sheet.
+//
+//	INSERT INTO invites (
+//	    invite_code, name, max_adults, max_kids, local_changed_at
+//	) VALUES (
+//	    ?, ?, ?, ?, datetime('now', 'utc')
+//	)
+func (q *Queries) CreateInvite(ctx context.Context, arg *CreateInviteParams) error {
+	_, err := q.exec(ctx, q.createInviteStmt, CreateInvite,
+		arg.InviteCode,
+		arg.Name,
+		arg.MaxAdults,
+		arg.MaxKids,
+	)
+	return err
+}
+
 const DeleteAllScheduleEvents = `-- name: DeleteAllScheduleEvents :exec
 DELETE FROM schedule_events
 `
@@ -21,6 +54,34 @@ func (q *Queries) DeleteAllScheduleEvents(ctx context.Context) error {
 	return err
 }
 
+const DeleteEvent = `-- name: DeleteEvent :exec
+DELETE FROM events
+WHERE event_key = ?
+`
+
+// DeleteEvent
+//
+//	DELETE FROM events
+//	WHERE event_key = ?
+func (q *Queries) DeleteEvent(ctx context.Context, eventKey string) error {
+	_, err := q.exec(ctx, q.deleteEventStmt, DeleteEvent, eventKey)
+	return err
+}
+
+const DeleteGuestsByInviteCode = `-- name: DeleteGuestsByInviteCode :exec
+DELETE FROM guests
+WHERE invite_code = ?
+`
+
+// Clears an invite's guests before the list is replaced.
+//
+//	DELETE FROM guests
+//	WHERE invite_code = ?
+func (q *Queries) DeleteGuestsByInviteCode(ctx context.Context, inviteCode string) error {
+	_, err := q.exec(ctx, q.deleteGuestsByInviteCodeStmt, DeleteGuestsByInviteCode, inviteCode)
+	return err
+}
+
 const DeleteInvite = `-- name: DeleteInvite :exec
     -- protecting local RSVP changes that haven't been pushed to the sheet yet.
 
@@ -44,13 +105,194 @@ func (q *Queries) DeleteInvite(ctx context.Context, inviteCode string) error {
 	return err
 }
 
+const DeleteInviteDeletion = `-- name: DeleteInviteDeletion :exec
+DELETE FROM invite_deletions
+WHERE invite_code = ?
+`
+
+// DeleteInviteDeletion
+//
+//	DELETE FROM invite_deletions
+//	WHERE invite_code = ?
+func (q *Queries) DeleteInviteDeletion(ctx context.Context, inviteCode string) error {
+	_, err := q.exec(ctx, q.deleteInviteDeletionStmt, DeleteInviteDeletion, inviteCode)
+	return err
+}
+
+const DeleteInviteEvent = `-- name: DeleteInviteEvent :execrows
+DELETE FROM invite_events
+WHERE invite_code = ? AND event_key = ?
+  AND (response_at IS NULL OR response_at <= updated_at)
+`
+
+type DeleteInviteEventParams struct {
+	InviteCode string `json:"invite_code"`
+	EventKey   string `json:"event_key"`
+}
+
+// Removes an invitation dropped from the event's tab.
+// Like UpsertInviteEvent, keeps it while its answer isn't written to the tab yet.
+//
+//	DELETE FROM invite_events
+//	WHERE invite_code = ? AND event_key = ?
+//	  AND (response_at IS NULL OR response_at <= updated_at)
+func (q *Queries) DeleteInviteEvent(ctx context.Context, arg *DeleteInviteEventParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteInviteEventStmt, DeleteInviteEvent,
+		arg.InviteCode,
+		arg.EventKey,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const DeleteInviteEventsByEvent = `-- name: DeleteInviteEventsByEvent :execrows
+DELETE FROM invite_events
+WHERE event_key = ?
+  AND (response_at IS NULL OR response_at <= updated_at)
+`
+
+// Removes the invitations of an event dropped from the Events tab, except
+// the ones with an answer not yet written to the tab.
+//
+//	DELETE FROM invite_events
+//	WHERE event_key = ?
+//	  AND (response_at IS NULL OR response_at <= updated_at)
+func (q *Queries) DeleteInviteEventsByEvent(ctx context.Context, eventKey string) (int64, error) {
+	result, err := q.exec(ctx, q.deleteInviteEventsByEventStmt, DeleteInviteEventsByEvent, eventKey)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const DeleteInviteEventsByInviteCode = `-- name: DeleteInviteEventsByInviteCode :exec
+DELETE FROM invite_events
+WHERE invite_code = ?
+`
+
+// DeleteInviteEventsByInviteCode
+//
+//	DELETE FROM invite_events
+//	WHERE invite_code = ?
+func (q *Queries) DeleteInviteEventsByInviteCode(ctx context.Context, inviteCode string) error {
+	_, err := q.exec(ctx, q.deleteInviteEventsByInviteCodeStmt, DeleteInviteEventsByInviteCode, inviteCode)
+	return err
+}
+
+const DeleteOldSyncRuns = `-- name: DeleteOldSyncRuns :exec
+DELETE FROM sync_runs
+WHERE id <= (SELECT MAX(id) FROM sync_runs) - CAST(?1 AS INTEGER)
+`
+
+// Keeps only the most recent runs, the sync loop adds one every interval.
+//
+//	DELETE FROM sync_runs
+//	WHERE id <= (SELECT MAX(id) FROM sync_runs) - CAST(?1 AS INTEGER)
+func (q *Queries) DeleteOldSyncRuns(ctx context.Context, keep int64) error {
+	_, err := q.exec(ctx, q.deleteOldSyncRunsStmt, DeleteOldSyncRuns, keep)
+	return err
+}
+
+const DisableInvite = `-- name: DisableInvite :exec
+UPDATE invites
+SET
+    disabled_at      = datetime('now', 'utc'),
+    sheet_row        = NULL,
+    local_changed_at = NULL
+WHERE invite_code = ?
+  AND disabled_at IS NULL
+`
+
+// Disables an invite whose row was removed from the sheet, keeping its
+// answers. Its row number and pending admin edit no longer apply.
+//
+//	UPDATE invites
+//	SET
+//	    disabled_at      = datetime('now', 'utc'),
+//	    sheet_row        = NULL,
+//	    local_changed_at = NULL
+//	WHERE invite_code = ?
+//	  AND disabled_at IS NULL
+func (q *Queries) DisableInvite(ctx context.Context, inviteCode string) error {
+	_, err := q.exec(ctx, q.disableInviteStmt, DisableInvite, inviteCode)
+	return err
+}
+
+const EnableInvite = `-- name: EnableInvite :exec
+UPDATE invites
+SET
+    disabled_at = NULL,
+    sheet_row   = ?1
+WHERE invite_code = ?2
+  AND disabled_at IS NOT NULL
+`
+
+type EnableInviteParams struct {
+	SheetRow   *int64 `json:"sheet_row"`
+	InviteCode string `json:"invite_code"`
+}
+
+// Re-enables an invite whose row is back in the sheet. Unlike UpsertInvite
+// it doesn't wait for a pending RSVP, which is only written to the sheet
+// once the invite is enabled and has a row again.
+//
+//	UPDATE invites
+//	SET
+//	    disabled_at = NULL,
+//	    sheet_row   = ?1
+//	WHERE invite_code = ?2
+//	  AND disabled_at IS NOT NULL
+func (q *Queries) EnableInvite(ctx context.Context, arg *EnableInviteParams) error {
+	_, err := q.exec(ctx, q.enableInviteStmt, EnableInvite,
+		arg.SheetRow,
+		arg.InviteCode,
+	)
+	return err
+}
+
+const EnqueueEmail = `-- name: EnqueueEmail :exec
+INSERT INTO email_outbox (
+    kind, invite_code, recipient, subject, body
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+`
+
+type EnqueueEmailParams struct {
+	Kind       string `json:"kind"`
+	InviteCode string `json:"invite_code"`
+	Recipient  string `json:"recipient"`
+	Subject    string `json:"subject"`
+	Body       string `json:"body"`
+}
+
+// EnqueueEmail
+//
+//	INSERT INTO email_outbox (
+//	    kind, invite_code, recipient, subject, body
+//	) VALUES (
+//	    ?, ?, ?, ?, ?
+//	)
+func (q *Queries) EnqueueEmail(ctx context.Context, arg *EnqueueEmailParams) error {
+	_, err := q.exec(ctx, q.enqueueEmailStmt, EnqueueEmail,
+		arg.Kind,
+		arg.InviteCode,
+		arg.Recipient,
+		arg.Subject,
+		arg.Body,
+	)
+	return err
+}
+
 const GetInviteByInviteCode = `-- name: GetInviteByInviteCode :one
-SELECT invite_code, name, max_adults, max_kids, confirmed_adults, confirmed_kids, dietary_info, message_for_us, song_request, response_at, sheet_row, created_at, updated_at FROM invites WHERE invite_code = ?
+SELECT invite_code, name, max_adults, max_kids, confirmed_adults, confirmed_kids, dietary_info, message_for_us, song_request, response_at, sheet_row, created_at, updated_at, local_changed_at, email, lang, rsvp_deadline, disabled_at FROM invites WHERE invite_code = ?
 `
 
 // GetInviteByInviteCode
 //
-//	SELECT invite_code, name, max_adults, max_kids, confirmed_adults, confirmed_kids, dietary_info, message_for_us, song_request, response_at, sheet_row, created_at, updated_at FROM invites WHERE invite_code = ?
+//	SELECT invite_code, name, max_adults, max_kids, confirmed_adults, confirmed_kids, dietary_info, message_for_us, song_request, response_at, sheet_row, created_at, updated_at, local_changed_at, email, lang, rsvp_deadline, disabled_at FROM invites WHERE invite_code = ?
 func (q *Queries) GetInviteByInviteCode(ctx context.Context, inviteCode string) (*Invite, error) {
 	row := q.queryRow(ctx, q.getInviteByInviteCodeStmt, GetInviteByInviteCode, inviteCode)
 	var i Invite
@@ -68,22 +310,269 @@ func (q *Queries) GetInviteByInviteCode(ctx context.Context, inviteCode string)
 		&i.SheetRow,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LocalChangedAt,
+		&i.Email,
+		&i.Lang,
+		&i.RsvpDeadline,
+		&i.DisabledAt,
+	)
+	return &i, err
+}
+
+const GetInviteDeletion = `-- name: GetInviteDeletion :one
+SELECT invite_code, sheet_row, deleted_at FROM invite_deletions
+WHERE invite_code = ?
+`
+
+// GetInviteDeletion
+//
+//	SELECT invite_code, sheet_row, deleted_at FROM invite_deletions
+//	WHERE invite_code = ?
+func (q *Queries) GetInviteDeletion(ctx context.Context, inviteCode string) (*InviteDeletion, error) {
+	row := q.queryRow(ctx, q.getInviteDeletionStmt, GetInviteDeletion, inviteCode)
+	var i InviteDeletion
+	err := row.Scan(
+		&i.InviteCode,
+		&i.SheetRow,
+		&i.DeletedAt,
+	)
+	return &i, err
+}
+
+const GetInviteStats = `-- name: GetInviteStats :one
+
+SELECT
+    COUNT(*) AS total_invites,
+    CAST(COALESCE(SUM(max_adults), 0) AS INTEGER) AS invited_adults,
+    CAST(COALESCE(SUM(max_kids), 0) AS INTEGER) AS invited_kids,
+    CAST(COALESCE(SUM(confirmed_adults), 0) AS INTEGER) AS confirmed_adults,
+    CAST(COALESCE(SUM(confirmed_kids), 0) AS INTEGER) AS confirmed_kids,
+    COUNT(response_at) AS responded_invites,
+    CAST(COALESCE(SUM(response_at IS NOT NULL AND confirmed_adults + confirmed_kids = 0), 0) AS INTEGER) AS declined_invites
+FROM invites
+WHERE disabled_at IS NULL
+`
+
+type GetInviteStatsRow struct {
+	TotalInvites     int64 `json:"total_invites"`
+	InvitedAdults    int64 `json:"invited_adults"`
+	InvitedKids      int64 `json:"invited_kids"`
+	ConfirmedAdults  int64 `json:"confirmed_adults"`
+	ConfirmedKids    int64 `json:"confirmed_kids"`
+	RespondedInvites int64 `json:"responded_invites"`
+	DeclinedInvites  int64 `json:"declined_invites"`
+}
+
+// =====================
+// Stats Queries
+// =====================
+// Aggregates invited vs confirmed headcounts and response counts.
+// A decline is a response with no adults or kids attending.
+//
+//	SELECT
+//	    COUNT(*) AS total_invites,
+//	    CAST(COALESCE(SUM(max_adults), 0) AS INTEGER) AS invited_adults,
+//	    CAST(COALESCE(SUM(max_kids), 0) AS INTEGER) AS invited_kids,
+//	    CAST(COALESCE(SUM(confirmed_adults), 0) AS INTEGER) AS confirmed_adults,
+//	    CAST(COALESCE(SUM(confirmed_kids), 0) AS INTEGER) AS confirmed_kids,
+//	    COUNT(response_at) AS responded_invites,
+//	    CAST(COALESCE(SUM(response_at IS NOT NULL AND confirmed_adults + confirmed_kids = 0), 0) AS INTEGER) AS declined_invites
+//	FROM invites
+//	WHERE disabled_at IS NULL
+func (q *Queries) GetInviteStats(ctx context.Context) (*GetInviteStatsRow, error) {
+	row := q.queryRow(ctx, q.getInviteStatsStmt, GetInviteStats)
+	var i GetInviteStatsRow
+	err := row.Scan(
+		&i.TotalInvites,
+		&i.InvitedAdults,
+		&i.InvitedKids,
+		&i.ConfirmedAdults,
+		&i.ConfirmedKids,
+		&i.RespondedInvites,
+		&i.DeclinedInvites,
+	)
+	return &i, err
+}
+
+const GetLastDigestAt = `-- name: GetLastDigestAt :one
+SELECT created_at FROM email_outbox
+WHERE kind = 'digest'
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+// GetLastDigestAt
+//
+//	SELECT created_at FROM email_outbox
+//	WHERE kind = 'digest'
+//	ORDER BY created_at DESC
+//	LIMIT 1
+func (q *Queries) GetLastDigestAt(ctx context.Context) (time.Time, error) {
+	row := q.queryRow(ctx, q.getLastDigestAtStmt, GetLastDigestAt)
+	var createdAt time.Time
+	err := row.Scan(&createdAt)
+	return createdAt, err
+}
+
+const GetLastSuccessfulSyncRun = `-- name: GetLastSuccessfulSyncRun :one
+SELECT id, started_at, finished_at, duration_ms, invites_read, rsvps_pushed, rsvps_failed, schedule_events, error FROM sync_runs
+WHERE error = ''
+ORDER BY id DESC
+LIMIT 1
+`
+
+// GetLastSuccessfulSyncRun
+//
+//	SELECT id, started_at, finished_at, duration_ms, invites_read, rsvps_pushed, rsvps_failed, schedule_events, error FROM sync_runs
+//	WHERE error = ''
+//	ORDER BY id DESC
+//	LIMIT 1
+func (q *Queries) GetLastSuccessfulSyncRun(ctx context.Context) (*SyncRun, error) {
+	row := q.queryRow(ctx, q.getLastSuccessfulSyncRunStmt, GetLastSuccessfulSyncRun)
+	var i SyncRun
+	err := row.Scan(
+		&i.ID,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.DurationMs,
+		&i.InvitesRead,
+		&i.RsvpsPushed,
+		&i.RsvpsFailed,
+		&i.ScheduleEvents,
+		&i.Error,
 	)
 	return &i, err
 }
 
+const GetPendingInviteEdits = `-- name: GetPendingInviteEdits :many
+SELECT invite_code, name, max_adults, max_kids, confirmed_adults, confirmed_kids, dietary_info, message_for_us, song_request, response_at, sheet_row, created_at, updated_at, local_changed_at, email, lang, rsvp_deadline, disabled_at FROM invites
+WHERE local_changed_at IS NOT NULL
+  AND disabled_at IS NULL
+ORDER BY local_changed_at ASC
+`
+
+// Finds invites with admin edits that haven't been pushed to the sheet.
+//
+//	SELECT invite_code, name, max_adults, max_kids, confirmed_adults, confirmed_kids, dietary_info, message_for_us, song_request, response_at, sheet_row, created_at, updated_at, local_changed_at, email, lang, rsvp_deadline, disabled_at FROM invites
+//	WHERE local_changed_at IS NOT NULL
+//	  AND disabled_at IS NULL
+//	ORDER BY local_changed_at ASC
+func (q *Queries) GetPendingInviteEdits(ctx context.Context) ([]*Invite, error) {
+	rows, err := q.query(ctx, q.getPendingInviteEditsStmt, GetPendingInviteEdits)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Invite{}
+	for rows.Next() {
+		var i Invite
+		if err := rows.Scan(
+			&i.InviteCode,
+			&i.Name,
+			&i.MaxAdults,
+			&i.MaxKids,
+			&i.ConfirmedAdults,
+			&i.ConfirmedKids,
+			&i.DietaryInfo,
+			&i.MessageForUs,
+			&i.SongRequest,
+			&i.ResponseAt,
+			&i.SheetRow,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LocalChangedAt,
+			&i.Email,
+			&i.Lang,
+			&i.RsvpDeadline,
+			&i.DisabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetPendingInviteEventSyncs = `-- name: GetPendingInviteEventSyncs :many
+SELECT
+    invite_events.invite_code, invite_events.event_key,
+    invite_events.max_adults, invite_events.max_kids,
+    invite_events.confirmed_adults, invite_events.confirmed_kids,
+    invite_events.response_at, invite_events.sheet_row, invite_events.updated_at
+FROM invite_events
+JOIN invites ON invites.invite_code = invite_events.invite_code
+WHERE invite_events.response_at IS NOT NULL
+  AND invite_events.response_at > invite_events.updated_at
+  AND invites.disabled_at IS NULL
+ORDER BY invite_events.event_key ASC, invite_events.response_at ASC
+`
+
+// Finds event answers that changed since they were last written to the sheet.
+//
+//	SELECT
+//	    invite_events.invite_code, invite_events.event_key,
+//	    invite_events.max_adults, invite_events.max_kids,
+//	    invite_events.confirmed_adults, invite_events.confirmed_kids,
+//	    invite_events.response_at, invite_events.sheet_row, invite_events.updated_at
+//	FROM invite_events
+//	JOIN invites ON invites.invite_code = invite_events.invite_code
+//	WHERE invite_events.response_at IS NOT NULL
+//	  AND invite_events.response_at > invite_events.updated_at
+//	  AND invites.disabled_at IS NULL
+//	ORDER BY invite_events.event_key ASC, invite_events.response_at ASC
+func (q *Queries) GetPendingInviteEventSyncs(ctx context.Context) ([]*InviteEvent, error) {
+	rows, err := q.query(ctx, q.getPendingInviteEventSyncsStmt, GetPendingInviteEventSyncs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*InviteEvent{}
+	for rows.Next() {
+		var i InviteEvent
+		if err := rows.Scan(
+			&i.InviteCode,
+			&i.EventKey,
+			&i.MaxAdults,
+			&i.MaxKids,
+			&i.ConfirmedAdults,
+			&i.ConfirmedKids,
+			&i.ResponseAt,
+			&i.SheetRow,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const GetPendingSyncInvites = `-- name: GetPendingSyncInvites :many
-SELECT invite_code, name, max_adults, max_kids, confirmed_adults, confirmed_kids, dietary_info, message_for_us, song_request, response_at, sheet_row, created_at, updated_at FROM invites
+SELECT invite_code, name, max_adults, max_kids, confirmed_adults, confirmed_kids, dietary_info, message_for_us, song_request, response_at, sheet_row, created_at, updated_at, local_changed_at, email, lang, rsvp_deadline, disabled_at FROM invites
 WHERE response_at IS NOT NULL
   AND response_at > updated_at
+  AND disabled_at IS NULL
 ORDER BY response_at ASC
 `
 
 // Finds rows that have responded but haven't been synced OR have changed since sync.
 //
-//	SELECT invite_code, name, max_adults, max_kids, confirmed_adults, confirmed_kids, dietary_info, message_for_us, song_request, response_at, sheet_row, created_at, updated_at FROM invites
+//	SELECT invite_code, name, max_adults, max_kids, confirmed_adults, confirmed_kids, dietary_info, message_for_us, song_request, response_at, sheet_row, created_at, updated_at, local_changed_at, email, lang, rsvp_deadline, disabled_at FROM invites
 //	WHERE response_at IS NOT NULL
 //	  AND response_at > updated_at
+//	  AND disabled_at IS NULL
 //	ORDER BY response_at ASC
 func (q *Queries) GetPendingSyncInvites(ctx context.Context) ([]*Invite, error) {
 	rows, err := q.query(ctx, q.getPendingSyncInvitesStmt, GetPendingSyncInvites)
@@ -108,6 +597,11 @@ func (q *Queries) GetPendingSyncInvites(ctx context.Context) ([]*Invite, error)
 			&i.SheetRow,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.LocalChangedAt,
+			&i.Email,
+			&i.Lang,
+			&i.RsvpDeadline,
+			&i.DisabledAt,
 		); err != nil {
 			return nil, err
 		}
@@ -124,7 +618,7 @@ func (q *Queries) GetPendingSyncInvites(ctx context.Context) ([]*Invite, error)
 
 const GetScheduleEvents = `-- name: GetScheduleEvents :many
 
-SELECT id, start_time, end_time, event_name_es, event_name_en, event_name_ca, location, description_es, description_en, description_ca, updated_at FROM schedule_events
+SELECT id, start_time, end_time, event_name_es, event_name_en, event_name_ca, location, description_es, description_en, description_ca, updated_at, timezone, uid FROM schedule_events
 ORDER BY start_time ASC
 `
 
@@ -134,7 +628,7 @@ ORDER BY start_time ASC
 // Returns all schedule events ordered by start time.
 // Only public events are stored in the DB (filtered during sync).
 //
-//	SELECT id, start_time, end_time, event_name_es, event_name_en, event_name_ca, location, description_es, description_en, description_ca, updated_at FROM schedule_events
+//	SELECT id, start_time, end_time, event_name_es, event_name_en, event_name_ca, location, description_es, description_en, description_ca, updated_at, timezone, uid FROM schedule_events
 //	ORDER BY start_time ASC
 func (q *Queries) GetScheduleEvents(ctx context.Context) ([]*ScheduleEvent, error) {
 	rows, err := q.query(ctx, q.getScheduleEventsStmt, GetScheduleEvents)
@@ -157,6 +651,8 @@ func (q *Queries) GetScheduleEvents(ctx context.Context) ([]*ScheduleEvent, erro
 			&i.DescriptionEn,
 			&i.DescriptionCa,
 			&i.UpdatedAt,
+			&i.Timezone,
+			&i.Uid,
 		); err != nil {
 			return nil, err
 		}
@@ -171,52 +667,178 @@ func (q *Queries) GetScheduleEvents(ctx context.Context) ([]*ScheduleEvent, erro
 	return items, nil
 }
 
-const InsertScheduleEvent = `-- name: InsertScheduleEvent :exec
-INSERT INTO schedule_events (
-    start_time, end_time,
-    event_name_es, event_name_en, event_name_ca,
-    location,
-    description_es, description_en, description_ca,
-    updated_at
+const InsertGuest = `-- name: InsertGuest :exec
+INSERT INTO guests (
+    invite_code, name, is_kid, meal_choice, allergies
 ) VALUES (
-    ?, ?,
-    ?, ?, ?,
-    ?,
-    ?, ?, ?,
-    datetime('now', 'utc')
+    ?, ?, ?, ?, ?
 )
 `
 
-type InsertScheduleEventParams struct {
-	StartTime     string  `json:"start_time"`
-	EndTime       *string `json:"end_time"`
-	EventNameEs   string  `json:"event_name_es"`
-	EventNameEn   string  `json:"event_name_en"`
-	EventNameCa   string  `json:"event_name_ca"`
-	Location      string  `json:"location"`
-	DescriptionEs string  `json:"description_es"`
-	DescriptionEn string  `json:"description_en"`
-	DescriptionCa string  `json:"description_ca"`
+type InsertGuestParams struct {
+	InviteCode string `json:"invite_code"`
+	Name       string `json:"name"`
+	IsKid      bool   `json:"is_kid"`
+	MealChoice string `json:"meal_choice"`
+	Allergies  string `json:"allergies"`
 }
 
-// Inserts a single schedule event during sync.
+// InsertGuest
 //
-//	INSERT INTO schedule_events (
-//	    start_time, end_time,
-//	    event_name_es, event_name_en, event_name_ca,
-//	    location,
-//	    description_es, description_en, description_ca,
-//	    updated_at
+//	INSERT INTO guests (
+//	    invite_code, name, is_kid, meal_choice, allergies
 //	) VALUES (
-//	    ?, ?,
-//	    ?, ?, ?,
-//	    ?,
-//	    ?, ?, ?,
-//	    datetime('now', 'utc')
+//	    ?, ?, ?, ?, ?
 //	)
-func (q *Queries) InsertScheduleEvent(ctx context.Context, arg *InsertScheduleEventParams) error {
-	_, err := q.exec(ctx, q.insertScheduleEventStmt, InsertScheduleEvent,
-		arg.StartTime,
+func (q *Queries) InsertGuest(ctx context.Context, arg *InsertGuestParams) error {
+	_, err := q.exec(ctx, q.insertGuestStmt, InsertGuest,
+		arg.InviteCode,
+		arg.Name,
+		arg.IsKid,
+		arg.MealChoice,
+		arg.Allergies,
+	)
+	return err
+}
+
+const InsertInviteDeletion = `-- name: InsertInviteDeletion :exec
+INSERT INTO invite_deletions (invite_code, sheet_row)
+VALUES (?, ?)
+ON CONFLICT(invite_code) DO UPDATE SET
+    sheet_row  = excluded.sheet_row,
+    deleted_at = datetime('now', 'utc')
+`
+
+type InsertInviteDeletionParams struct {
+	InviteCode string `json:"invite_code"`
+	SheetRow   int64  `json:"sheet_row"`
+}
+
+// Queues removal of a deleted invite's sheet row.
+//
+//	INSERT INTO invite_deletions (invite_code, sheet_row)
+//	VALUES (?, ?)
+//	ON CONFLICT(invite_code) DO UPDATE SET
+//	    sheet_row  = excluded.sheet_row,
+//	    deleted_at = datetime('now', 'utc')
+func (q *Queries) InsertInviteDeletion(ctx context.Context, arg *InsertInviteDeletionParams) error {
+	_, err := q.exec(ctx, q.insertInviteDeletionStmt, InsertInviteDeletion,
+		arg.InviteCode,
+		arg.SheetRow,
+	)
+	return err
+}
+
+const InsertRSVPEvent = `-- name: InsertRSVPEvent :exec
+INSERT INTO rsvp_events (
+    invite_code, event_key, source, client_ip,
+    old_confirmed_adults, old_confirmed_kids, old_dietary_info, old_message_for_us, old_song_request, old_response_at,
+    new_confirmed_adults, new_confirmed_kids, new_dietary_info, new_message_for_us, new_song_request
+) VALUES (
+    ?, ?, ?, ?,
+    ?, ?, ?, ?, ?, ?,
+    ?, ?, ?, ?, ?
+)
+`
+
+type InsertRSVPEventParams struct {
+	InviteCode         string     `json:"invite_code"`
+	EventKey           string     `json:"event_key"`
+	Source             string     `json:"source"`
+	ClientIp           string     `json:"client_ip"`
+	OldConfirmedAdults int64      `json:"old_confirmed_adults"`
+	OldConfirmedKids   int64      `json:"old_confirmed_kids"`
+	OldDietaryInfo     string     `json:"old_dietary_info"`
+	OldMessageForUs    string     `json:"old_message_for_us"`
+	OldSongRequest     string     `json:"old_song_request"`
+	OldResponseAt      *time.Time `json:"old_response_at"`
+	NewConfirmedAdults int64      `json:"new_confirmed_adults"`
+	NewConfirmedKids   int64      `json:"new_confirmed_kids"`
+	NewDietaryInfo     string     `json:"new_dietary_info"`
+	NewMessageForUs    string     `json:"new_message_for_us"`
+	NewSongRequest     string     `json:"new_song_request"`
+}
+
+// Appends an entry to an invite's RSVP timeline.
+//
+//	INSERT INTO rsvp_events (
+//	    invite_code, event_key, source, client_ip,
+//	    old_confirmed_adults, old_confirmed_kids, old_dietary_info, old_message_for_us, old_song_request, old_response_at,
+//	    new_confirmed_adults, new_confirmed_kids, new_dietary_info, new_message_for_us, new_song_request
+//	) VALUES (
+//	    ?, ?, ?, ?,
+//	    ?, ?, ?, ?, ?, ?,
+//	    ?, ?, ?, ?, ?
+//	)
+func (q *Queries) InsertRSVPEvent(ctx context.Context, arg *InsertRSVPEventParams) error {
+	_, err := q.exec(ctx, q.insertRSVPEventStmt, InsertRSVPEvent,
+		arg.InviteCode,
+		arg.EventKey,
+		arg.Source,
+		arg.ClientIp,
+		arg.OldConfirmedAdults,
+		arg.OldConfirmedKids,
+		arg.OldDietaryInfo,
+		arg.OldMessageForUs,
+		arg.OldSongRequest,
+		arg.OldResponseAt,
+		arg.NewConfirmedAdults,
+		arg.NewConfirmedKids,
+		arg.NewDietaryInfo,
+		arg.NewMessageForUs,
+		arg.NewSongRequest,
+	)
+	return err
+}
+
+const InsertScheduleEvent = `-- name: InsertScheduleEvent :exec
+INSERT INTO schedule_events (
+    start_time, end_time,
+    event_name_es, event_name_en, event_name_ca,
+    location,
+    description_es, description_en, description_ca,
+    timezone, uid, updated_at
+) VALUES (
+    ?, ?,
+    ?, ?, ?,
+    ?,
+    ?, ?, ?,
+    ?, ?, datetime('now', 'utc')
+)
+`
+
+type InsertScheduleEventParams struct {
+	StartTime     string  `json:"start_time"`
+	EndTime       *string `json:"end_time"`
+	EventNameEs   string  `json:"event_name_es"`
+	EventNameEn   string  `json:"event_name_en"`
+	EventNameCa   string  `json:"event_name_ca"`
+	Location      string  `json:"location"`
+	DescriptionEs string  `json:"description_es"`
+	DescriptionEn string  `json:"description_en"`
+	DescriptionCa string  `json:"description_ca"`
+	Timezone      string  `json:"timezone"`
+	Uid           string  `json:"uid"`
+}
+
+// Inserts a single schedule event during sync.
+//
+//	INSERT INTO schedule_events (
+//	    start_time, end_time,
+//	    event_name_es, event_name_en, event_name_ca,
+//	    location,
+//	    description_es, description_en, description_ca,
+//	    timezone, uid, updated_at
+//	) VALUES (
+//	    ?, ?,
+//	    ?, ?, ?,
+//	    ?,
+//	    ?, ?, ?,
+//	    ?, ?, datetime('now', 'utc')
+//	)
+func (q *Queries) InsertScheduleEvent(ctx context.Context, arg *InsertScheduleEventParams) error {
+	_, err := q.exec(ctx, q.insertScheduleEventStmt, InsertScheduleEvent,
+		arg.StartTime,
 		arg.EndTime,
 		arg.EventNameEs,
 		arg.EventNameEn,
@@ -225,29 +847,1049 @@ func (q *Queries) InsertScheduleEvent(ctx context.Context, arg *InsertScheduleEv
 		arg.DescriptionEs,
 		arg.DescriptionEn,
 		arg.DescriptionCa,
+		arg.Timezone,
+		arg.Uid,
+	)
+	return err
+}
+
+const InsertSyncRun = `-- name: InsertSyncRun :exec
+
+INSERT INTO sync_runs (
+    started_at, finished_at, duration_ms,
+    invites_read, rsvps_pushed, rsvps_failed, schedule_events, error
+) VALUES (
+    datetime(?1), datetime(?2), ?3,
+    ?4, ?5, ?6, ?7, ?8
+)
+`
+
+type InsertSyncRunParams struct {
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	DurationMs     int64     `json:"duration_ms"`
+	InvitesRead    int64     `json:"invites_read"`
+	RsvpsPushed    int64     `json:"rsvps_pushed"`
+	RsvpsFailed    int64     `json:"rsvps_failed"`
+	ScheduleEvents int64     `json:"schedule_events"`
+	Error          string    `json:"error"`
+}
+
+// =====================
+// Sync Runs Queries
+// =====================
+//
+//	INSERT INTO sync_runs (
+//	    started_at, finished_at, duration_ms,
+//	    invites_read, rsvps_pushed, rsvps_failed, schedule_events, error
+//	) VALUES (
+//	    datetime(?1), datetime(?2), ?3,
+//	    ?4, ?5, ?6, ?7, ?8
+//	)
+func (q *Queries) InsertSyncRun(ctx context.Context, arg *InsertSyncRunParams) error {
+	_, err := q.exec(ctx, q.insertSyncRunStmt, InsertSyncRun,
+		arg.StartedAt,
+		arg.FinishedAt,
+		arg.DurationMs,
+		arg.InvitesRead,
+		arg.RsvpsPushed,
+		arg.RsvpsFailed,
+		arg.ScheduleEvents,
+		arg.Error,
+	)
+	return err
+}
+
+const ListDietaryInfoCounts = `-- name: ListDietaryInfoCounts :many
+SELECT
+    CAST(LOWER(TRIM(dietary_info)) AS TEXT) AS dietary_info,
+    COUNT(*) AS invites,
+    CAST(SUM(confirmed_adults + confirmed_kids) AS INTEGER) AS guests
+FROM invites
+WHERE response_at IS NOT NULL
+  AND disabled_at IS NULL
+  AND confirmed_adults + confirmed_kids > 0
+  AND TRIM(dietary_info) != ''
+GROUP BY LOWER(TRIM(dietary_info))
+ORDER BY guests DESC, dietary_info ASC
+`
+
+type ListDietaryInfoCountsRow struct {
+	DietaryInfo string `json:"dietary_info"`
+	Invites     int64  `json:"invites"`
+	Guests      int64  `json:"guests"`
+}
+
+// Groups the dietary info of attending invites, ignoring case and
+// surrounding whitespace.
+//
+//	SELECT
+//	    CAST(LOWER(TRIM(dietary_info)) AS TEXT) AS dietary_info,
+//	    COUNT(*) AS invites,
+//	    CAST(SUM(confirmed_adults + confirmed_kids) AS INTEGER) AS guests
+//	FROM invites
+//	WHERE response_at IS NOT NULL
+//	  AND disabled_at IS NULL
+//	  AND confirmed_adults + confirmed_kids > 0
+//	  AND TRIM(dietary_info) != ''
+//	GROUP BY LOWER(TRIM(dietary_info))
+//	ORDER BY guests DESC, dietary_info ASC
+func (q *Queries) ListDietaryInfoCounts(ctx context.Context) ([]*ListDietaryInfoCountsRow, error) {
+	rows, err := q.query(ctx, q.listDietaryInfoCountsStmt, ListDietaryInfoCounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListDietaryInfoCountsRow{}
+	for rows.Next() {
+		var i ListDietaryInfoCountsRow
+		if err := rows.Scan(
+			&i.DietaryInfo,
+			&i.Invites,
+			&i.Guests,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListDueEmails = `-- name: ListDueEmails :many
+SELECT id, kind, invite_code, recipient, subject, body, attempts, last_error, next_attempt_at, sent_at, created_at FROM email_outbox
+WHERE sent_at IS NULL
+  AND attempts < ?1
+  AND next_attempt_at <= datetime('now', 'utc')
+ORDER BY id ASC
+LIMIT ?2
+`
+
+type ListDueEmailsParams struct {
+	MaxAttempts int64 `json:"max_attempts"`
+	Limit       int64 `json:"limit"`
+}
+
+// Returns unsent messages whose next attempt is due, oldest first.
+//
+//	SELECT id, kind, invite_code, recipient, subject, body, attempts, last_error, next_attempt_at, sent_at, created_at FROM email_outbox
+//	WHERE sent_at IS NULL
+//	  AND attempts < ?1
+//	  AND next_attempt_at <= datetime('now', 'utc')
+//	ORDER BY id ASC
+//	LIMIT ?2
+func (q *Queries) ListDueEmails(ctx context.Context, arg *ListDueEmailsParams) ([]*EmailOutbox, error) {
+	rows, err := q.query(ctx, q.listDueEmailsStmt, ListDueEmails,
+		arg.MaxAttempts,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*EmailOutbox{}
+	for rows.Next() {
+		var i EmailOutbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.InviteCode,
+			&i.Recipient,
+			&i.Subject,
+			&i.Body,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.SentAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListEvents = `-- name: ListEvents :many
+
+SELECT event_key, position, name_es, name_en, name_ca, start_time, timezone, location, sheet_tab, updated_at FROM events
+ORDER BY position ASC, event_key ASC
+`
+
+// =====================
+// Events Queries
+// =====================
+// Returns all events in the order of the Events tab.
+//
+//	SELECT event_key, position, name_es, name_en, name_ca, start_time, timezone, location, sheet_tab, updated_at FROM events
+//	ORDER BY position ASC, event_key ASC
+func (q *Queries) ListEvents(ctx context.Context) ([]*Event, error) {
+	rows, err := q.query(ctx, q.listEventsStmt, ListEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Event{}
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.EventKey,
+			&i.Position,
+			&i.NameEs,
+			&i.NameEn,
+			&i.NameCa,
+			&i.StartTime,
+			&i.Timezone,
+			&i.Location,
+			&i.SheetTab,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListGuestsByInviteCode = `-- name: ListGuestsByInviteCode :many
+
+SELECT id, invite_code, name, is_kid, meal_choice, allergies, created_at FROM guests
+WHERE invite_code = ?
+ORDER BY id ASC
+`
+
+// =====================
+// Guests Queries
+// =====================
+// Returns the guests of an invite in the order they were entered.
+//
+//	SELECT id, invite_code, name, is_kid, meal_choice, allergies, created_at FROM guests
+//	WHERE invite_code = ?
+//	ORDER BY id ASC
+func (q *Queries) ListGuestsByInviteCode(ctx context.Context, inviteCode string) ([]*Guest, error) {
+	rows, err := q.query(ctx, q.listGuestsByInviteCodeStmt, ListGuestsByInviteCode, inviteCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Guest{}
+	for rows.Next() {
+		var i Guest
+		if err := rows.Scan(
+			&i.ID,
+			&i.InviteCode,
+			&i.Name,
+			&i.IsKid,
+			&i.MealChoice,
+			&i.Allergies,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListInviteDeletions = `-- name: ListInviteDeletions :many
+SELECT invite_code, sheet_row, deleted_at FROM invite_deletions
+ORDER BY sheet_row DESC
+`
+
+// Returns pending sheet row removals, bottom rows first so deleting one
+// doesn't shift the rows of the others.
+//
+//	SELECT invite_code, sheet_row, deleted_at FROM invite_deletions
+//	ORDER BY sheet_row DESC
+func (q *Queries) ListInviteDeletions(ctx context.Context) ([]*InviteDeletion, error) {
+	rows, err := q.query(ctx, q.listInviteDeletionsStmt, ListInviteDeletions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*InviteDeletion{}
+	for rows.Next() {
+		var i InviteDeletion
+		if err := rows.Scan(
+			&i.InviteCode,
+			&i.SheetRow,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListInviteEventsByEvent = `-- name: ListInviteEventsByEvent :many
+SELECT invite_code, event_key, max_adults, max_kids, confirmed_adults, confirmed_kids, response_at, sheet_row, updated_at FROM invite_events
+WHERE event_key = ?
+ORDER BY sheet_row ASC
+`
+
+// Returns everyone invited to an event.
+//
+//	SELECT invite_code, event_key, max_adults, max_kids, confirmed_adults, confirmed_kids, response_at, sheet_row, updated_at FROM invite_events
+//	WHERE event_key = ?
+//	ORDER BY sheet_row ASC
+func (q *Queries) ListInviteEventsByEvent(ctx context.Context, eventKey string) ([]*InviteEvent, error) {
+	rows, err := q.query(ctx, q.listInviteEventsByEventStmt, ListInviteEventsByEvent, eventKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*InviteEvent{}
+	for rows.Next() {
+		var i InviteEvent
+		if err := rows.Scan(
+			&i.InviteCode,
+			&i.EventKey,
+			&i.MaxAdults,
+			&i.MaxKids,
+			&i.ConfirmedAdults,
+			&i.ConfirmedKids,
+			&i.ResponseAt,
+			&i.SheetRow,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListInviteEventsByInviteCode = `-- name: ListInviteEventsByInviteCode :many
+SELECT
+    invite_events.invite_code, invite_events.event_key,
+    invite_events.max_adults, invite_events.max_kids,
+    invite_events.confirmed_adults, invite_events.confirmed_kids,
+    invite_events.response_at, invite_events.sheet_row, invite_events.updated_at
+FROM invite_events
+JOIN events ON events.event_key = invite_events.event_key
+WHERE invite_events.invite_code = ?
+ORDER BY events.position ASC, events.event_key ASC
+`
+
+// Returns the events an invite is invited to, in the order of the Events tab.
+//
+//	SELECT
+//	    invite_events.invite_code, invite_events.event_key,
+//	    invite_events.max_adults, invite_events.max_kids,
+//	    invite_events.confirmed_adults, invite_events.confirmed_kids,
+//	    invite_events.response_at, invite_events.sheet_row, invite_events.updated_at
+//	FROM invite_events
+//	JOIN events ON events.event_key = invite_events.event_key
+//	WHERE invite_events.invite_code = ?
+//	ORDER BY events.position ASC, events.event_key ASC
+func (q *Queries) ListInviteEventsByInviteCode(ctx context.Context, inviteCode string) ([]*InviteEvent, error) {
+	rows, err := q.query(ctx, q.listInviteEventsByInviteCodeStmt, ListInviteEventsByInviteCode, inviteCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*InviteEvent{}
+	for rows.Next() {
+		var i InviteEvent
+		if err := rows.Scan(
+			&i.InviteCode,
+			&i.EventKey,
+			&i.MaxAdults,
+			&i.MaxKids,
+			&i.ConfirmedAdults,
+			&i.ConfirmedKids,
+			&i.ResponseAt,
+			&i.SheetRow,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListInvites = `-- name: ListInvites :many
+
+SELECT invite_code, name, max_adults, max_kids, confirmed_adults, confirmed_kids, dietary_info, message_for_us, song_request, response_at, sheet_row, created_at, updated_at, local_changed_at, email, lang, rsvp_deadline, disabled_at FROM invites
+WHERE CAST(?1 AS TEXT) = ''
+   OR name LIKE '%' || ?1 || '%' ESCAPE '\'
+   OR invite_code LIKE ?1 || '%' ESCAPE '\'
+ORDER BY name ASC
+`
+
+// =====================
+// Admin Queries
+// =====================
+// Lists invites ordered by name, optionally filtered by a name or code search.
+// The search is matched literally once escaped with EscapeLike.
+//
+//	SELECT invite_code, name, max_adults, max_kids, confirmed_adults, confirmed_kids, dietary_info, message_for_us, song_request, response_at, sheet_row, created_at, updated_at, local_changed_at, email, lang, rsvp_deadline, disabled_at FROM invites
+//	WHERE CAST(?1 AS TEXT) = ''
+//	   OR name LIKE '%' || ?1 || '%' ESCAPE '\'
+//	   OR invite_code LIKE ?1 || '%' ESCAPE '\'
+//	ORDER BY name ASC
+func (q *Queries) ListInvites(ctx context.Context, search string) ([]*Invite, error) {
+	rows, err := q.query(ctx, q.listInvitesStmt, ListInvites, search)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Invite{}
+	for rows.Next() {
+		var i Invite
+		if err := rows.Scan(
+			&i.InviteCode,
+			&i.Name,
+			&i.MaxAdults,
+			&i.MaxKids,
+			&i.ConfirmedAdults,
+			&i.ConfirmedKids,
+			&i.DietaryInfo,
+			&i.MessageForUs,
+			&i.SongRequest,
+			&i.ResponseAt,
+			&i.SheetRow,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LocalChangedAt,
+			&i.Email,
+			&i.Lang,
+			&i.RsvpDeadline,
+			&i.DisabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListMealChoiceCounts = `-- name: ListMealChoiceCounts :many
+SELECT
+    meal_choice,
+    COUNT(*) AS guests
+FROM guests
+WHERE invite_code IN (SELECT invite_code FROM invites WHERE disabled_at IS NULL)
+GROUP BY meal_choice
+ORDER BY guests DESC, meal_choice ASC
+`
+
+type ListMealChoiceCountsRow struct {
+	MealChoice string `json:"meal_choice"`
+	Guests     int64  `json:"guests"`
+}
+
+// Counts the meal choices of individual guests (empty if not chosen yet).
+//
+//	SELECT
+//	    meal_choice,
+//	    COUNT(*) AS guests
+//	FROM guests
+//	WHERE invite_code IN (SELECT invite_code FROM invites WHERE disabled_at IS NULL)
+//	GROUP BY meal_choice
+//	ORDER BY guests DESC, meal_choice ASC
+func (q *Queries) ListMealChoiceCounts(ctx context.Context) ([]*ListMealChoiceCountsRow, error) {
+	rows, err := q.query(ctx, q.listMealChoiceCountsStmt, ListMealChoiceCounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListMealChoiceCountsRow{}
+	for rows.Next() {
+		var i ListMealChoiceCountsRow
+		if err := rows.Scan(
+			&i.MealChoice,
+			&i.Guests,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListRSVPEventsByInviteCode = `-- name: ListRSVPEventsByInviteCode :many
+SELECT id, invite_code, source, client_ip, old_confirmed_adults, old_confirmed_kids, old_dietary_info, old_message_for_us, old_song_request, old_response_at, new_confirmed_adults, new_confirmed_kids, new_dietary_info, new_message_for_us, new_song_request, created_at, event_key FROM rsvp_events
+WHERE invite_code = ?
+ORDER BY id ASC
+`
+
+// Returns an invite's RSVP timeline, oldest first.
+//
+//	SELECT id, invite_code, source, client_ip, old_confirmed_adults, old_confirmed_kids, old_dietary_info, old_message_for_us, old_song_request, old_response_at, new_confirmed_adults, new_confirmed_kids, new_dietary_info, new_message_for_us, new_song_request, created_at, event_key FROM rsvp_events
+//	WHERE invite_code = ?
+//	ORDER BY id ASC
+func (q *Queries) ListRSVPEventsByInviteCode(ctx context.Context, inviteCode string) ([]*RsvpEvent, error) {
+	rows, err := q.query(ctx, q.listRSVPEventsByInviteCodeStmt, ListRSVPEventsByInviteCode, inviteCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*RsvpEvent{}
+	for rows.Next() {
+		var i RsvpEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.InviteCode,
+			&i.Source,
+			&i.ClientIp,
+			&i.OldConfirmedAdults,
+			&i.OldConfirmedKids,
+			&i.OldDietaryInfo,
+			&i.OldMessageForUs,
+			&i.OldSongRequest,
+			&i.OldResponseAt,
+			&i.NewConfirmedAdults,
+			&i.NewConfirmedKids,
+			&i.NewDietaryInfo,
+			&i.NewMessageForUs,
+			&i.NewSongRequest,
+			&i.CreatedAt,
+			&i.EventKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListRSVPEventsSince = `-- name: ListRSVPEventsSince :many
+SELECT id, invite_code, source, client_ip, old_confirmed_adults, old_confirmed_kids, old_dietary_info, old_message_for_us, old_song_request, old_response_at, new_confirmed_adults, new_confirmed_kids, new_dietary_info, new_message_for_us, new_song_request, created_at, event_key FROM rsvp_events
+WHERE created_at > datetime(?1)
+ORDER BY id ASC
+`
+
+// Returns every RSVP change after the given time, oldest first, for the digest.
+//
+//	SELECT id, invite_code, source, client_ip, old_confirmed_adults, old_confirmed_kids, old_dietary_info, old_message_for_us, old_song_request, old_response_at, new_confirmed_adults, new_confirmed_kids, new_dietary_info, new_message_for_us, new_song_request, created_at, event_key FROM rsvp_events
+//	WHERE created_at > datetime(?1)
+//	ORDER BY id ASC
+func (q *Queries) ListRSVPEventsSince(ctx context.Context, since time.Time) ([]*RsvpEvent, error) {
+	rows, err := q.query(ctx, q.listRSVPEventsSinceStmt, ListRSVPEventsSince, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*RsvpEvent{}
+	for rows.Next() {
+		var i RsvpEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.InviteCode,
+			&i.Source,
+			&i.ClientIp,
+			&i.OldConfirmedAdults,
+			&i.OldConfirmedKids,
+			&i.OldDietaryInfo,
+			&i.OldMessageForUs,
+			&i.OldSongRequest,
+			&i.OldResponseAt,
+			&i.NewConfirmedAdults,
+			&i.NewConfirmedKids,
+			&i.NewDietaryInfo,
+			&i.NewMessageForUs,
+			&i.NewSongRequest,
+			&i.CreatedAt,
+			&i.EventKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListResponsesByLastAnswerDay = `-- name: ListResponsesByLastAnswerDay :many
+SELECT
+    CAST(DATE(response_at) AS TEXT) AS day,
+    COUNT(*) AS responses,
+    CAST(SUM(confirmed_adults) AS INTEGER) AS confirmed_adults,
+    CAST(SUM(confirmed_kids) AS INTEGER) AS confirmed_kids,
+    CAST(SUM(confirmed_adults + confirmed_kids = 0) AS INTEGER) AS declines
+FROM invites
+WHERE response_at IS NOT NULL
+  AND disabled_at IS NULL
+GROUP BY DATE(response_at)
+ORDER BY day ASC
+`
+
+type ListResponsesByLastAnswerDayRow struct {
+	Day             string `json:"day"`
+	Responses       int64  `json:"responses"`
+	ConfirmedAdults int64  `json:"confirmed_adults"`
+	ConfirmedKids   int64  `json:"confirmed_kids"`
+	Declines        int64  `json:"declines"`
+}
+
+// Counts each invite once, on the UTC day of its latest answer. An invite
+// that changes its answer moves to the day of the change, so this is not how
+// many answers came in each day; rsvp_events has those.
+//
+//	SELECT
+//	    CAST(DATE(response_at) AS TEXT) AS day,
+//	    COUNT(*) AS responses,
+//	    CAST(SUM(confirmed_adults) AS INTEGER) AS confirmed_adults,
+//	    CAST(SUM(confirmed_kids) AS INTEGER) AS confirmed_kids,
+//	    CAST(SUM(confirmed_adults + confirmed_kids = 0) AS INTEGER) AS declines
+//	FROM invites
+//	WHERE response_at IS NOT NULL
+//	  AND disabled_at IS NULL
+//	GROUP BY DATE(response_at)
+//	ORDER BY day ASC
+func (q *Queries) ListResponsesByLastAnswerDay(ctx context.Context) ([]*ListResponsesByLastAnswerDayRow, error) {
+	rows, err := q.query(ctx, q.listResponsesByLastAnswerDayStmt, ListResponsesByLastAnswerDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListResponsesByLastAnswerDayRow{}
+	for rows.Next() {
+		var i ListResponsesByLastAnswerDayRow
+		if err := rows.Scan(
+			&i.Day,
+			&i.Responses,
+			&i.ConfirmedAdults,
+			&i.ConfirmedKids,
+			&i.Declines,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListSyncRuns = `-- name: ListSyncRuns :many
+SELECT id, started_at, finished_at, duration_ms, invites_read, rsvps_pushed, rsvps_failed, schedule_events, error FROM sync_runs
+ORDER BY id DESC
+LIMIT ?1
+`
+
+// Returns the most recent sync runs, newest first.
+//
+//	SELECT id, started_at, finished_at, duration_ms, invites_read, rsvps_pushed, rsvps_failed, schedule_events, error FROM sync_runs
+//	ORDER BY id DESC
+//	LIMIT ?1
+func (q *Queries) ListSyncRuns(ctx context.Context, limit int64) ([]*SyncRun, error) {
+	rows, err := q.query(ctx, q.listSyncRunsStmt, ListSyncRuns, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*SyncRun{}
+	for rows.Next() {
+		var i SyncRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.DurationMs,
+			&i.InvitesRead,
+			&i.RsvpsPushed,
+			&i.RsvpsFailed,
+			&i.ScheduleEvents,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const MarkEmailFailed = `-- name: MarkEmailFailed :exec
+UPDATE email_outbox
+SET
+    attempts        = attempts + 1,
+    last_error      = ?1,
+    next_attempt_at = datetime('now', 'utc', '+' || CAST(?2 AS INTEGER) || ' seconds')
+WHERE id = ?3
+`
+
+type MarkEmailFailedParams struct {
+	LastError      string `json:"last_error"`
+	BackoffSeconds int64  `json:"backoff_seconds"`
+	ID             int64  `json:"id"`
+}
+
+// Records a failed attempt and schedules the next one after a backoff.
+//
+//	UPDATE email_outbox
+//	SET
+//	    attempts        = attempts + 1,
+//	    last_error      = ?1,
+//	    next_attempt_at = datetime('now', 'utc', '+' || CAST(?2 AS INTEGER) || ' seconds')
+//	WHERE id = ?3
+func (q *Queries) MarkEmailFailed(ctx context.Context, arg *MarkEmailFailedParams) error {
+	_, err := q.exec(ctx, q.markEmailFailedStmt, MarkEmailFailed,
+		arg.LastError,
+		arg.BackoffSeconds,
+		arg.ID,
 	)
 	return err
 }
 
-const MarkInviteSynced = `-- name: MarkInviteSynced :exec
+const MarkEmailSent = `-- name: MarkEmailSent :exec
+UPDATE email_outbox
+SET
+    attempts   = attempts + 1,
+    last_error = '',
+    sent_at    = datetime('now', 'utc')
+WHERE id = ?
+`
+
+// MarkEmailSent
+//
+//	UPDATE email_outbox
+//	SET
+//	    attempts   = attempts + 1,
+//	    last_error = '',
+//	    sent_at    = datetime('now', 'utc')
+//	WHERE id = ?
+func (q *Queries) MarkEmailSent(ctx context.Context, id int64) error {
+	_, err := q.exec(ctx, q.markEmailSentStmt, MarkEmailSent, id)
+	return err
+}
+
+const MarkInviteEditSynced = `-- name: MarkInviteEditSynced :exec
 UPDATE invites
+SET
+    sheet_row        = ?1,
+    local_changed_at = CASE
+        WHEN local_changed_at = datetime(?2) THEN NULL
+        ELSE local_changed_at
+    END
+WHERE invite_code = ?3
+`
+
+type MarkInviteEditSyncedParams struct {
+	SheetRow       *int64     `json:"sheet_row"`
+	LocalChangedAt *time.Time `json:"local_changed_at"`
+	InviteCode     string     `json:"invite_code"`
+}
+
+// Records the invite's sheet row and clears the pending edit, unless the
+// invite was edited again since it was read.
+//
+//	UPDATE invites
+//	SET
+//	    sheet_row        = ?1,
+//	    local_changed_at = CASE
+//	        WHEN local_changed_at = datetime(?2) THEN NULL
+//	        ELSE local_changed_at
+//	    END
+//	WHERE invite_code = ?3
+func (q *Queries) MarkInviteEditSynced(ctx context.Context, arg *MarkInviteEditSyncedParams) error {
+	_, err := q.exec(ctx, q.markInviteEditSyncedStmt, MarkInviteEditSynced,
+		arg.SheetRow,
+		arg.LocalChangedAt,
+		arg.InviteCode,
+	)
+	return err
+}
+
+const MarkInviteEventSynced = `-- name: MarkInviteEventSynced :execrows
+UPDATE invite_events
 SET
     updated_at = datetime('now', 'utc')
-WHERE invite_code = ?
+WHERE invite_code = ?1
+  AND event_key   = ?2
+  AND response_at = datetime(?3)
+`
+
+type MarkInviteEventSyncedParams struct {
+	InviteCode string     `json:"invite_code"`
+	EventKey   string     `json:"event_key"`
+	ResponseAt *time.Time `json:"response_at"`
+}
+
+// Like MarkInviteSynced, for an event answer written to the event's tab.
+//
+//	UPDATE invite_events
+//	SET
+//	    updated_at = datetime('now', 'utc')
+//	WHERE invite_code = ?1
+//	  AND event_key   = ?2
+//	  AND response_at = datetime(?3)
+func (q *Queries) MarkInviteEventSynced(ctx context.Context, arg *MarkInviteEventSyncedParams) (int64, error) {
+	result, err := q.exec(ctx, q.markInviteEventSyncedStmt, MarkInviteEventSynced,
+		arg.InviteCode,
+		arg.EventKey,
+		arg.ResponseAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const MarkInviteSynced = `-- name: MarkInviteSynced :execrows
+UPDATE invites
+SET
+    updated_at = datetime('now', 'utc')
+WHERE invite_code = ?1
+  AND response_at = datetime(?2)
 `
 
-// MarkInviteSynced
+type MarkInviteSyncedParams struct {
+	InviteCode string     `json:"invite_code"`
+	ResponseAt *time.Time `json:"response_at"`
+}
+
+// Marks the RSVP written to the sheet as synced, unless the guest answered
+// again since it was read. Returns 0 rows affected in that case.
 //
 //	UPDATE invites
 //	SET
 //	    updated_at = datetime('now', 'utc')
-//	WHERE invite_code = ?
-func (q *Queries) MarkInviteSynced(ctx context.Context, inviteCode string) error {
-	_, err := q.exec(ctx, q.markInviteSyncedStmt, MarkInviteSynced, inviteCode)
+//	WHERE invite_code = ?1
+//	  AND response_at = datetime(?2)
+func (q *Queries) MarkInviteSynced(ctx context.Context, arg *MarkInviteSyncedParams) (int64, error) {
+	result, err := q.exec(ctx, q.markInviteSyncedStmt, MarkInviteSynced,
+		arg.InviteCode,
+		arg.ResponseAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const ShiftInviteDeletionRows = `-- name: ShiftInviteDeletionRows :exec
+UPDATE invite_deletions
+SET sheet_row = sheet_row - 1
+WHERE sheet_row > ?
+`
+
+// ShiftInviteDeletionRows
+//
+//	UPDATE invite_deletions
+//	SET sheet_row = sheet_row - 1
+//	WHERE sheet_row > ?
+func (q *Queries) ShiftInviteDeletionRows(ctx context.Context, sheetRow int64) error {
+	_, err := q.exec(ctx, q.shiftInviteDeletionRowsStmt, ShiftInviteDeletionRows, sheetRow)
+	return err
+}
+
+const ShiftInviteRows = `-- name: ShiftInviteRows :exec
+UPDATE invites
+SET sheet_row = sheet_row - 1
+WHERE sheet_row > ?
+`
+
+// Moves invites below a removed sheet row up by one, matching the sheet.
+//
+//	UPDATE invites
+//	SET sheet_row = sheet_row - 1
+//	WHERE sheet_row > ?
+func (q *Queries) ShiftInviteRows(ctx context.Context, sheetRow int64) error {
+	_, err := q.exec(ctx, q.shiftInviteRowsStmt, ShiftInviteRows, sheetRow)
+	return err
+}
+
+const UpdateInviteContact = `-- name: UpdateInviteContact :exec
+
+UPDATE invites
+SET
+    email = ?1,
+    lang  = ?2
+WHERE invite_code = ?3
+`
+
+type UpdateInviteContactParams struct {
+	Email      string `json:"email"`
+	Lang       string `json:"lang"`
+	InviteCode string `json:"invite_code"`
+}
+
+// =====================
+// Email Outbox Queries
+// =====================
+// Stores the email and language a guest left with their RSVP.
+//
+//	UPDATE invites
+//	SET
+//	    email = ?1,
+//	    lang  = ?2
+//	WHERE invite_code = ?3
+func (q *Queries) UpdateInviteContact(ctx context.Context, arg *UpdateInviteContactParams) error {
+	_, err := q.exec(ctx, q.updateInviteContactStmt, UpdateInviteContact,
+		arg.Email,
+		arg.Lang,
+		arg.InviteCode,
+	)
 	return err
 }
 
-const UpdateRSVP = `-- name: UpdateRSVP :exec
+const UpdateInviteDetails = `-- name: UpdateInviteDetails :execrows
+UPDATE invites
+SET
+    name             = ?1,
+    max_adults       = ?2,
+    max_kids         = ?3,
+    local_changed_at = datetime('now', 'utc')
+WHERE invite_code = ?4
+  AND confirmed_adults <= ?2
+  AND confirmed_kids   <= ?3
+`
+
+type UpdateInviteDetailsParams struct {
+	Name       string `json:"name"`
+	MaxAdults  int64  `json:"max_adults"`
+	MaxKids    int64  `json:"max_kids"`
+	InviteCode string `json:"invite_code"`
+}
+
+// Edits an invite's master data from the admin API and marks it for push.
+// Returns 0 rows affected when the new limits are below the confirmed counts.
+//
+//	UPDATE invites
+//	SET
+//	    name             = ?1,
+//	    max_adults       = ?2,
+//	    max_kids         = ?3,
+//	    local_changed_at = datetime('now', 'utc')
+//	WHERE invite_code = ?4
+//	  AND confirmed_adults <= ?2
+//	  AND confirmed_kids   <= ?3
+func (q *Queries) UpdateInviteDetails(ctx context.Context, arg *UpdateInviteDetailsParams) (int64, error) {
+	result, err := q.exec(ctx, q.updateInviteDetailsStmt, UpdateInviteDetails,
+		arg.Name,
+		arg.MaxAdults,
+		arg.MaxKids,
+		arg.InviteCode,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const UpdateInviteEventRSVP = `-- name: UpdateInviteEventRSVP :execrows
+UPDATE invite_events
+SET
+    confirmed_adults = ?1,
+    confirmed_kids   = ?2,
+    response_at      = datetime('now', 'utc')
+WHERE invite_code = ?3
+  AND event_key   = ?4
+  AND ?1 <= max_adults
+  AND ?2   <= max_kids
+`
+
+type UpdateInviteEventRSVPParams struct {
+	ConfirmedAdults int64  `json:"confirmed_adults"`
+	ConfirmedKids   int64  `json:"confirmed_kids"`
+	InviteCode      string `json:"invite_code"`
+	EventKey        string `json:"event_key"`
+}
+
+// Saves an invite's answer for one event and marks it as needing sync.
+// Returns 0 rows affected when the invite isn't invited or the counts exceed its limits.
+//
+//	UPDATE invite_events
+//	SET
+//	    confirmed_adults = ?1,
+//	    confirmed_kids   = ?2,
+//	    response_at      = datetime('now', 'utc')
+//	WHERE invite_code = ?3
+//	  AND event_key   = ?4
+//	  AND ?1 <= max_adults
+//	  AND ?2   <= max_kids
+func (q *Queries) UpdateInviteEventRSVP(ctx context.Context, arg *UpdateInviteEventRSVPParams) (int64, error) {
+	result, err := q.exec(ctx, q.updateInviteEventRSVPStmt, UpdateInviteEventRSVP,
+		arg.ConfirmedAdults,
+		arg.ConfirmedKids,
+		arg.InviteCode,
+		arg.EventKey,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const UpdateRSVP = `-- name: UpdateRSVP :execrows
 UPDATE invites
 SET
     confirmed_adults = ?1,
@@ -273,6 +1915,7 @@ type UpdateRSVPParams struct {
 }
 
 // Updates RSVP details and forces a sync (synced_at = NULL).
+// Returns 0 rows affected when the counts exceed the invite's limits.
 //
 //	UPDATE invites
 //	SET
@@ -287,8 +1930,8 @@ type UpdateRSVPParams struct {
 //	    -- Validation Logic:
 //	    AND ?1 <= max_adults
 //	    AND ?2   <= max_kids
-func (q *Queries) UpdateRSVP(ctx context.Context, arg *UpdateRSVPParams) error {
-	_, err := q.exec(ctx, q.updateRSVPStmt, UpdateRSVP,
+func (q *Queries) UpdateRSVP(ctx context.Context, arg *UpdateRSVPParams) (int64, error) {
+	result, err := q.exec(ctx, q.updateRSVPStmt, UpdateRSVP,
 		arg.InputConfirmedAdults,
 		arg.InputConfirmedKids,
 		arg.InputDietaryInfo,
@@ -296,14 +1939,79 @@ func (q *Queries) UpdateRSVP(ctx context.Context, arg *UpdateRSVPParams) error {
 		arg.InputSong,
 		arg.InputInviteCode,
 	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const UpsertEvent = `-- name: UpsertEvent :exec
+INSERT INTO events (
+    event_key, position, name_es, name_en, name_ca, start_time, timezone, location, sheet_tab, updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now', 'utc')
+)
+ON CONFLICT(event_key) DO UPDATE SET
+    position   = excluded.position,
+    name_es    = excluded.name_es,
+    name_en    = excluded.name_en,
+    name_ca    = excluded.name_ca,
+    start_time = excluded.start_time,
+    timezone   = excluded.timezone,
+    location   = excluded.location,
+    sheet_tab  = excluded.sheet_tab,
+    updated_at = excluded.updated_at
+`
+
+type UpsertEventParams struct {
+	EventKey  string `json:"event_key"`
+	Position  int64  `json:"position"`
+	NameEs    string `json:"name_es"`
+	NameEn    string `json:"name_en"`
+	NameCa    string `json:"name_ca"`
+	StartTime string `json:"start_time"`
+	Timezone  string `json:"timezone"`
+	Location  string `json:"location"`
+	SheetTab  string `json:"sheet_tab"`
+}
+
+// Syncs an event from the Events tab -> DB.
+//
+//	INSERT INTO events (
+//	    event_key, position, name_es, name_en, name_ca, start_time, timezone, location, sheet_tab, updated_at
+//	) VALUES (
+//	    ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now', 'utc')
+//	)
+//	ON CONFLICT(event_key) DO UPDATE SET
+//	    position   = excluded.position,
+//	    name_es    = excluded.name_es,
+//	    name_en    = excluded.name_en,
+//	    name_ca    = excluded.name_ca,
+//	    start_time = excluded.start_time,
+//	    timezone   = excluded.timezone,
+//	    location   = excluded.location,
+//	    sheet_tab  = excluded.sheet_tab,
+//	    updated_at = excluded.updated_at
+func (q *Queries) UpsertEvent(ctx context.Context, arg *UpsertEventParams) error {
+	_, err := q.exec(ctx, q.upsertEventStmt, UpsertEvent,
+		arg.EventKey,
+		arg.Position,
+		arg.NameEs,
+		arg.NameEn,
+		arg.NameCa,
+		arg.StartTime,
+		arg.Timezone,
+		arg.Location,
+		arg.SheetTab,
+	)
 	return err
 }
 
 const UpsertInvite = `-- name: UpsertInvite :exec
 INSERT INTO invites (
-    invite_code, name, max_adults, max_kids, confirmed_adults, sheet_row, updated_at
+    invite_code, name, max_adults, max_kids, confirmed_adults, sheet_row, rsvp_deadline, updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, datetime('now', 'utc')
+    ?, ?, ?, ?, ?, ?, datetime(?), datetime('now', 'utc')
 )
 ON CONFLICT(invite_code) DO UPDATE SET
     name       = excluded.name,
@@ -311,26 +2019,30 @@ ON CONFLICT(invite_code) DO UPDATE SET
     max_kids   = excluded.max_kids,
     sheet_row  = excluded.sheet_row,
     confirmed_adults = excluded.confirmed_adults,
+    rsvp_deadline    = excluded.rsvp_deadline,
+    disabled_at      = NULL,
     updated_at = excluded.updated_at
-WHERE invites.response_at IS NULL OR invites.response_at <= invites.updated_at
+WHERE (invites.response_at IS NULL OR invites.response_at <= invites.updated_at)
+  AND invites.local_changed_at IS NULL
 `
 
 type UpsertInviteParams struct {
-	InviteCode      string `json:"invite_code"`
-	Name            string `json:"name"`
-	MaxAdults       int64  `json:"max_adults"`
-	MaxKids         int64  `json:"max_kids"`
-	ConfirmedAdults int64  `json:"confirmed_adults"`
-	SheetRow        *int64 `json:"sheet_row"`
+	InviteCode      string     `json:"invite_code"`
+	Name            string     `json:"name"`
+	MaxAdults       int64      `json:"max_adults"`
+	MaxKids         int64      `json:"max_kids"`
+	ConfirmedAdults int64      `json:"confirmed_adults"`
+	SheetRow        *int64     `json:"sheet_row"`
+	RsvpDeadline    *time.Time `json:"rsvp_deadline"`
 }
 
 // Syncs Master Data from Google Sheets -> DB.
 // Skips updates if invite has unsynced local changes (synced_at IS NULL).
 //
 //	INSERT INTO invites (
-//	    invite_code, name, max_adults, max_kids, confirmed_adults, sheet_row, updated_at
+//	    invite_code, name, max_adults, max_kids, confirmed_adults, sheet_row, rsvp_deadline, updated_at
 //	) VALUES (
-//	    ?, ?, ?, ?, ?, ?, datetime('now', 'utc')
+//	    ?, ?, ?, ?, ?, ?, datetime(?), datetime('now', 'utc')
 //	)
 //	ON CONFLICT(invite_code) DO UPDATE SET
 //	    name       = excluded.name,
@@ -338,8 +2050,11 @@ type UpsertInviteParams struct {
 //	    max_kids   = excluded.max_kids,
 //	    sheet_row  = excluded.sheet_row,
 //	    confirmed_adults = excluded.confirmed_adults,
+//	    rsvp_deadline    = excluded.rsvp_deadline,
+//	    disabled_at      = NULL,
 //	    updated_at = excluded.updated_at
-//	WHERE invites.response_at IS NULL OR invites.response_at <= invites.updated_at
+//	WHERE (invites.response_at IS NULL OR invites.response_at <= invites.updated_at)
+//	  AND invites.local_changed_at IS NULL
 func (q *Queries) UpsertInvite(ctx context.Context, arg *UpsertInviteParams) error {
 	_, err := q.exec(ctx, q.upsertInviteStmt, UpsertInvite,
 		arg.InviteCode,
@@ -348,6 +2063,64 @@ func (q *Queries) UpsertInvite(ctx context.Context, arg *UpsertInviteParams) err
 		arg.MaxKids,
 		arg.ConfirmedAdults,
 		arg.SheetRow,
+		arg.RsvpDeadline,
+	)
+	return err
+}
+
+const UpsertInviteEvent = `-- name: UpsertInviteEvent :exec
+INSERT INTO invite_events (
+    invite_code, event_key, max_adults, max_kids, confirmed_adults, confirmed_kids, sheet_row, updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, datetime('now', 'utc')
+)
+ON CONFLICT(invite_code, event_key) DO UPDATE SET
+    max_adults       = excluded.max_adults,
+    max_kids         = excluded.max_kids,
+    confirmed_adults = excluded.confirmed_adults,
+    confirmed_kids   = excluded.confirmed_kids,
+    sheet_row        = excluded.sheet_row,
+    updated_at       = excluded.updated_at
+WHERE invite_events.response_at IS NULL
+   OR invite_events.response_at <= invite_events.updated_at
+`
+
+type UpsertInviteEventParams struct {
+	InviteCode      string `json:"invite_code"`
+	EventKey        string `json:"event_key"`
+	MaxAdults       int64  `json:"max_adults"`
+	MaxKids         int64  `json:"max_kids"`
+	ConfirmedAdults int64  `json:"confirmed_adults"`
+	ConfirmedKids   int64  `json:"confirmed_kids"`
+	SheetRow        *int64 `json:"sheet_row"`
+}
+
+// Syncs an invitation from the event's tab -> DB.
+// Like UpsertInvite, skips invitations with an answer not yet written to the tab.
+//
+//	INSERT INTO invite_events (
+//	    invite_code, event_key, max_adults, max_kids, confirmed_adults, confirmed_kids, sheet_row, updated_at
+//	) VALUES (
+//	    ?, ?, ?, ?, ?, ?, ?, datetime('now', 'utc')
+//	)
+//	ON CONFLICT(invite_code, event_key) DO UPDATE SET
+//	    max_adults       = excluded.max_adults,
+//	    max_kids         = excluded.max_kids,
+//	    confirmed_adults = excluded.confirmed_adults,
+//	    confirmed_kids   = excluded.confirmed_kids,
+//	    sheet_row        = excluded.sheet_row,
+//	    updated_at       = excluded.updated_at
+//	WHERE invite_events.response_at IS NULL
+//	   OR invite_events.response_at <= invite_events.updated_at
+func (q *Queries) UpsertInviteEvent(ctx context.Context, arg *UpsertInviteEventParams) error {
+	_, err := q.exec(ctx, q.upsertInviteEventStmt, UpsertInviteEvent,
+		arg.InviteCode,
+		arg.EventKey,
+		arg.MaxAdults,
+		arg.MaxKids,
+		arg.ConfirmedAdults,
+		arg.ConfirmedKids,
+		arg.SheetRow,
 	)
 	return err
 }
@@ -0,0 +1,209 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// RSVP change sources recorded in the rsvp_events audit log
+const (
+	RSVPSourceGuest = "guest"
+	RSVPSourceSheet = "sheet"
+	RSVPSourceAdmin = "admin"
+)
+
+// ErrRSVPRejected is returned when an RSVP exceeds the invite's limits
+var ErrRSVPRejected = errors.New("RSVP exceeds the invite's limits")
+
+// ErrRSVPConflict is returned when the RSVP changed since the client loaded it
+var ErrRSVPConflict = errors.New("RSVP was changed by someone else, reload it and try again")
+
+// ErrInviteDisabled is returned when the invite's row was removed from the sheet
+var ErrInviteDisabled = errors.New("invite is no longer valid")
+
+// RSVPChange identifies who made an RSVP change, for the audit log
+type RSVPChange struct {
+	Source   string   // One of the RSVPSource* constants
+	ClientIP string   // Empty for sheet-driven changes
+	IfMatch  []string // Saves only if the current RSVPVersion is one of these, when set
+}
+
+// SaveRSVP updates an invite's RSVP answers and, when guests is non-nil,
+// replaces its guest list. events holds the answers for the other events the
+// invite is invited to, events it leaves out keep their answer. The previous
+// and new wedding answers are appended to rsvp_events, followed by those of
+// each event whose answer changed. Everything happens in a single
+// transaction so the counts, the guest records and the audit log never
+// disagree.
+func (s *Store) SaveRSVP(ctx context.Context, params *UpdateRSVPParams, guests []*InsertGuestParams, events []*UpdateInviteEventRSVPParams, change RSVPChange) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := s.WithTx(tx)
+
+	old, err := q.GetInviteByInviteCode(ctx, params.InputInviteCode)
+	if err != nil {
+		return fmt.Errorf("failed to load invite: %w", err)
+	}
+	if old.Disabled() {
+		return ErrInviteDisabled
+	}
+
+	oldInvitations, err := q.ListInviteEventsByInviteCode(ctx, params.InputInviteCode)
+	if err != nil {
+		return fmt.Errorf("failed to load event invitations: %w", err)
+	}
+
+	if len(change.IfMatch) > 0 {
+		oldGuests, err := q.ListGuestsByInviteCode(ctx, params.InputInviteCode)
+		if err != nil {
+			return fmt.Errorf("failed to load guests: %w", err)
+		}
+		if !slices.Contains(change.IfMatch, RSVPVersion(old, oldGuests, oldInvitations)) {
+			return ErrRSVPConflict
+		}
+	}
+
+	updated, err := q.UpdateRSVP(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to update RSVP: %w", err)
+	}
+	if updated == 0 {
+		return ErrRSVPRejected
+	}
+
+	for _, event := range events {
+		event.InviteCode = params.InputInviteCode
+		updated, err := q.UpdateInviteEventRSVP(ctx, event)
+		if err != nil {
+			return fmt.Errorf("failed to update RSVP for event %s: %w", event.EventKey, err)
+		}
+		if updated == 0 {
+			return ErrRSVPRejected
+		}
+	}
+
+	if guests != nil {
+		if err := q.DeleteGuestsByInviteCode(ctx, params.InputInviteCode); err != nil {
+			return fmt.Errorf("failed to clear guests: %w", err)
+		}
+		for _, guest := range guests {
+			guest.InviteCode = params.InputInviteCode
+			if err := q.InsertGuest(ctx, guest); err != nil {
+				return fmt.Errorf("failed to insert guest: %w", err)
+			}
+		}
+	}
+
+	current, err := q.GetInviteByInviteCode(ctx, params.InputInviteCode)
+	if err != nil {
+		return fmt.Errorf("failed to reload invite: %w", err)
+	}
+
+	if err := q.InsertRSVPEvent(ctx, NewRSVPEvent(old, current, change)); err != nil {
+		return fmt.Errorf("failed to record RSVP event: %w", err)
+	}
+
+	if len(events) > 0 {
+		invitations, err := q.ListInviteEventsByInviteCode(ctx, params.InputInviteCode)
+		if err != nil {
+			return fmt.Errorf("failed to reload event invitations: %w", err)
+		}
+		for _, invitation := range invitations {
+			i := slices.IndexFunc(oldInvitations, func(old *InviteEvent) bool { return old.EventKey == invitation.EventKey })
+			if i == -1 || !EventRSVPChanged(oldInvitations[i], invitation) {
+				continue
+			}
+			if err := q.InsertRSVPEvent(ctx, NewEventRSVPEvent(oldInvitations[i], invitation, change)); err != nil {
+				return fmt.Errorf("failed to record RSVP event for event %s: %w", invitation.EventKey, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RSVPVersion returns a short hash of an invite's limits, answers, guests and
+// event invitations. It changes whenever any of them do and is served as the
+// invite's ETag.
+func RSVPVersion(invite *Invite, guests []*Guest, invitations []*InviteEvent) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d %d %d %d %q %q %q",
+		invite.MaxAdults, invite.MaxKids, invite.ConfirmedAdults, invite.ConfirmedKids,
+		invite.DietaryInfo, invite.MessageForUs, invite.SongRequest)
+	if invite.ResponseAt != nil {
+		fmt.Fprintf(h, " %s", invite.ResponseAt.UTC().Format(time.RFC3339))
+	}
+	for _, guest := range guests {
+		fmt.Fprintf(h, "\n%q %t %q %q", guest.Name, guest.IsKid, guest.MealChoice, guest.Allergies)
+	}
+	for _, invitation := range invitations {
+		fmt.Fprintf(h, "\nevent %q %d %d %d %d", invitation.EventKey,
+			invitation.MaxAdults, invitation.MaxKids, invitation.ConfirmedAdults, invitation.ConfirmedKids)
+		if invitation.ResponseAt != nil {
+			fmt.Fprintf(h, " %s", invitation.ResponseAt.UTC().Format(time.RFC3339))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// NewRSVPEvent builds the audit log entry for a change from old to current
+func NewRSVPEvent(old, current *Invite, change RSVPChange) *InsertRSVPEventParams {
+	return &InsertRSVPEventParams{
+		InviteCode:         current.InviteCode,
+		Source:             change.Source,
+		ClientIp:           change.ClientIP,
+		OldConfirmedAdults: old.ConfirmedAdults,
+		OldConfirmedKids:   old.ConfirmedKids,
+		OldDietaryInfo:     old.DietaryInfo,
+		OldMessageForUs:    old.MessageForUs,
+		OldSongRequest:     old.SongRequest,
+		OldResponseAt:      old.ResponseAt,
+		NewConfirmedAdults: current.ConfirmedAdults,
+		NewConfirmedKids:   current.ConfirmedKids,
+		NewDietaryInfo:     current.DietaryInfo,
+		NewMessageForUs:    current.MessageForUs,
+		NewSongRequest:     current.SongRequest,
+	}
+}
+
+// NewEventRSVPEvent builds the audit log entry for a change of an event
+// answer from old to current. Only the counts apply to events.
+func NewEventRSVPEvent(old, current *InviteEvent, change RSVPChange) *InsertRSVPEventParams {
+	return &InsertRSVPEventParams{
+		InviteCode:         current.InviteCode,
+		EventKey:           current.EventKey,
+		Source:             change.Source,
+		ClientIp:           change.ClientIP,
+		OldConfirmedAdults: old.ConfirmedAdults,
+		OldConfirmedKids:   old.ConfirmedKids,
+		OldResponseAt:      old.ResponseAt,
+		NewConfirmedAdults: current.ConfirmedAdults,
+		NewConfirmedKids:   current.ConfirmedKids,
+	}
+}
+
+// EventRSVPChanged reports whether an event answer was given for the first
+// time or its counts differ between two invitation snapshots
+func EventRSVPChanged(old, current *InviteEvent) bool {
+	return old.ResponseAt == nil ||
+		old.ConfirmedAdults != current.ConfirmedAdults ||
+		old.ConfirmedKids != current.ConfirmedKids
+}
+
+// RSVPChanged reports whether any RSVP answer differs between two invite snapshots
+func RSVPChanged(old, current *Invite) bool {
+	return old.ConfirmedAdults != current.ConfirmedAdults ||
+		old.ConfirmedKids != current.ConfirmedKids ||
+		old.DietaryInfo != current.DietaryInfo ||
+		old.MessageForUs != current.MessageForUs ||
+		old.SongRequest != current.SongRequest
+}
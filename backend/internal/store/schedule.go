@@ -0,0 +1,14 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// ScheduleEventUID derives the calendar UID of a new schedule event from its
+// start time and Spanish name. The sync stores it and keeps it while the
+// event is moved or renamed.
+func ScheduleEventUID(startTime, nameES string) string {
+	sum := sha256.Sum256([]byte(startTime + "\x00" + nameES))
+	return hex.EncodeToString(sum[:16])
+}
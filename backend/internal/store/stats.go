@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// Stats aggregates RSVP numbers over all invites
+type Stats struct {
+	Totals                   *GetInviteStatsRow
+	Dietary                  []*ListDietaryInfoCountsRow
+	MealChoices              []*ListMealChoiceCountsRow
+	ResponsesByLastAnswerDay []*ListResponsesByLastAnswerDayRow
+}
+
+// GetStats computes the RSVP dashboard numbers. All queries run in one
+// read transaction so the totals and breakdowns agree with each other.
+func (s *Store) GetStats(ctx context.Context) (*Stats, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := s.WithTx(tx)
+
+	var stats Stats
+	if stats.Totals, err = q.GetInviteStats(ctx); err != nil {
+		return nil, fmt.Errorf("failed to count invites: %w", err)
+	}
+	if stats.Dietary, err = q.ListDietaryInfoCounts(ctx); err != nil {
+		return nil, fmt.Errorf("failed to count dietary info: %w", err)
+	}
+	if stats.MealChoices, err = q.ListMealChoiceCounts(ctx); err != nil {
+		return nil, fmt.Errorf("failed to count meal choices: %w", err)
+	}
+	if stats.ResponsesByLastAnswerDay, err = q.ListResponsesByLastAnswerDay(ctx); err != nil {
+		return nil, fmt.Errorf("failed to count responses by last answer day: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// PendingInvites returns the number of invites that haven't responded yet
+func (t *GetInviteStatsRow) PendingInvites() int64 {
+	return t.TotalInvites - t.RespondedInvites
+}
+
+// AttendingInvites returns the number of invites with at least one guest attending
+func (t *GetInviteStatsRow) AttendingInvites() int64 {
+	return t.RespondedInvites - t.DeclinedInvites
+}
+
+// ResponseRate returns the fraction of invites that have responded (0 to 1)
+func (t *GetInviteStatsRow) ResponseRate() float64 {
+	if t.TotalInvites == 0 {
+		return 0
+	}
+	return float64(t.RespondedInvites) / float64(t.TotalInvites)
+}
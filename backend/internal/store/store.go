@@ -15,7 +15,13 @@ type Store struct {
 
 // New creates a new database connection
 func Open(dbPath string) (*Store, error) {
-	sqlDB, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_timeout=5000")
+	// _time_format=sqlite binds time.Time params as "2006-01-02 15:04:05+00:00".
+	// The driver's default is Go's time.String() ("... +0000 UTC"), which
+	// datetime() can't parse and turns into NULL. Queries that match a time
+	// read earlier, like MarkInviteSynced and MarkInviteEditSynced, would then
+	// never match, and the same answers and edits would be pushed to the sheet
+	// on every sync.
+	sqlDB, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_timeout=5000&_time_format=sqlite")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// syncRunsKept is how many sync runs are kept, about a day at the default 1m interval
+const syncRunsKept = 2000
+
+// SyncStatus is the sync history shown by the admin API and `sync status`
+type SyncStatus struct {
+	LastSuccess *SyncRun   // Nil if no cycle has succeeded yet
+	Runs        []*SyncRun // Newest first
+}
+
+// Stale reports whether no sync has succeeded within maxAge of now. Before
+// the first successful run, the age is counted from since instead.
+func (s *SyncStatus) Stale(now, since time.Time, maxAge time.Duration) bool {
+	if s.LastSuccess != nil {
+		since = s.LastSuccess.FinishedAt
+	}
+	return now.Sub(since) > maxAge
+}
+
+// RecordSyncRun stores a finished sync cycle and drops the oldest runs
+func (s *Store) RecordSyncRun(ctx context.Context, run *InsertSyncRunParams) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := s.WithTx(tx)
+
+	if err := q.InsertSyncRun(ctx, run); err != nil {
+		return fmt.Errorf("failed to insert sync run: %w", err)
+	}
+	if err := q.DeleteOldSyncRuns(ctx, syncRunsKept); err != nil {
+		return fmt.Errorf("failed to delete old sync runs: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetSyncStatus returns the last successful sync and the latest limit runs
+func (s *Store) GetSyncStatus(ctx context.Context, limit int64) (*SyncStatus, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := s.WithTx(tx)
+
+	var status SyncStatus
+	status.LastSuccess, err = q.GetLastSuccessfulSyncRun(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		status.LastSuccess = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get last successful sync run: %w", err)
+	}
+	if status.Runs, err = q.ListSyncRuns(ctx, limit); err != nil {
+		return nil, fmt.Errorf("failed to list sync runs: %w", err)
+	}
+
+	return &status, nil
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_schedule_events_start_time;
+DROP TABLE IF EXISTS schedule_events;
+
+DROP INDEX IF EXISTS idx_invites_response_at;
+DROP TABLE IF EXISTS invites;
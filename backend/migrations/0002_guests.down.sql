@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_guests_invite_code;
+DROP TABLE IF EXISTS guests;
@@ -0,0 +1,15 @@
+-- Guests table: the individual people attending under an invite.
+-- Replaced as a whole on every RSVP that includes a guest list.
+CREATE TABLE IF NOT EXISTS guests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    invite_code TEXT NOT NULL REFERENCES invites(invite_code) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    is_kid BOOLEAN NOT NULL DEFAULT 0 CHECK (is_kid IN (0, 1)),
+    meal_choice TEXT NOT NULL DEFAULT '',   -- One of the menu options, empty if not chosen yet
+    allergies TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT (datetime('now', 'utc'))
+);
+
+-- OPTIMIZATION: Index for fetching an invite's guests
+CREATE INDEX IF NOT EXISTS idx_guests_invite_code
+ON guests(invite_code);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_rsvp_events_invite_code;
+DROP TABLE IF EXISTS rsvp_events;
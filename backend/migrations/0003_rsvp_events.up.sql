@@ -0,0 +1,28 @@
+-- RSVP Events table: append-only audit log of every RSVP change.
+-- Written in the same transaction as the change itself; rows are never
+-- updated or deleted, and outlive the invite they refer to.
+CREATE TABLE IF NOT EXISTS rsvp_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    invite_code TEXT NOT NULL,
+    source TEXT NOT NULL CHECK (source IN ('guest', 'sheet', 'admin')),
+    client_ip TEXT NOT NULL DEFAULT '',     -- Empty for sheet-driven changes
+
+    old_confirmed_adults INTEGER NOT NULL,
+    old_confirmed_kids INTEGER NOT NULL,
+    old_dietary_info TEXT NOT NULL,
+    old_message_for_us TEXT NOT NULL,
+    old_song_request TEXT NOT NULL,
+    old_response_at DATETIME,               -- NULL if this is the first response
+
+    new_confirmed_adults INTEGER NOT NULL,
+    new_confirmed_kids INTEGER NOT NULL,
+    new_dietary_info TEXT NOT NULL,
+    new_message_for_us TEXT NOT NULL,
+    new_song_request TEXT NOT NULL,
+
+    created_at DATETIME NOT NULL DEFAULT (datetime('now', 'utc'))
+);
+
+-- OPTIMIZATION: Index for fetching an invite's timeline
+CREATE INDEX IF NOT EXISTS idx_rsvp_events_invite_code
+ON rsvp_events(invite_code, id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS invite_deletions;
+
+DROP INDEX IF EXISTS idx_invites_local_changed_at;
+ALTER TABLE invites DROP COLUMN local_changed_at;
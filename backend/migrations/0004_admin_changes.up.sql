@@ -0,0 +1,18 @@
+-- Admin edits to invite master data (name, limits) are pushed back to the
+-- sheet. local_changed_at marks an invite with such an unsynced edit and is
+-- cleared once the sheet has been updated.
+ALTER TABLE invites ADD COLUMN local_changed_at DATETIME;
+
+-- OPTIMIZATION: Index for the admin edit sync queue
+CREATE INDEX IF NOT EXISTS idx_invites_local_changed_at
+ON invites(local_changed_at)
+WHERE local_changed_at IS NOT NULL;
+
+-- Invite Deletions table: invites deleted through the admin API whose sheet
+-- row still has to be removed. Rows listed here are ignored when reading the
+-- sheet so a deleted invite isn't re-imported before its row is gone.
+CREATE TABLE IF NOT EXISTS invite_deletions (
+    invite_code TEXT PRIMARY KEY,
+    sheet_row INTEGER NOT NULL,
+    deleted_at DATETIME NOT NULL DEFAULT (datetime('now', 'utc'))
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_email_outbox_due;
+DROP TABLE IF EXISTS email_outbox;
+
+ALTER TABLE invites DROP COLUMN lang;
+ALTER TABLE invites DROP COLUMN email;
@@ -0,0 +1,25 @@
+-- Contact details captured with the RSVP, used for the confirmation email
+ALTER TABLE invites ADD COLUMN email TEXT NOT NULL DEFAULT '';
+ALTER TABLE invites ADD COLUMN lang TEXT NOT NULL DEFAULT 'es';  -- es, en or ca
+
+-- Email Outbox table: queued notification emails. Messages are rendered when
+-- queued and sent by a background dispatcher, so a mail outage never fails
+-- the RSVP that triggered them. Failed sends are retried with backoff.
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL CHECK (kind IN ('confirmation', 'digest')),
+    invite_code TEXT NOT NULL DEFAULT '',   -- Empty for digests
+    recipient TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at DATETIME NOT NULL DEFAULT (datetime('now', 'utc')),
+    sent_at DATETIME,                       -- NULL until delivered
+    created_at DATETIME NOT NULL DEFAULT (datetime('now', 'utc'))
+);
+
+-- OPTIMIZATION: Index for the dispatcher's due messages lookup
+CREATE INDEX IF NOT EXISTS idx_email_outbox_due
+ON email_outbox(next_attempt_at)
+WHERE sent_at IS NULL;
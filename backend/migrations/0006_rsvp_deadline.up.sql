@@ -0,0 +1,2 @@
+-- Per-invite RSVP deadline, read from the sheet. NULL uses the global RSVP_DEADLINE.
+ALTER TABLE invites ADD COLUMN rsvp_deadline DATETIME;
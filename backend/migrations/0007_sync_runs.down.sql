@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_sync_runs_success;
+DROP TABLE IF EXISTS sync_runs;
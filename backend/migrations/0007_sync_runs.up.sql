@@ -0,0 +1,18 @@
+-- Sync Runs table: one row per sync cycle with what moved in each direction,
+-- so a stale sheet shows up in /health and the admin API, not just the logs.
+CREATE TABLE IF NOT EXISTS sync_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME NOT NULL,
+    duration_ms INTEGER NOT NULL,
+    invites_read INTEGER NOT NULL DEFAULT 0,     -- Sheet to database
+    rsvps_pushed INTEGER NOT NULL DEFAULT 0,     -- Database to sheet
+    rsvps_failed INTEGER NOT NULL DEFAULT 0,
+    schedule_events INTEGER NOT NULL DEFAULT 0,  -- Sheet to database
+    error TEXT NOT NULL DEFAULT ''               -- Empty when the cycle succeeded
+);
+
+-- OPTIMIZATION: Index for the last successful run lookup done by /health
+CREATE INDEX IF NOT EXISTS idx_sync_runs_success
+ON sync_runs(id)
+WHERE error = '';
@@ -0,0 +1,3 @@
+-- Invites whose row was removed from the sheet are disabled instead of
+-- deleted (see SHEETS_REMOVED_INVITES), keeping their RSVP answers
+ALTER TABLE invites ADD COLUMN disabled_at DATETIME;  -- NULL while the invite is in the sheet
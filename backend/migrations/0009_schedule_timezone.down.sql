@@ -0,0 +1 @@
+ALTER TABLE schedule_events DROP COLUMN timezone;
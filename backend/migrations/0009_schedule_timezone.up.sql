@@ -0,0 +1,3 @@
+-- IANA timezone each event's times are in (see WEDDING_TIMEZONE). Empty for
+-- events synced before this column existed, read as the wedding's timezone.
+ALTER TABLE schedule_events ADD COLUMN timezone TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_invite_events_event_key;
+DROP TABLE IF EXISTS invite_events;
+DROP TABLE IF EXISTS events;
@@ -0,0 +1,33 @@
+-- Events table: celebrations besides the wedding itself, e.g. Friday drinks
+-- or the spring party in Catalonia. Synced from the sheet's Events tab.
+CREATE TABLE IF NOT EXISTS events (
+    event_key TEXT PRIMARY KEY,              -- Short ID used by the API, e.g. "drinks"
+    position INTEGER NOT NULL DEFAULT 0,     -- Order of the row in the Events tab
+    name_es TEXT NOT NULL,
+    name_en TEXT NOT NULL DEFAULT '',
+    name_ca TEXT NOT NULL DEFAULT '',
+    start_time TEXT NOT NULL DEFAULT '',     -- ISO8601 date or date-time, empty if not set
+    timezone TEXT NOT NULL DEFAULT '',       -- IANA timezone of start_time
+    location TEXT NOT NULL DEFAULT '',
+    sheet_tab TEXT NOT NULL,                 -- Tab listing who is invited and their answers
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now', 'utc'))
+);
+
+-- Invite Events table: who is invited to each event, with their own limits
+-- and answers. Like invites, an answer newer than updated_at is pending sync.
+CREATE TABLE IF NOT EXISTS invite_events (
+    invite_code TEXT NOT NULL REFERENCES invites(invite_code) ON DELETE CASCADE,
+    event_key TEXT NOT NULL REFERENCES events(event_key) ON DELETE CASCADE,
+    max_adults INTEGER NOT NULL DEFAULT 1,
+    max_kids INTEGER NOT NULL DEFAULT 0,
+    confirmed_adults INTEGER NOT NULL DEFAULT 0,
+    confirmed_kids INTEGER NOT NULL DEFAULT 0,
+    response_at DATETIME,                    -- Last answer, NULL if not responded
+    sheet_row INTEGER,                       -- Row in the event's tab at the last read
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now', 'utc')),
+    PRIMARY KEY (invite_code, event_key)
+);
+
+-- OPTIMIZATION: Index for syncing an event's tab
+CREATE INDEX IF NOT EXISTS idx_invite_events_event_key
+ON invite_events(event_key);
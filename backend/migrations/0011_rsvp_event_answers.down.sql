@@ -0,0 +1,2 @@
+DELETE FROM rsvp_events WHERE event_key != '';
+ALTER TABLE rsvp_events DROP COLUMN event_key;
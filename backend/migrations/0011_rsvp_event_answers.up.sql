@@ -0,0 +1,3 @@
+-- Answers for events besides the wedding are logged in rsvp_events too, one
+-- entry per event whose answer changed. Only their counts are filled in.
+ALTER TABLE rsvp_events ADD COLUMN event_key TEXT NOT NULL DEFAULT ''; -- Empty for the wedding
@@ -0,0 +1,4 @@
+-- Calendar UID of each schedule event, kept by the sync while the event is
+-- moved or renamed so calendar apps update it instead of adding a new one.
+-- Empty for events synced before this column existed, filled on the next sync.
+ALTER TABLE schedule_events ADD COLUMN uid TEXT NOT NULL DEFAULT '';
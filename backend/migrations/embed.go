@@ -0,0 +1,13 @@
+// Package migrations embeds the numbered SQL schema migrations.
+//
+// Files are named NNNN_description.up.sql with a matching .down.sql and are
+// applied in version order by store.Migrate. sqlc reads the same directory
+// (ignoring the .down.sql files) to generate the query code.
+package migrations
+
+import "embed"
+
+// FS holds every migration file in this directory
+//
+//go:embed *.sql
+var FS embed.FS